@@ -43,6 +43,8 @@ type ActivityLog struct {
 	ModelLink  string `gorm:"not null;"`
 	Detail     string `gorm:"not null;"`
 	Scope      string `gorm:"index;"`
+	// TenantID is the tenant of the request which creates the log
+	TenantID string `gorm:"index;"`
 }
 
 func (v *ActivityLog) AfterMigrate(tx *gorm.DB, tablePrefix string) error {
@@ -149,7 +149,7 @@ func (ab *Builder) defaultLogModelInstall(b *presets.Builder, mb *presets.ModelB
 	)
 	lb.Field("Action").Label(Messages_en_US.ModelAction).ComponentFunc(func(obj interface{}, field *presets.FieldContext, ctx *web.EventContext) h.HTMLComponent {
 		action := obj.(*ActivityLog).Action
-		label := getActionLabel(ctx, action)
+		label := ActionLabel(ctx, action)
 		return h.Td(h.Div().Attr("v-pre", true).Text(label))
 	})
 	lb.Field("ModelKeys").Label(Messages_en_US.ModelKeys)
@@ -302,7 +302,7 @@ func (ab *Builder) defaultLogModelInstall(b *presets.Builder, mb *presets.ModelB
 				msgr          = i18n.MustGetModuleMessages(ctx.R, I18nActivityKey, Messages_en_US).(*Messages)
 				pmsgr         = presets.MustGetMessages(ctx.R)
 				hideDetailTop = cast.ToBool(ctx.R.Form.Get(paramHideDetailTop))
-				actionLabel   = getActionLabel(ctx, log.Action)
+				actionLabel   = ActionLabel(ctx, log.Action)
 			)
 			var children []h.HTMLComponent
 			if !hideDetailTop {
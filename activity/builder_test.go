@@ -512,3 +512,32 @@ func TestContextWithDB(t *testing.T) {
 		}
 	}
 }
+
+func TestDuplicateLoggedAsCreate(t *testing.T) {
+	pb := presets.New().DataOperator(gorm2op.DataOperator(db))
+	builder := New(db, testCurrentUser)
+	builder.Install(pb)
+
+	mb := pb.Model(&TestActivityModel{}).URIName("duplicated-pages")
+	mb.Editing("Title", "Description")
+	mb.Duplicate()
+	builder.RegisterModel(mb).Keys("ID")
+	pb.Build()
+
+	resetDB()
+	require.NoError(t, db.Create(&TestActivityModel{ID: 1, VersionName: "v1", Title: "test1"}).Error)
+
+	w := httptest.NewRecorder()
+	pb.ServeHTTP(w, httptest.NewRequest("POST", "/duplicated-pages?__execute_event__=presets_Duplicate&id=1", nil))
+	require.Equal(t, 200, w.Code)
+
+	var copied TestActivityModel
+	require.NoError(t, db.Where("id <> ?", 1).First(&copied).Error)
+	require.Equal(t, "test1", copied.Title)
+
+	var logs []*ActivityLog
+	require.NoError(t, db.Where("model_name = ?", "TestActivityModel").Find(&logs).Error)
+	require.Len(t, logs, 1, "only the copy is logged")
+	require.Equal(t, "Create", logs[0].Action)
+	require.Equal(t, fmt.Sprint(copied.ID), logs[0].ModelKeys)
+}
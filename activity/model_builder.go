@@ -144,6 +144,16 @@ func (amb *ModelBuilder) installPresetModelBuilder(mb *presets.ModelBuilder) {
 		return amb.ab, mb, amb
 	})
 
+	mb.VersionConflictDiffFunc(func(latest, yours any) ([]*presets.VersionConflictDiff, error) {
+		diffs, err := NewDiffBuilder(amb).Diff(latest, yours)
+		if err != nil {
+			return nil, err
+		}
+		return lo.Map(diffs, func(d Diff, _ int) *presets.VersionConflictDiff {
+			return &presets.VersionConflictDiff{Field: d.Field, Latest: d.Old, Yours: d.New}
+		}), nil
+	})
+
 	eb := mb.Editing()
 	eb.WrapSaveFunc(func(in presets.SaveFunc) presets.SaveFunc {
 		return func(obj any, id string, ctx *web.EventContext) (err error) {
@@ -472,6 +482,8 @@ func (mb *ModelBuilder) create(
 	} else if mb.ab.tablePrefix != "" {
 		db = db.Scopes(ScopeWithTablePrefix(mb.ab.tablePrefix)).Session(&gorm.Session{})
 	}
+	// the callbacks like the tenant scoping read the context
+	db = db.WithContext(ctx)
 
 	user, err := mb.ab.currentUserFunc(ctx)
 	if err != nil {
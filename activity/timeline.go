@@ -125,7 +125,7 @@ func (c *TimelineCompo) humanContent(ctx context.Context, log *ActivityLog) h.HT
 	case ActionDelete:
 		return h.Div(h.Text(msgr.Deleted))
 	default:
-		return h.Div().Attr("v-pre", true).Text(msgr.PerformAction(getActionLabel(evCtx, log.Action), log.Detail))
+		return h.Div().Attr("v-pre", true).Text(msgr.PerformAction(ActionLabel(evCtx, log.Action), log.Detail))
 	}
 }
 
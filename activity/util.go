@@ -212,7 +212,8 @@ func ScopeWithTablePrefix(tablePrefix string) func(db *gorm.DB) *gorm.DB {
 
 const I18nActionLabelPrefix = "ActivityAction"
 
-func getActionLabel(evCtx *web.EventContext, action string) string {
+// ActionLabel returns the translated label of the action of the logs
+func ActionLabel(evCtx *web.EventContext, action string) string {
 	msgr := i18n.MustGetModuleMessages(evCtx.R, I18nActivityKey, Messages_en_US).(*Messages)
 	label := defaultActionLabels(msgr)[action]
 	if label == "" {
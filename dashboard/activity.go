@@ -0,0 +1,47 @@
+package dashboard
+
+import (
+	"fmt"
+
+	"github.com/qor5/admin/v3/activity"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/i18n"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+// RecentActivity shows the latest activity logs, it requires the log model of the activity to be installed to presets.
+func (b *Builder) RecentActivity(name string, ab *activity.Builder, limit int) (r *WidgetBuilder) {
+	logModel := func() *presets.ModelBuilder {
+		if b.pb == nil {
+			return nil
+		}
+		return ab.GetLogModelBuilder(b.pb)
+	}
+	r = b.Widget(name).Cols(6)
+	r.modelsFunc = func() []*presets.ModelBuilder {
+		return []*presets.ModelBuilder{logModel()}
+	}
+	r.defaultLabel = func(ctx *web.EventContext) string {
+		return i18n.MustGetModuleMessages(ctx.R, I18nDashboardKey, Messages_en_US).(*Messages).RecentActivity
+	}
+	return r.ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		pmsgr := presets.MustGetMessages(ctx.R)
+		return recentContent(logModel(), "CreatedAt", limit, func(obj any, ctx *web.EventContext) h.HTMLComponent {
+			log := obj.(*activity.ActivityLog)
+			title := fmt.Sprintf("%s %s", activity.ActionLabel(ctx, log.Action), log.ModelLabel)
+			if log.User.Name != "" {
+				title = fmt.Sprintf("%s: %s", log.User.Name, title)
+			}
+			item := VListItem(
+				VListItemTitle(h.Text(title)),
+				VListItemSubtitle(h.Text(pmsgr.HumanizeTime(log.CreatedAt))),
+			)
+			if log.ModelLink != "" {
+				item.Href(log.ModelLink)
+			}
+			return item
+		})(ctx)
+	})
+}
@@ -0,0 +1,268 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/i18n"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+const I18nDashboardKey i18n.ModuleKey = "I18nDashboardKey"
+
+const (
+	portalName = "dashboard_portal"
+
+	eventUpdateLayout = "dashboard_UpdateLayout"
+	eventResetLayout  = "dashboard_ResetLayout"
+
+	paramOp     = "op"
+	paramWidget = "widget"
+	paramCols   = "cols"
+)
+
+// LayoutKeysFunc returns the keys which the layout of the dashboard is saved by for the request, like the user and then
+// its roles, the layout of the first key having one is shown and the changes are saved to the first key,
+// so the layout of a role is the default of its users. The dashboard can't be customized if there are no keys.
+type LayoutKeysFunc func(r *http.Request) []string
+
+// Builder is the home page of presets which shows the widgets in a grid.
+type Builder struct {
+	db             *gorm.DB
+	pb             *presets.Builder
+	widgets        []*WidgetBuilder
+	layoutKeysFunc LayoutKeysFunc
+}
+
+var _ presets.Plugin = (*Builder)(nil)
+
+func New(db *gorm.DB) *Builder {
+	return &Builder{db: db}
+}
+
+func (b *Builder) AutoMigrate() (r *Builder) {
+	if err := b.db.AutoMigrate(&DashboardLayout{}); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (b *Builder) LayoutKeysFunc(v LayoutKeysFunc) (r *Builder) {
+	b.layoutKeysFunc = v
+	return b
+}
+
+// Widget returns the widget of the name, it's added to the dashboard if it doesn't exist.
+func (b *Builder) Widget(name string) (r *WidgetBuilder) {
+	if w := b.getWidget(name); w != nil {
+		return w
+	}
+	r = &WidgetBuilder{name: name, cols: colsDefault}
+	b.widgets = append(b.widgets, r)
+	return r
+}
+
+func (b *Builder) getWidget(name string) *WidgetBuilder {
+	for _, w := range b.widgets {
+		if w.name == name {
+			return w
+		}
+	}
+	return nil
+}
+
+// Install makes the dashboard the home page of presets.
+func (b *Builder) Install(pb *presets.Builder) error {
+	b.pb = pb
+	pb.GetI18n().
+		RegisterForModule(language.English, I18nDashboardKey, Messages_en_US).
+		RegisterForModule(language.SimplifiedChinese, I18nDashboardKey, Messages_zh_CN).
+		RegisterForModule(language.Japanese, I18nDashboardKey, Messages_ja_JP)
+	pb.HomePageFunc(b.pageFunc)
+	pb.GetWebBuilder().RegisterEventFunc(eventUpdateLayout, b.updateLayout)
+	pb.GetWebBuilder().RegisterEventFunc(eventResetLayout, b.resetLayoutEvent)
+	return nil
+}
+
+func (b *Builder) pageFunc(ctx *web.EventContext) (r web.PageResponse, err error) {
+	msgr := i18n.MustGetModuleMessages(ctx.R, I18nDashboardKey, Messages_en_US).(*Messages)
+	body, err := b.dashboard(ctx, false)
+	if err != nil {
+		return
+	}
+	r.PageTitle = msgr.Dashboard
+	r.Body = VContainer(web.Portal(body).Name(portalName)).Fluid(true)
+	return
+}
+
+func (b *Builder) dashboard(ctx *web.EventContext, editing bool) (h.HTMLComponent, error) {
+	msgr := i18n.MustGetModuleMessages(ctx.R, I18nDashboardKey, Messages_en_US).(*Messages)
+	items, err := b.layout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := VRow()
+	shown := map[string]bool{}
+	for i, item := range items {
+		shown[item.Widget] = true
+		row.AppendChildren(
+			VCol(b.widgetCard(ctx, b.getWidget(item.Widget), item, i == 0, i == len(items)-1)).
+				Cols(12).Md(item.Cols),
+		)
+	}
+	if len(items) == 0 {
+		row.AppendChildren(VCol(h.Div(h.Text(msgr.NoWidgets)).Class("text-body-2 text-grey")))
+	}
+
+	var controls h.HTMLComponent
+	if len(b.layoutKeys(ctx)) > 0 {
+		addMenu := VList().Density(DensityCompact)
+		var addable int
+		for _, w := range b.widgets {
+			if shown[w.name] || !w.allowed(ctx) {
+				continue
+			}
+			addMenu.AppendChildren(
+				VListItem(VListItemTitle(h.Text(w.getLabel(ctx)))).
+					Attr("@click", layoutOpEvent(layoutOpAdd, w.name, 0)),
+			)
+			addable++
+		}
+		controls = h.Div(
+			VBtn(msgr.Customize).Variant(VariantTonal).PrependIcon("mdi-view-dashboard-edit").
+				Attr("v-if", "!locals.editing").
+				Attr("@click", "locals.editing = true"),
+			h.Div(
+				VMenu(
+					web.Slot(
+						VBtn(msgr.AddWidget).Variant(VariantTonal).PrependIcon("mdi-plus").
+							Attr("v-bind", "props").Disabled(addable == 0),
+					).Name("activator").Scope("{ props }"),
+					addMenu,
+				),
+				VBtn(msgr.Reset).Variant(VariantText).
+					Attr("@click", web.Plaid().EventFunc(eventResetLayout).Go()),
+				VBtn(msgr.Done).Variant(VariantFlat).Color(ColorPrimary).
+					Attr("@click", "locals.editing = false"),
+			).Class("d-flex ga-2").Attr("v-if", "locals.editing"),
+		)
+	}
+
+	return web.Scope(
+		h.Div(
+			h.Div(h.Text(msgr.Dashboard)).Class("text-h5"),
+			VSpacer(),
+			controls,
+		).Class("d-flex align-center mb-4"),
+		row,
+	).VSlot("{ locals }").Init(fmt.Sprintf(`{ editing: %t }`, editing)), nil
+}
+
+func (b *Builder) widgetCard(ctx *web.EventContext, w *WidgetBuilder, item *LayoutItem, first bool, last bool) h.HTMLComponent {
+	msgr := i18n.MustGetModuleMessages(ctx.R, I18nDashboardKey, Messages_en_US).(*Messages)
+
+	var content h.HTMLComponent
+	if w.contentFunc != nil {
+		var err error
+		if content, err = w.contentFunc(ctx); err != nil {
+			content = h.Div(h.Text(err.Error())).Class("text-body-2 text-error")
+		}
+	}
+
+	widthMenu := VList().Density(DensityCompact)
+	for _, cols := range colsOptions {
+		widthMenu.AppendChildren(
+			VListItem(VListItemTitle(h.Text(fmt.Sprintf("%d / 12", cols)))).
+				Active(cols == item.Cols).
+				Attr("@click", layoutOpEvent(layoutOpResize, w.name, cols)),
+		)
+	}
+
+	return VCard(
+		VCardTitle(
+			h.Div(
+				h.Div(h.Text(w.getLabel(ctx))).Class("text-subtitle-1 text-truncate"),
+				VSpacer(),
+				h.Div(
+					VBtn("").Icon("mdi-chevron-left").Variant(VariantText).Size(SizeSmall).
+						Disabled(first).Attr("title", msgr.MoveLeft).
+						Attr("@click", layoutOpEvent(layoutOpMoveLeft, w.name, 0)),
+					VBtn("").Icon("mdi-chevron-right").Variant(VariantText).Size(SizeSmall).
+						Disabled(last).Attr("title", msgr.MoveRight).
+						Attr("@click", layoutOpEvent(layoutOpMoveRight, w.name, 0)),
+					VMenu(
+						web.Slot(
+							VBtn("").Icon("mdi-arrow-expand-horizontal").Variant(VariantText).Size(SizeSmall).
+								Attr("title", msgr.Width).Attr("v-bind", "props"),
+						).Name("activator").Scope("{ props }"),
+						widthMenu,
+					),
+					VBtn("").Icon("mdi-close").Variant(VariantText).Size(SizeSmall).
+						Attr("title", msgr.RemoveWidget).
+						Attr("@click", layoutOpEvent(layoutOpRemove, w.name, 0)),
+				).Class("d-flex").Attr("v-if", "locals.editing"),
+			).Class("d-flex align-center"),
+		),
+		VCardText(content),
+	).Variant(VariantOutlined).Height("100%")
+}
+
+func layoutOpEvent(op string, widget string, cols int) string {
+	return web.Plaid().
+		EventFunc(eventUpdateLayout).
+		Query(paramOp, op).
+		Query(paramWidget, widget).
+		Query(paramCols, cols).
+		Go()
+}
+
+func (b *Builder) updatePortal(ctx *web.EventContext, r *web.EventResponse) error {
+	body, err := b.dashboard(ctx, true)
+	if err != nil {
+		return err
+	}
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: portalName,
+		Body: body,
+	})
+	return nil
+}
+
+func (b *Builder) updateLayout(ctx *web.EventContext) (r web.EventResponse, err error) {
+	name := ctx.R.FormValue(paramWidget)
+	if w := b.getWidget(name); w != nil && !w.allowed(ctx) {
+		presets.ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return
+	}
+	items, err := b.layout(ctx)
+	if err != nil {
+		return
+	}
+	cols, _ := strconv.Atoi(ctx.R.FormValue(paramCols))
+	items, err = b.applyLayoutOp(items, ctx.R.FormValue(paramOp), name, cols)
+	if err != nil {
+		presets.ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+	if err = b.saveLayout(ctx, items); err != nil {
+		return
+	}
+	err = b.updatePortal(ctx, &r)
+	return
+}
+
+func (b *Builder) resetLayoutEvent(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if err = b.resetLayout(ctx); err != nil {
+		return
+	}
+	err = b.updatePortal(ctx, &r)
+	return
+}
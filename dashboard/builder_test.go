@@ -0,0 +1,175 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/admin/v3/presets/gorm2op"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type Order struct {
+	gorm.Model
+	Amount float64
+}
+
+func setup(t *testing.T) (*Builder, *presets.ModelBuilder, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Order{}))
+	require.NoError(t, db.Create([]*Order{{Amount: 1.5}, {Amount: 2}, {Amount: 3}}).Error)
+
+	pb := presets.New().DataOperator(gorm2op.DataOperator(db)).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("guest").WhoAre(perm.Denied).ToDo(presets.PermList).On("*:presets:orders:*"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&Order{})
+
+	b := New(db).AutoMigrate().LayoutKeysFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("user"), r.Header.Get("role")}
+	})
+	b.Count("Orders", mb)
+	b.Sum("Revenue", mb, "Amount")
+	b.TimeSeries("NewOrders", mb, "CreatedAt", 7)
+	b.Widget("Welcome").ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		return h.Text("Welcome"), nil
+	})
+	pb.Use(b)
+	return b, mb, db
+}
+
+func eventContext(user string, role string) *web.EventContext {
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("user", user)
+	r.Header.Set("role", role)
+	return &web.EventContext{R: r, W: httptest.NewRecorder()}
+}
+
+func widgetNames(items []*LayoutItem) (names []string) {
+	for _, item := range items {
+		names = append(names, item.Widget)
+	}
+	return
+}
+
+func TestWidgets(t *testing.T) {
+	b, mb, db := setup(t)
+	ctx := eventContext("alice", "admin")
+
+	content, err := b.getWidget("Orders").contentFunc(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, h.MustString(content, context.Background()), ">3<")
+
+	content, err = b.getWidget("Revenue").contentFunc(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, h.MustString(content, context.Background()), "6.5")
+
+	content, err = b.getWidget("NewOrders").contentFunc(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, h.MustString(content, context.Background()), time.Now().Format("01-02"))
+
+	// the sum is computed by the data operator with the conditions
+	content, err = b.Sum("BigRevenue", mb, "Amount", &presets.SQLCondition{Query: "amount > ?", Args: []any{1.5}}).contentFunc(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, h.MustString(content, context.Background()), ">5<")
+
+	// the records are summed one by one if the model has a custom searcher
+	mb.Listing().WrapSearchFunc(func(in presets.SearchFunc) presets.SearchFunc {
+		return func(ctx *web.EventContext, params *presets.SearchParams) (*presets.SearchResult, error) {
+			params.SQLConditions = append(params.SQLConditions, &presets.SQLCondition{Query: "amount < ?", Args: []any{3}})
+			return in(ctx, params)
+		}
+	})
+	content, err = b.getWidget("Revenue").contentFunc(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, h.MustString(content, context.Background()), ">3.5<")
+	mb.Listing().SearchFunc(mb.GetPresetsBuilder().GetDataOperator().Search)
+
+	// the records are counted by the calendar days
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	require.NoError(t, db.Create([]*Order{
+		{Model: gorm.Model{CreatedAt: today.Add(-time.Minute)}},
+		{Model: gorm.Model{CreatedAt: today.AddDate(0, 0, -2)}},
+		{Model: gorm.Model{CreatedAt: today.AddDate(0, 0, -7)}},
+	}).Error)
+	content, err = b.getWidget("NewOrders").contentFunc(ctx)
+	require.NoError(t, err)
+	body := h.MustString(content, context.Background())
+	assert.Contains(t, body, "[0,0,0,0,1,1,3]")
+	assert.Contains(t, body, ">5<")
+}
+
+func TestPermissions(t *testing.T) {
+	b, _, _ := setup(t)
+
+	items, err := b.layout(eventContext("alice", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders", "Revenue", "NewOrders", "Welcome"}, widgetNames(items))
+
+	items, err = b.layout(eventContext("bob", "guest"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Welcome"}, widgetNames(items), "the widgets of the models which can't be listed are hidden")
+
+	ctx := eventContext("bob", "guest")
+	ctx.R.Form = map[string][]string{paramOp: {layoutOpAdd}, paramWidget: {"Orders"}}
+	_, err = b.updateLayout(ctx)
+	require.NoError(t, err)
+	items, err = b.layout(eventContext("bob", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders", "Revenue", "NewOrders", "Welcome"}, widgetNames(items), "the layout is not saved")
+}
+
+func TestLayout(t *testing.T) {
+	b, _, _ := setup(t)
+
+	update := func(ctx *web.EventContext, op string, widget string, cols string) {
+		ctx.R.Form = map[string][]string{paramOp: {op}, paramWidget: {widget}, paramCols: {cols}}
+		r, err := b.updateLayout(ctx)
+		require.NoError(t, err)
+		require.Len(t, r.UpdatePortals, 1)
+	}
+
+	// the layout of the role is the default of its users
+	update(eventContext("", "admin"), layoutOpRemove, "Welcome", "")
+	items, err := b.layout(eventContext("alice", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders", "Revenue", "NewOrders"}, widgetNames(items))
+
+	alice := eventContext("alice", "admin")
+	update(alice, layoutOpMoveRight, "Orders", "")
+	update(alice, layoutOpResize, "NewOrders", "12")
+	update(alice, layoutOpAdd, "Welcome", "")
+	items, err = b.layout(eventContext("alice", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Revenue", "Orders", "NewOrders", "Welcome"}, widgetNames(items))
+	assert.Equal(t, 12, items[2].Cols)
+	assert.Equal(t, colsDefault, items[3].Cols)
+
+	items, err = b.layout(eventContext("bob", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders", "Revenue", "NewOrders"}, widgetNames(items), "the layouts belong to the users")
+
+	_, err = b.applyLayoutOp(items, layoutOpResize, "Orders", 5)
+	assert.Error(t, err)
+	_, err = b.applyLayoutOp(items, layoutOpRemove, "Welcome", 0)
+	assert.Error(t, err)
+
+	_, err = b.resetLayoutEvent(eventContext("alice", "admin"))
+	require.NoError(t, err)
+	items, err = b.layout(eventContext("alice", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders", "Revenue", "NewOrders"}, widgetNames(items))
+}
@@ -0,0 +1,59 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/admin/v3/worker"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/i18n"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+// JobStatus shows the number of the jobs of the worker in each status, it links to the jobs in the status.
+func (b *Builder) JobStatus(name string, wb *worker.Builder) (r *WidgetBuilder) {
+	r = b.Widget(name).Cols(6)
+	r.modelsFunc = func() []*presets.ModelBuilder {
+		return []*presets.ModelBuilder{wb.GetModelBuilder()}
+	}
+	r.defaultLabel = func(ctx *web.EventContext) string {
+		return i18n.MustGetModuleMessages(ctx.R, I18nDashboardKey, Messages_en_US).(*Messages).JobStatus
+	}
+	return r.ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		mb := wb.GetModelBuilder()
+		wmsgr := i18n.MustGetModuleMessages(ctx.R, worker.I18nWorkerKey, worker.Messages_en_US).(*worker.Messages)
+		statuses := []struct {
+			status string
+			label  string
+			color  string
+		}{
+			{worker.JobStatusNew, wmsgr.StatusNew, ColorInfo},
+			{worker.JobStatusScheduled, wmsgr.StatusScheduled, ColorInfo},
+			{worker.JobStatusRunning, wmsgr.StatusRunning, ColorPrimary},
+			{worker.JobStatusDone, wmsgr.StatusDone, ColorSuccess},
+			{worker.JobStatusException, wmsgr.StatusException, ColorError},
+			{worker.JobStatusKilled, wmsgr.StatusKilled, ColorError},
+			{worker.JobStatusCancelled, wmsgr.StatusCancelled, ColorGrey},
+		}
+		chips := h.Div().Class("d-flex flex-wrap ga-2")
+		for _, s := range statuses {
+			n, err := countRecords(ctx, mb, []*presets.SQLCondition{{
+				Query: "status = ?",
+				Args:  []any{s.status},
+			}})
+			if err != nil {
+				return nil, err
+			}
+			chips.AppendChildren(
+				VChip(
+					h.Text(s.label),
+					h.Strong(fmt.Sprint(n)).Class("ml-2"),
+				).Color(s.color).Variant(VariantTonal).
+					Href(mb.Info().ListingHref() + "?" + url.Values{"status": {s.status}}.Encode()),
+			)
+		}
+		return chips, nil
+	})
+}
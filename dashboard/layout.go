@@ -0,0 +1,142 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LayoutItem is a widget shown on the dashboard, Cols is its width in the 12 columns grid.
+type LayoutItem struct {
+	Widget string `json:"widget"`
+	Cols   int    `json:"cols"`
+}
+
+// DashboardLayout is the layout saved for a key, which is usually a user or a role.
+type DashboardLayout struct {
+	Owner     string `gorm:"primaryKey;size:191"`
+	Items     string
+	UpdatedAt time.Time
+}
+
+const (
+	layoutOpAdd       = "add"
+	layoutOpRemove    = "remove"
+	layoutOpMoveLeft  = "moveLeft"
+	layoutOpMoveRight = "moveRight"
+	layoutOpResize    = "resize"
+)
+
+var colsOptions = []int{3, 4, 6, 8, 12}
+
+// defaultLayout shows all the widgets in the order they are registered
+func (b *Builder) defaultLayout() []*LayoutItem {
+	items := make([]*LayoutItem, 0, len(b.widgets))
+	for _, w := range b.widgets {
+		items = append(items, &LayoutItem{Widget: w.name, Cols: w.cols})
+	}
+	return items
+}
+
+// layout returns the layout saved for the first key having one, and the default layout if there is none,
+// the widgets which are not registered anymore or not allowed for the request are dropped.
+func (b *Builder) layout(ctx *web.EventContext) ([]*LayoutItem, error) {
+	items := b.defaultLayout()
+	for _, key := range b.layoutKeys(ctx) {
+		var l DashboardLayout
+		err := b.db.WithContext(ctx.R.Context()).Where("owner = ?", key).First(&l).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var saved []*LayoutItem
+		if err = json.Unmarshal([]byte(l.Items), &saved); err != nil {
+			return nil, err
+		}
+		items = saved
+		break
+	}
+	return slices.DeleteFunc(items, func(item *LayoutItem) bool {
+		w := b.getWidget(item.Widget)
+		return w == nil || !w.allowed(ctx)
+	}), nil
+}
+
+func (b *Builder) saveLayout(ctx *web.EventContext, items []*LayoutItem) error {
+	keys := b.layoutKeys(ctx)
+	if len(keys) == 0 {
+		return errors.New("dashboard: the layout can not be saved without a key")
+	}
+	bs, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return b.db.WithContext(ctx.R.Context()).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner"}},
+		DoUpdates: clause.AssignmentColumns([]string{"items", "updated_at"}),
+	}).Create(&DashboardLayout{Owner: keys[0], Items: string(bs)}).Error
+}
+
+// resetLayout removes the layout of the first key, so the layout falls back to the next key's or the default one
+func (b *Builder) resetLayout(ctx *web.EventContext) error {
+	keys := b.layoutKeys(ctx)
+	if len(keys) == 0 {
+		return nil
+	}
+	return b.db.WithContext(ctx.R.Context()).Where("owner = ?", keys[0]).Delete(&DashboardLayout{}).Error
+}
+
+func (b *Builder) layoutKeys(ctx *web.EventContext) []string {
+	if b.layoutKeysFunc == nil {
+		return nil
+	}
+	return slices.DeleteFunc(slices.Clone(b.layoutKeysFunc(ctx.R)), func(key string) bool {
+		return key == ""
+	})
+}
+
+// applyLayoutOp changes the layout by the operation on the widget
+func (b *Builder) applyLayoutOp(items []*LayoutItem, op string, widget string, cols int) ([]*LayoutItem, error) {
+	w := b.getWidget(widget)
+	if w == nil {
+		return nil, errors.Errorf("dashboard: widget %q not found", widget)
+	}
+	i := slices.IndexFunc(items, func(item *LayoutItem) bool {
+		return item.Widget == widget
+	})
+	if op != layoutOpAdd && i < 0 {
+		return nil, errors.Errorf("dashboard: widget %q is not on the dashboard", widget)
+	}
+
+	switch op {
+	case layoutOpAdd:
+		if i < 0 {
+			items = append(items, &LayoutItem{Widget: widget, Cols: w.cols})
+		}
+	case layoutOpRemove:
+		items = slices.Delete(items, i, i+1)
+	case layoutOpMoveLeft:
+		if i > 0 {
+			items[i-1], items[i] = items[i], items[i-1]
+		}
+	case layoutOpMoveRight:
+		if i < len(items)-1 {
+			items[i+1], items[i] = items[i], items[i+1]
+		}
+	case layoutOpResize:
+		if !slices.Contains(colsOptions, cols) {
+			return nil, errors.Errorf("dashboard: invalid width %d", cols)
+		}
+		items[i].Cols = cols
+	default:
+		return nil, errors.Errorf("dashboard: unknown layout operation %q", op)
+	}
+	return items, nil
+}
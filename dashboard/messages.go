@@ -0,0 +1,65 @@
+package dashboard
+
+type Messages struct {
+	Dashboard      string
+	Customize      string
+	Done           string
+	Reset          string
+	AddWidget      string
+	RemoveWidget   string
+	MoveLeft       string
+	MoveRight      string
+	Width          string
+	NoWidgets      string
+	NoData         string
+	RecentActivity string
+	JobStatus      string
+}
+
+var Messages_en_US = &Messages{
+	Dashboard:      "Dashboard",
+	Customize:      "Customize",
+	Done:           "Done",
+	Reset:          "Reset",
+	AddWidget:      "Add Widget",
+	RemoveWidget:   "Remove",
+	MoveLeft:       "Move Left",
+	MoveRight:      "Move Right",
+	Width:          "Width",
+	NoWidgets:      "No widgets",
+	NoData:         "No data",
+	RecentActivity: "Recent Activity",
+	JobStatus:      "Job Status",
+}
+
+var Messages_zh_CN = &Messages{
+	Dashboard:      "仪表盘",
+	Customize:      "自定义",
+	Done:           "完成",
+	Reset:          "重置",
+	AddWidget:      "添加组件",
+	RemoveWidget:   "移除",
+	MoveLeft:       "左移",
+	MoveRight:      "右移",
+	Width:          "宽度",
+	NoWidgets:      "没有组件",
+	NoData:         "没有数据",
+	RecentActivity: "最近活动",
+	JobStatus:      "任务状态",
+}
+
+var Messages_ja_JP = &Messages{
+	Dashboard:      "ダッシュボード",
+	Customize:      "カスタマイズ",
+	Done:           "完了",
+	Reset:          "リセット",
+	AddWidget:      "ウィジェットを追加",
+	RemoveWidget:   "削除",
+	MoveLeft:       "左へ移動",
+	MoveRight:      "右へ移動",
+	Width:          "幅",
+	NoWidgets:      "ウィジェットがありません",
+	NoData:         "データがありません",
+	RecentActivity: "最近のアクティビティ",
+	JobStatus:      "ジョブの状態",
+}
@@ -0,0 +1,283 @@
+package dashboard
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/i18n"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+	"github.com/theplant/relay"
+)
+
+type WidgetContentFunc func(ctx *web.EventContext) (h.HTMLComponent, error)
+
+const colsDefault = 4
+
+type WidgetBuilder struct {
+	name         string
+	label        string
+	defaultLabel func(ctx *web.EventContext) string
+	cols         int
+	contentFunc  WidgetContentFunc
+	models       []*presets.ModelBuilder
+	// modelsFunc returns the models which are only known after presets is installed
+	modelsFunc func() []*presets.ModelBuilder
+}
+
+// Label is the title of the widget, it's translated with the presets models i18n module by the prefix Dashboard.
+func (w *WidgetBuilder) Label(v string) (r *WidgetBuilder) {
+	w.label = v
+	return w
+}
+
+// Cols is the default width of the widget in the 12 columns grid.
+func (w *WidgetBuilder) Cols(v int) (r *WidgetBuilder) {
+	w.cols = v
+	return w
+}
+
+func (w *WidgetBuilder) ContentFunc(v WidgetContentFunc) (r *WidgetBuilder) {
+	w.contentFunc = v
+	return w
+}
+
+// Models are the models which the data of the widget comes from,
+// the widget is only shown to the users who can list all of them.
+func (w *WidgetBuilder) Models(vs ...*presets.ModelBuilder) (r *WidgetBuilder) {
+	w.models = vs
+	return w
+}
+
+func (w *WidgetBuilder) getLabel(ctx *web.EventContext) string {
+	if w.label != "" {
+		return i18n.PT(ctx.R, presets.ModelsI18nModuleKey, "Dashboard", w.label)
+	}
+	if w.defaultLabel != nil {
+		return w.defaultLabel(ctx)
+	}
+	return i18n.PT(ctx.R, presets.ModelsI18nModuleKey, "Dashboard", w.name)
+}
+
+func (w *WidgetBuilder) allowed(ctx *web.EventContext) bool {
+	models := w.models
+	if w.modelsFunc != nil {
+		models = append(slices.Clone(models), w.modelsFunc()...)
+	}
+	for _, mb := range models {
+		if mb == nil || mb.Info().Verifier().Do(presets.PermList).WithReq(ctx.R).IsAllowed() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// search runs the searcher of the model's listing, so the records are scoped in the same way as they are listed
+func search(ctx *web.EventContext, mb *presets.ModelBuilder, params *presets.SearchParams) (*presets.SearchResult, error) {
+	lb := mb.Listing()
+	if lb.Searcher == nil {
+		return nil, errors.New("function Searcher is not set")
+	}
+	params.Model = mb.NewModel()
+	params.PageURL = ctx.R.URL
+	return lb.Searcher(ctx, params)
+}
+
+// eachRecord walks through all the records of the model matching the conditions
+func eachRecord(ctx *web.EventContext, mb *presets.ModelBuilder, conds []*presets.SQLCondition, f func(obj any) error) error {
+	for page := int64(1); ; page++ {
+		result, err := search(ctx, mb, &presets.SearchParams{
+			SQLConditions: conds,
+			Page:          page,
+			PerPage:       presets.PerPageMax,
+		})
+		if err != nil {
+			return err
+		}
+		nodes := reflect.ValueOf(result.Nodes)
+		if nodes.Kind() != reflect.Slice {
+			return errors.New("search result nodes must be a slice")
+		}
+		for i := 0; i < nodes.Len(); i++ {
+			if err := f(nodes.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		if nodes.Len() < presets.PerPageMax {
+			return nil
+		}
+	}
+}
+
+func countRecords(ctx *web.EventContext, mb *presets.ModelBuilder, conds []*presets.SQLCondition) (int, error) {
+	result, err := search(ctx, mb, &presets.SearchParams{
+		SQLConditions: conds,
+		Page:          1,
+		PerPage:       1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.PageInfo.TotalCount, nil
+}
+
+func toFloat(v any) (float64, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return 0, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return strconv.ParseFloat(fmt.Sprint(rv.Interface()), 64)
+}
+
+func statistic(value string) h.HTMLComponent {
+	return h.Div(h.Text(value)).Class("text-h4 font-weight-medium")
+}
+
+func modelLabel(mb *presets.ModelBuilder) func(ctx *web.EventContext) string {
+	return func(ctx *web.EventContext) string {
+		return mb.Info().LabelName(ctx, false)
+	}
+}
+
+// Count shows the number of the records of the model matching the conditions
+func (b *Builder) Count(name string, mb *presets.ModelBuilder, conds ...*presets.SQLCondition) (r *WidgetBuilder) {
+	r = b.Widget(name).Cols(3).Models(mb)
+	r.defaultLabel = modelLabel(mb)
+	return r.ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		n, err := countRecords(ctx, mb, conds)
+		if err != nil {
+			return nil, err
+		}
+		return statistic(humanize.Comma(int64(n))), nil
+	})
+}
+
+// defaultSearcher reports whether the listing of the model still searches with the data operator,
+// a custom searcher may narrow the records down, like to the ones of the current user.
+func defaultSearcher(mb *presets.ModelBuilder) bool {
+	op := mb.GetPresetsBuilder().GetDataOperator()
+	searcher := mb.Listing().Searcher
+	return op != nil && searcher != nil &&
+		reflect.ValueOf(searcher).Pointer() == reflect.ValueOf(op.Search).Pointer()
+}
+
+// Sum shows the sum of the field of the records of the model matching the conditions,
+// it's computed in the data store if the data operator implements presets.Aggregator
+// and the listing of the model searches with the data operator.
+func (b *Builder) Sum(name string, mb *presets.ModelBuilder, field string, conds ...*presets.SQLCondition) (r *WidgetBuilder) {
+	r = b.Widget(name).Cols(3).Models(mb)
+	r.defaultLabel = modelLabel(mb)
+	return r.ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		if aggregator, ok := mb.GetPresetsBuilder().GetDataOperator().(presets.Aggregator); ok && defaultSearcher(mb) {
+			sum, err := aggregator.Sum(ctx, &presets.SearchParams{Model: mb.NewModel(), PageURL: ctx.R.URL, SQLConditions: conds}, field)
+			if err != nil {
+				return nil, err
+			}
+			return statistic(humanize.CommafWithDigits(sum, 2)), nil
+		}
+
+		var sum float64
+		err := eachRecord(ctx, mb, conds, func(obj any) error {
+			v, err := reflectutils.Get(obj, field)
+			if err != nil {
+				return err
+			}
+			f, err := toFloat(v)
+			if err != nil {
+				return errors.Wrapf(err, "sum of %s", field)
+			}
+			sum += f
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return statistic(humanize.CommafWithDigits(sum, 2)), nil
+	})
+}
+
+// TimeSeries shows the number of the records of the model created in each of the last days by the time field,
+// the column of the field is the snake case of it. The records of each calendar day are counted by the searcher,
+// the days start at the midnights of the local time zone.
+func (b *Builder) TimeSeries(name string, mb *presets.ModelBuilder, field string, days int, conds ...*presets.SQLCondition) (r *WidgetBuilder) {
+	r = b.Widget(name).Cols(6).Models(mb)
+	r.defaultLabel = modelLabel(mb)
+	column := strcase.ToSnake(field)
+	return r.ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		now := time.Now()
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1-days)
+		counts := make([]int, days)
+		labels := make([]string, days)
+		var total int
+		for i := range counts {
+			day := start.AddDate(0, 0, i)
+			labels[i] = day.Format("01-02")
+			n, err := countRecords(ctx, mb, append([]*presets.SQLCondition{{
+				Query: fmt.Sprintf("%s >= ? AND %s < ?", column, column),
+				Args:  []any{day, day.AddDate(0, 0, 1)},
+			}}, conds...))
+			if err != nil {
+				return nil, err
+			}
+			counts[i] = n
+			total += n
+		}
+		return h.Div(
+			statistic(humanize.Comma(int64(total))),
+			VSparkline().Type("bar").ModelValue(counts).Labels(labels).ShowLabels(true).
+				AutoLineWidth(true).Color(ColorPrimary).Height(80).Padding(4),
+		), nil
+	})
+}
+
+// Recent shows the latest records of the model ordered by the field descending
+func (b *Builder) Recent(name string, mb *presets.ModelBuilder, field string, limit int, itemFunc presets.ObjectComponentFunc, conds ...*presets.SQLCondition) (r *WidgetBuilder) {
+	r = b.Widget(name).Cols(6).Models(mb)
+	r.defaultLabel = modelLabel(mb)
+	return r.ContentFunc(recentContent(mb, field, limit, itemFunc, conds...))
+}
+
+func recentContent(mb *presets.ModelBuilder, field string, limit int, itemFunc presets.ObjectComponentFunc, conds ...*presets.SQLCondition) WidgetContentFunc {
+	return func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		result, err := search(ctx, mb, &presets.SearchParams{
+			SQLConditions: conds,
+			Page:          1,
+			PerPage:       int64(limit),
+			OrderBys:      []relay.OrderBy{{Field: field, Desc: true}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		list := VList().Density(DensityCompact)
+		var n int
+		reflectutils.ForEach(result.Nodes, func(obj interface{}) {
+			list.AppendChildren(itemFunc(obj, ctx))
+			n++
+		})
+		if n == 0 {
+			return noData(ctx), nil
+		}
+		return list, nil
+	}
+}
+
+func noData(ctx *web.EventContext) h.HTMLComponent {
+	msgr := i18n.MustGetModuleMessages(ctx.R, I18nDashboardKey, Messages_en_US).(*Messages)
+	return h.Div(h.Text(msgr.NoData)).Class("text-body-2 text-grey")
+}
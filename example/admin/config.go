@@ -22,6 +22,7 @@ import (
 	"github.com/qor5/admin/v3/autosync"
 	"github.com/qor5/admin/v3/example/models"
 	"github.com/qor5/admin/v3/l10n"
+	"github.com/qor5/admin/v3/listingview"
 	plogin "github.com/qor5/admin/v3/login"
 	"github.com/qor5/admin/v3/media"
 	"github.com/qor5/admin/v3/media/base"
@@ -96,7 +97,10 @@ func NewConfig(db *gorm.DB) Config {
 
 	// @snippet_begin(ActivityExample)
 	ab := activity.New(db, func(ctx context.Context) (*activity.User, error) {
-		u := ctx.Value(login.UserKey).(*models.User)
+		u, ok := ctx.Value(login.UserKey).(*models.User)
+		if !ok {
+			return nil, errors.New("current user is required")
+		}
 		return &activity.User{
 			ID:     fmt.Sprint(u.ID),
 			Name:   u.Name,
@@ -145,7 +149,16 @@ func NewConfig(db *gorm.DB) Config {
 		Session:  sess,
 		Endpoint: publishURL,
 	}))
-	b := presets.New().DataOperator(gorm2op.DataOperator(db)).RightDrawerWidth("700")
+	// the example runs on a single instance, which keeps the undo funcs in memory
+	b := presets.New().DataOperator(gorm2op.DataOperator(db)).RightDrawerWidth("700").
+		UndoWindow(presets.UndoWindowDefault).
+		UndoUserFunc(func(r *http.Request) string {
+			u := getCurrentUser(r)
+			if u == nil {
+				return ""
+			}
+			return fmt.Sprint(u.ID)
+		})
 	defer b.Build()
 
 	js, _ := assets.ReadFile("assets/fontcolor.min.js")
@@ -234,6 +247,7 @@ func NewConfig(db *gorm.DB) Config {
 			{Text: "Workers", Value: "*:workers:*"},
 		}).
 		AfterInstall(func(pb *presets.Builder, mb *presets.ModelBuilder) error {
+			pb.RESTAPI().Except(mb)
 			mb.Listing().SearchFunc(func(ctx *web.EventContext, params *presets.SearchParams) (result *presets.SearchResult, err error) {
 				u := getCurrentUser(ctx.R)
 				qdb := db
@@ -250,7 +264,7 @@ func NewConfig(db *gorm.DB) Config {
 	w := worker.New(db)
 	defer w.Listen()
 	addJobs(w)
-	configProduct(b, db, w, publisher)
+	productModel := configProduct(b, db, w, publisher)
 	configCategory(b, db, publisher)
 
 	// Use m to customize the model, Or config more models here.
@@ -336,10 +350,23 @@ func NewConfig(db *gorm.DB) Config {
 
 	configInputDemo(b, db)
 
-	configOrder(b, db)
+	listingViewBuilder := listingview.New(db, func(ctx context.Context) (*listingview.User, error) {
+		u, ok := ctx.Value(login.UserKey).(*models.User)
+		if !ok {
+			return nil, nil
+		}
+		return &listingview.User{
+			ID:    fmt.Sprint(u.ID),
+			Roles: u.GetRoles(),
+		}, nil
+	}).AutoMigrate()
+
+	orderModel := configOrder(b, db, listingViewBuilder)
 	configECDashboard(b, db)
 
-	configUser(b, ab, db, publisher, loginSessionBuilder)
+	userModel := configUser(b, ab, db, publisher, loginSessionBuilder)
+	// the users and the roles are only managed in the admin
+	b.RESTAPI().Title("Example Admin API").Except(userModel)
 
 	b.Use(
 		mediab,
@@ -350,6 +377,7 @@ func NewConfig(db *gorm.DB) Config {
 		roleBuilder,
 		loginSessionBuilder,
 		profileBuilder,
+		configDashboard(db, ab, w, productModel, orderModel),
 	)
 
 	if resetAndImportInitialData {
@@ -550,10 +578,6 @@ func configBrand(b *presets.Builder) {
 				h.Script("function updateCountdown(){const now=new Date();const nextEvenHour=new Date(now);nextEvenHour.setHours(nextEvenHour.getHours()+(nextEvenHour.getHours()%2===0?2:1),0,0,0);const timeLeft=nextEvenHour-now;const hours=Math.floor(timeLeft/(60*60*1000));const minutes=Math.floor((timeLeft%(60*60*1000))/(60*1000));const seconds=Math.floor((timeLeft%(60*1000))/1000);const countdownElem=document.getElementById(\"countdown\");countdownElem.innerText=`${hours.toString().padStart(2,\"0\")}:${minutes.toString().padStart(2,\"0\")}:${seconds.toString().padStart(2,\"0\")}`}updateCountdown();setInterval(updateCountdown,1000);"),
 			),
 		).Class("mb-n4 mt-n2")
-	}).HomePageFunc(func(ctx *web.EventContext) (r web.PageResponse, err error) {
-		r.PageTitle = "Home"
-		r.Body = Dashboard()
-		return
 	}).NotFoundPageLayoutConfig(&presets.LayoutConfig{
 		NotificationCenterInvisible: true,
 	})
@@ -1,6 +1,10 @@
 package admin
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+
 	"github.com/qor5/admin/v3/example/models"
 	"github.com/qor5/admin/v3/media"
 	"github.com/qor5/admin/v3/media/base"
@@ -55,4 +59,30 @@ func configNestedFieldDemo(b *presets.Builder, db *gorm.DB) {
 		}
 		return gorm2op.DataOperator(db.Session(&gorm.Session{FullSaveAssociations: true})).Save(obj, id, ctx)
 	})
+
+	searchIndex := gorm2op.PostgresSearchIndex(db, "english")
+	if err := searchIndex.AutoMigrate(); err != nil {
+		panic(err)
+	}
+	cust.Listing().SearchIndex(searchIndex, "Name")
+	// the customers created before the index is added are indexed at startup
+	reindexReq, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	if err != nil {
+		panic(err)
+	}
+	if err := cust.Listing().Reindex(&web.EventContext{R: reindexReq}); err != nil {
+		panic(err)
+	}
+
+	drafts := gorm2op.DraftStore(db)
+	if err := drafts.AutoMigrate(); err != nil {
+		panic(err)
+	}
+	ed.Drafts(drafts, func(r *http.Request) string {
+		u := getCurrentUser(r)
+		if u == nil {
+			return ""
+		}
+		return fmt.Sprint(u.ID)
+	})
 }
@@ -1,16 +1,52 @@
 package admin
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/qor5/admin/v3/activity"
+	"github.com/qor5/admin/v3/dashboard"
+	"github.com/qor5/admin/v3/example/models"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/admin/v3/worker"
+	"github.com/qor5/web/v3"
 	"github.com/qor5/x/v3/ui/vuetify"
 	h "github.com/theplant/htmlgo"
+	"gorm.io/gorm"
 )
 
 func Dashboard() h.HTMLComponent {
-	return vuetify.VContainer(
-		h.H1("Welcome to the QOR5 demo site").Class("mt-8"),
-
+	return h.Div(
 		h.A().Text("QOR5 Website").Href("https://qor5.com").Target("_blank"),
 		h.A().Text("QOR5 Documentation").Href("https://docs.qor5.com").Target("_blank").Class("ml-4"),
 		h.A().Text("Source Code").Href("https://github.com/qor5/admin/tree/main/example").Target("_blank").Class("ml-4"),
 	)
 }
+
+func configDashboard(db *gorm.DB, ab *activity.Builder, wb *worker.Builder, products *presets.ModelBuilder, orders *presets.ModelBuilder) *dashboard.Builder {
+	b := dashboard.New(db).AutoMigrate().LayoutKeysFunc(func(r *http.Request) []string {
+		u := getCurrentUser(r)
+		if u == nil {
+			return nil
+		}
+		// the layout of the user, or the layout of its roles
+		return append([]string{fmt.Sprintf("user:%d", u.ID)}, u.GetRoles()...)
+	})
+	b.Widget("Welcome to the QOR5 demo site").Cols(12).ContentFunc(func(ctx *web.EventContext) (h.HTMLComponent, error) {
+		return Dashboard(), nil
+	})
+	b.Count("Products", products)
+	b.Count("Orders", orders)
+	b.TimeSeries("New Orders", orders, "CreatedAt", 14)
+	b.Recent("Latest Orders", orders, "CreatedAt", 5, func(obj interface{}, ctx *web.EventContext) h.HTMLComponent {
+		order := obj.(*models.Order)
+		return vuetify.VListItem(
+			vuetify.VListItemTitle(h.Text(fmt.Sprintf("#%d %s", order.ID, order.Source))),
+			vuetify.VListItemSubtitle(h.Text(presets.MustGetMessages(ctx.R).HumanizeTime(order.CreatedAt))),
+			web.Slot(GetColoredStatus(order.Status)).Name("append"),
+		).Href(orders.Info().DetailingHref(fmt.Sprint(order.ID)))
+	})
+	b.RecentActivity("Recent Activity", ab, 5)
+	b.JobStatus("Jobs", wb)
+	return b
+}
@@ -1,9 +1,11 @@
 package admin
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/qor5/admin/v3/example/models"
+	"github.com/qor5/admin/v3/listingview"
 	"github.com/qor5/admin/v3/presets"
 	"github.com/qor5/web/v3"
 	"github.com/qor5/x/v3/ui/vuetify"
@@ -24,8 +26,8 @@ const (
 	ActionsAttr        = "Actions"
 )
 
-func configOrder(pb *presets.Builder, db *gorm.DB) {
-	b := pb.Model(&models.Order{})
+func configOrder(pb *presets.Builder, db *gorm.DB, listingViewBuilder *listingview.Builder) *presets.ModelBuilder {
+	b := pb.Model(&models.Order{}).Use(listingViewBuilder).VersionField("UpdatedAt")
 
 	// listing
 	lb := b.Listing(
@@ -35,7 +37,7 @@ func configOrder(pb *presets.Builder, db *gorm.DB) {
 		PaymentMethodAttr,
 		StatusAttr,
 		SourceAttr,
-	)
+	).Trash(true)
 
 	lb.Field(CreatedDateAttr).ComponentFunc(func(obj interface{}, field *presets.FieldContext, ctx *web.EventContext) h.HTMLComponent {
 		return h.Td(h.Text(field.Value(obj).(time.Time).Local().Format("2006-01-02 15:04:05")))
@@ -58,6 +60,21 @@ func configOrder(pb *presets.Builder, db *gorm.DB) {
 		return h.Td(GetColoredStatus(status))
 	})
 
+	lb.Kanban(StatusAttr).ColumnsFunc(func(ctx *web.EventContext) []*presets.KanbanColumn {
+		var columns []*presets.KanbanColumn
+		for _, status := range models.OrderStatuses {
+			columns = append(columns, &presets.KanbanColumn{Value: string(status), Label: string(status)})
+		}
+		return columns
+	}).CardFunc(func(obj interface{}, ctx *web.EventContext) h.HTMLComponent {
+		order := obj.(*models.Order)
+		return h.Div(
+			h.Div(h.Text(fmt.Sprintf("#%d", order.ID))).Class("text-subtitle-2"),
+			h.Div(h.Text(order.Source)).Class("text-caption"),
+			h.Div(h.Text(order.CreatedAt.Local().Format("2006-01-02 15:04"))).Class("text-caption text-grey"),
+		)
+	})
+
 	lb.FilterDataFunc(func(ctx *web.EventContext) vuetifyx.FilterData {
 		statusOptions := []*vuetifyx.SelectItem{}
 		for _, status := range models.OrderStatuses {
@@ -81,14 +98,23 @@ func configOrder(pb *presets.Builder, db *gorm.DB) {
 		}
 	})
 
-	lb.Action("Export").ButtonCompFunc(func(ctx *web.EventContext) h.HTMLComponent {
-		return vuetify.VBtn("Export").
-			Color("primary").
-			Variant(vuetify.VariantFlat).
-			Class("ml-2").
-			Href(exportOrdersURL)
+	lb.AdvancedFilterFieldsFunc(func(ctx *web.EventContext) []*presets.AdvancedFilterField {
+		statusOptions := []*vuetifyx.SelectItem{}
+		for _, status := range models.OrderStatuses {
+			statusOptions = append(statusOptions, &vuetifyx.SelectItem{Value: string(status), Text: string(status)})
+		}
+
+		return []*presets.AdvancedFilterField{
+			{Key: "status", Label: "Status", Column: "status", Type: presets.AdvancedFilterFieldTypeSelect, Options: statusOptions},
+			{Key: "source", Label: "Source", Column: "source", Type: presets.AdvancedFilterFieldTypeString},
+			{Key: "payment_method", Label: "Payment Method", Column: "payment_method", Type: presets.AdvancedFilterFieldTypeString},
+			{Key: "created_at", Label: "Created At", Column: "created_at", Type: presets.AdvancedFilterFieldTypeDate},
+			{Key: "confirmed_at", Label: "Check In Date", Column: "confirmed_at", Type: presets.AdvancedFilterFieldTypeDate},
+		}
 	})
 
+	lb.Export(presets.ExportFormatCSV, presets.ExportFormatXLSX)
+
 	lb.BulkAction("Change status").ComponentFunc(func(selectedIds []string, ctx *web.EventContext) h.HTMLComponent {
 		vErr := &web.ValidationErrors{}
 		if ctx.Flash != nil {
@@ -121,7 +147,24 @@ func configOrder(pb *presets.Builder, db *gorm.DB) {
 			presets.PayloadModelsUpdated{Ids: selectedIds},
 		)
 		return
-	})
+	}).UndoFunc(func(selectedIds []string, ctx *web.EventContext) (presets.UndoFunc, error) {
+		var orders []*models.Order
+		if err := db.Select("id", "status").Where("id IN (?)", selectedIds).Find(&orders).Error; err != nil {
+			return nil, err
+		}
+		return func(ctx *web.EventContext, r *web.EventResponse) error {
+			for _, order := range orders {
+				if err := db.Model(&models.Order{}).Where("id = ?", order.ID).Update("status", order.Status).Error; err != nil {
+					return err
+				}
+			}
+			r.Emit(
+				presets.NotifModelsUpdated(&models.Order{}),
+				presets.PayloadModelsUpdated{Ids: selectedIds},
+			)
+			return nil
+		}, nil
+	}).Background(2000)
 
 	// detailing
 	b.RightDrawerWidth("800")
@@ -176,6 +219,7 @@ func configOrder(pb *presets.Builder, db *gorm.DB) {
 			Label(field.Label).
 			Value(order.DeliveryMethod)
 	})
+	return b
 }
 
 func GetColoredStatus(status models.OrderStatus) h.HTMLComponent {
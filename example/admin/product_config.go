@@ -24,7 +24,9 @@ import (
 func configProduct(b *presets.Builder, _ *gorm.DB, wb *worker.Builder, publisher *publish.Builder) *presets.ModelBuilder {
 	p := b.Model(&models.Product{}).Use(publisher)
 	eb := p.Editing("StatusBar", "ScheduleBar", "Code", "Name", "Price", "Image")
-	listing := p.Listing("Code", "Name", "Price", "Image").SearchColumns("Code", "Name").SelectableColumns(true)
+	listing := p.Listing("Code", "Name", "Price", "Image").SearchColumns("Code", "Name").SelectableColumns(true).
+		InlineEditFields("Name", "Price")
+	listing.Calendar("ScheduledStartAt").EndField("ScheduledEndAt")
 	listing.ActionsAsMenu(true)
 
 	noParametersJob := wb.ActionJob(
@@ -152,17 +154,6 @@ func configProduct(b *presets.Builder, _ *gorm.DB, wb *worker.Builder, publisher
 					Attr("@click", getArgsJob.URL())
 			})
 
-	eb.ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
-		u := obj.(*models.Product)
-		if u.Code == "" {
-			err.FieldError("Code", "Code is required")
-		}
-		if u.Name == "" {
-			err.FieldError("Name", "Name is required")
-		}
-		return
-	})
-
 	eb.Field("Image").
 		WithContextValue(
 			media.MediaBoxConfig,
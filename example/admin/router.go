@@ -16,10 +16,6 @@ import (
 //go:embed assets/favicon.ico
 var favicon []byte
 
-const (
-	exportOrdersURL = "/export-orders"
-)
-
 func TestHandler(db *gorm.DB, u *models.User) http.Handler {
 	mux := http.NewServeMux()
 	c := NewConfig(db)
@@ -77,8 +73,6 @@ func Router(db *gorm.DB) http.Handler {
 		return
 	})
 
-	mux.Handle(exportOrdersURL, exportOrders(db))
-
 	// example of sitemap and robot
 	sitemap.SiteMap("product").RegisterRawString("https://dev.qor5.com/admin", "/product").MountTo(mux)
 	robot := sitemap.Robots()
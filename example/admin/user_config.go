@@ -28,7 +28,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func configUser(b *presets.Builder, ab *activity.Builder, db *gorm.DB, publisher *publish.Builder, loginSessionBuilder *plogin.SessionBuilder) {
+func configUser(b *presets.Builder, ab *activity.Builder, db *gorm.DB, publisher *publish.Builder, loginSessionBuilder *plogin.SessionBuilder) *presets.ModelBuilder {
 	user := b.Model(&models.User{})
 	// MenuIcon("people")
 	defer func() { ab.RegisterModel(user) }()
@@ -371,6 +371,7 @@ func configUser(b *presets.Builder, ab *activity.Builder, db *gorm.DB, publisher
 			tab,
 		}
 	})
+	return user
 }
 
 const (
@@ -15,9 +15,9 @@ import (
 type Product struct {
 	gorm.Model
 
-	Code  string
-	Name  string
-	Price int
+	Code  string                 `presets_validate:"required,max=20"`
+	Name  string                 `presets_validate:"required,max=100"`
+	Price int                    `presets_validate:"min=0"`
 	Image media_library.MediaBox `sql:"type:text;"`
 	publish.Status
 	publish.Schedule
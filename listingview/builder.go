@@ -0,0 +1,184 @@
+package listingview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type User struct {
+	ID    string
+	Roles []string
+}
+
+// Builder stores the saved listing views of presets models in the database,
+// a view is visible to its owner and to the users who have the role it is shared with.
+type Builder struct {
+	db                 *gorm.DB
+	currentUserFunc    func(ctx context.Context) (*User, error)
+	shareableRolesFunc func(ctx context.Context, user *User) ([]string, error)
+}
+
+var (
+	_ presets.ListingViewStore = (*Builder)(nil)
+	_ presets.ModelPlugin      = (*Builder)(nil)
+)
+
+func New(db *gorm.DB, currentUserFunc func(ctx context.Context) (*User, error)) *Builder {
+	return &Builder{
+		db:              db,
+		currentUserFunc: currentUserFunc,
+	}
+}
+
+// ShareableRolesFunc returns the roles a user can share views with, by default they are the roles of the user.
+func (b *Builder) ShareableRolesFunc(v func(ctx context.Context, user *User) ([]string, error)) *Builder {
+	b.shareableRolesFunc = v
+	return b
+}
+
+func (b *Builder) AutoMigrate() (r *Builder) {
+	if err := AutoMigrate(b.db); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func AutoMigrate(db *gorm.DB) error {
+	return errors.Wrap(db.AutoMigrate(&ListingView{}), "auto migrate")
+}
+
+// ModelInstall enables saved views for the model, e.g. `mb.Use(listingViewBuilder)`
+func (b *Builder) ModelInstall(_ *presets.Builder, m *presets.ModelBuilder) error {
+	m.Listing().ViewStore(b)
+	return nil
+}
+
+func (b *Builder) currentUser(ctx *web.EventContext) (*User, error) {
+	user, err := b.currentUserFunc(ctx.R.Context())
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.ID == "" {
+		return nil, errors.New("current user is required")
+	}
+	return user, nil
+}
+
+func (b *Builder) ListViews(ctx *web.EventContext, modelName string) ([]*presets.ListingView, error) {
+	user, err := b.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := b.db.WithContext(ctx.R.Context()).Where("model_name = ?", modelName)
+	if len(user.Roles) > 0 {
+		db = db.Where("owner_id = ? OR shared_role IN ?", user.ID, user.Roles)
+	} else {
+		db = db.Where("owner_id = ?", user.ID)
+	}
+
+	var records []*ListingView
+	if err := db.Order("id").Find(&records).Error; err != nil {
+		return nil, errors.Wrap(err, "list views")
+	}
+
+	views := make([]*presets.ListingView, 0, len(records))
+	for _, rec := range records {
+		view := &presets.ListingView{}
+		if rec.State != "" {
+			if err := json.Unmarshal([]byte(rec.State), view); err != nil {
+				return nil, errors.Wrapf(err, "unmarshal view %d", rec.ID)
+			}
+		}
+		view.ID = fmt.Sprint(rec.ID)
+		view.Name = rec.Name
+		view.SharedRole = rec.SharedRole
+		view.Editable = rec.OwnerID == user.ID
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func (b *Builder) ShareableRoles(ctx *web.EventContext) ([]string, error) {
+	user, err := b.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if b.shareableRolesFunc != nil {
+		return b.shareableRolesFunc(ctx.R.Context(), user)
+	}
+	return lo.Uniq(user.Roles), nil
+}
+
+func (b *Builder) SaveView(ctx *web.EventContext, modelName string, view *presets.ListingView) error {
+	user, err := b.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, err := json.Marshal(&presets.ListingView{
+		Keyword:        view.Keyword,
+		FilterQuery:    view.FilterQuery,
+		OrderBys:       view.OrderBys,
+		DisplayColumns: view.DisplayColumns,
+		PerPage:        view.PerPage,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal view")
+	}
+
+	rec := &ListingView{
+		ModelName:  modelName,
+		Name:       view.Name,
+		OwnerID:    user.ID,
+		SharedRole: view.SharedRole,
+		State:      string(state),
+	}
+	db := b.db.WithContext(ctx.R.Context())
+	if view.ID == "" {
+		if err := db.Create(rec).Error; err != nil {
+			return errors.Wrap(err, "create view")
+		}
+		view.ID = fmt.Sprint(rec.ID)
+		return nil
+	}
+
+	result := db.Model(&ListingView{}).
+		Where("id = ? AND model_name = ? AND owner_id = ?", view.ID, modelName, user.ID).
+		Updates(map[string]any{
+			"name":        rec.Name,
+			"shared_role": rec.SharedRole,
+			"state":       rec.State,
+		})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "update view")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("view not found")
+	}
+	return nil
+}
+
+func (b *Builder) DeleteView(ctx *web.EventContext, modelName string, id string) error {
+	user, err := b.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	result := b.db.WithContext(ctx.R.Context()).
+		Where("id = ? AND model_name = ? AND owner_id = ?", id, modelName, user.ID).
+		Delete(&ListingView{})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "delete view")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("view not found")
+	}
+	return nil
+}
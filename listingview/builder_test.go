@@ -0,0 +1,78 @@
+package listingview
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type ctxKeyUser struct{}
+
+func newEventContext(user *User) *web.EventContext {
+	r := httptest.NewRequest("GET", "/", nil)
+	return &web.EventContext{R: r.WithContext(context.WithValue(r.Context(), ctxKeyUser{}, user))}
+}
+
+func TestViews(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	b := New(db, func(ctx context.Context) (*User, error) {
+		return ctx.Value(ctxKeyUser{}).(*User), nil
+	}).AutoMigrate()
+
+	alice := newEventContext(&User{ID: "1", Roles: []string{"sales"}})
+	bob := newEventContext(&User{ID: "2", Roles: []string{"sales"}})
+	carol := newEventContext(&User{ID: "3"})
+
+	private := &presets.ListingView{
+		Name:        "My pending orders",
+		FilterQuery: "status=pending",
+		OrderBys:    []presets.ColOrderBy{{FieldName: "ID", OrderBy: presets.OrderByDESC}},
+		PerPage:     20,
+	}
+	require.NoError(t, b.SaveView(alice, "orders", private))
+	shared := &presets.ListingView{Name: "Paid", FilterQuery: "status=paid", SharedRole: "sales"}
+	require.NoError(t, b.SaveView(alice, "orders", shared))
+
+	views, err := b.ListViews(alice, "orders")
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, private.ID, views[0].ID)
+	assert.Equal(t, "status=pending", views[0].FilterQuery)
+	assert.Equal(t, private.OrderBys, views[0].OrderBys)
+	assert.EqualValues(t, 20, views[0].PerPage)
+	assert.True(t, views[0].Editable)
+
+	views, err = b.ListViews(bob, "orders")
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "Paid", views[0].Name)
+	assert.False(t, views[0].Editable)
+
+	views, err = b.ListViews(carol, "orders")
+	require.NoError(t, err)
+	assert.Empty(t, views)
+
+	views, err = b.ListViews(alice, "products")
+	require.NoError(t, err)
+	assert.Empty(t, views)
+
+	// only the owner can update or delete the view
+	shared.Name = "Paid orders"
+	assert.Error(t, b.SaveView(bob, "orders", shared))
+	require.NoError(t, b.SaveView(alice, "orders", shared))
+	assert.Error(t, b.DeleteView(bob, "orders", shared.ID))
+	require.NoError(t, b.DeleteView(alice, "orders", private.ID))
+
+	views, err = b.ListViews(alice, "orders")
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "Paid orders", views[0].Name)
+}
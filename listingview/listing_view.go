@@ -0,0 +1,15 @@
+package listingview
+
+import "gorm.io/gorm"
+
+type ListingView struct {
+	gorm.Model
+
+	ModelName string `gorm:"index;not null"`
+	Name      string `gorm:"not null"`
+	OwnerID   string `gorm:"index;not null"`
+	// SharedRole is empty if the view is private
+	SharedRole string `gorm:"index"`
+	// State is the json of the keyword, filter, order bys, columns and page size
+	State string
+}
@@ -82,7 +82,10 @@ func cropField(field *schema.Field, db *gorm.DB) (cropped bool, err error) {
 	return true, nil
 }
 
-func SaveUploadAndCropImage(db *gorm.DB, obj interface{}, _ string, _ *web.EventContext) (err error) {
+func SaveUploadAndCropImage(db *gorm.DB, obj interface{}, _ string, ctx *web.EventContext) (err error) {
+	if ctx != nil && ctx.R != nil {
+		db = db.WithContext(ctx.R.Context())
+	}
 	db = db.Model(obj).Save(obj)
 	err = db.Error
 	if err != nil {
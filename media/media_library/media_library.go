@@ -26,6 +26,8 @@ type MediaLibrary struct {
 	UserID       uint                `gorm:"index"`
 	Folder       bool                `gorm:"default:false"`
 	ParentId     uint                `gorm:"index;default:0"`
+	// TenantID is the tenant of the request which uploads the file
+	TenantID string `gorm:"index"`
 }
 
 type MediaOption struct {
@@ -7,7 +7,16 @@ const (
 	Update                     = "presets_Update"
 	DoAction                   = "presets_DoAction"
 	DoDelete                   = "presets_DoDelete"
+	Undo                       = "presets_Undo"
+	SaveDraft                  = "presets_SaveDraft"
+	RestoreDraft               = "presets_RestoreDraft"
+	DiscardDraft               = "presets_DiscardDraft"
+	ReloadDependentField       = "presets_ReloadDependentField"
+	WizardStep                 = "presets_WizardStep"
+	AssociationOptions         = "presets_AssociationOptions"
+	Duplicate                  = "presets_Duplicate"
 	NotificationCenter         = "presets_NotificationCenter"
+	GlobalSearch               = "presets_GlobalSearch"
 	DetailingDrawer            = "presets_DetailingDrawer"
 	DoSaveDetailingField       = "presets_Detailing_Field_Save"
 	DoEditDetailingField       = "presets_Detailing_Field_Edit"
@@ -18,6 +27,8 @@ const (
 
 	DeleteConfirmation = "presets_DeleteConfirmation"
 	OpenListingDialog  = "presets_OpenListingDialog"
+	OpenImportDialog   = "presets_OpenImportDialog"
+	DoImport           = "presets_DoImport"
 
 	// list editor
 	AddRowEvent    = "listEditor_addRowEvent"
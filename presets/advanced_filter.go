@@ -0,0 +1,492 @@
+package presets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	vx "github.com/qor5/x/v3/ui/vuetifyx"
+	"github.com/samber/lo"
+	h "github.com/theplant/htmlgo"
+)
+
+// ParamAdvancedFilter is the key of the advanced filter in FilterQuery, its value is the JSON of AdvancedFilterGroup
+const ParamAdvancedFilter = "advanced_filter"
+
+const (
+	advancedFilterMaxDepth      = 3
+	advancedFilterMaxConditions = 50
+)
+
+type AdvancedFilterFieldType string
+
+const (
+	AdvancedFilterFieldTypeString AdvancedFilterFieldType = "string"
+	AdvancedFilterFieldTypeNumber AdvancedFilterFieldType = "number"
+	AdvancedFilterFieldTypeDate   AdvancedFilterFieldType = "date"
+	AdvancedFilterFieldTypeBool   AdvancedFilterFieldType = "bool"
+	AdvancedFilterFieldTypeSelect AdvancedFilterFieldType = "select"
+)
+
+const (
+	AdvancedFilterOpEquals             = "eq"
+	AdvancedFilterOpNotEquals          = "ne"
+	AdvancedFilterOpContains           = "contains"
+	AdvancedFilterOpNotContains        = "not_contains"
+	AdvancedFilterOpStartsWith         = "starts_with"
+	AdvancedFilterOpGreaterThan        = "gt"
+	AdvancedFilterOpGreaterThanOrEqual = "gte"
+	AdvancedFilterOpLessThan           = "lt"
+	AdvancedFilterOpLessThanOrEqual    = "lte"
+	AdvancedFilterOpOn                 = "on"
+	AdvancedFilterOpBefore             = "before"
+	AdvancedFilterOpBeforeOrOn         = "before_or_on"
+	AdvancedFilterOpAfter              = "after"
+	AdvancedFilterOpAfterOrOn          = "after_or_on"
+	AdvancedFilterOpIn                 = "in"
+	AdvancedFilterOpNotIn              = "not_in"
+	AdvancedFilterOpIsTrue             = "true"
+	AdvancedFilterOpIsFalse            = "false"
+	AdvancedFilterOpIsEmpty            = "empty"
+	AdvancedFilterOpIsNotEmpty         = "not_empty"
+)
+
+var advancedFilterOperators = map[AdvancedFilterFieldType][]string{
+	AdvancedFilterFieldTypeString: {
+		AdvancedFilterOpEquals, AdvancedFilterOpNotEquals, AdvancedFilterOpContains, AdvancedFilterOpNotContains,
+		AdvancedFilterOpStartsWith, AdvancedFilterOpIsEmpty, AdvancedFilterOpIsNotEmpty,
+	},
+	AdvancedFilterFieldTypeNumber: {
+		AdvancedFilterOpEquals, AdvancedFilterOpNotEquals, AdvancedFilterOpGreaterThan, AdvancedFilterOpGreaterThanOrEqual,
+		AdvancedFilterOpLessThan, AdvancedFilterOpLessThanOrEqual, AdvancedFilterOpIsEmpty, AdvancedFilterOpIsNotEmpty,
+	},
+	AdvancedFilterFieldTypeDate: {
+		AdvancedFilterOpOn, AdvancedFilterOpBefore, AdvancedFilterOpBeforeOrOn, AdvancedFilterOpAfter,
+		AdvancedFilterOpAfterOrOn, AdvancedFilterOpIsEmpty, AdvancedFilterOpIsNotEmpty,
+	},
+	AdvancedFilterFieldTypeBool:   {AdvancedFilterOpIsTrue, AdvancedFilterOpIsFalse},
+	AdvancedFilterFieldTypeSelect: {AdvancedFilterOpIn, AdvancedFilterOpNotIn},
+}
+
+// AdvancedFilterField is a field which can be used in the advanced filter,
+// Column is put into the SQL as it is, so it must not come from the user input.
+type AdvancedFilterField struct {
+	Key     string
+	Label   string
+	Column  string
+	Type    AdvancedFilterFieldType
+	Options []*vx.SelectItem
+}
+
+type AdvancedFilterFieldsFunc func(ctx *web.EventContext) []*AdvancedFilterField
+
+// AdvancedFilterGroup is a group of conditions and sub groups combined with AND or OR
+type AdvancedFilterGroup struct {
+	Or         bool                       `json:"or"`
+	Conditions []*AdvancedFilterCondition `json:"conditions"`
+	Groups     []*AdvancedFilterGroup     `json:"groups"`
+}
+
+type AdvancedFilterCondition struct {
+	Field    string   `json:"field"`
+	Operator string   `json:"operator"`
+	Value    string   `json:"value"`
+	Values   []string `json:"values"`
+}
+
+// AdvancedFilterFieldsFunc enables the advanced filter of the listing, which combines conditions
+// of the fields with nested AND/OR groups.
+func (b *ListingBuilder) AdvancedFilterFieldsFunc(v AdvancedFilterFieldsFunc) (r *ListingBuilder) {
+	b.advancedFilterFieldsFunc = v
+	return b
+}
+
+// ParseAdvancedFilter parses the advanced filter from FilterQuery, it returns nil if there is none
+func ParseAdvancedFilter(filterQuery string) (*AdvancedFilterGroup, error) {
+	qs, err := url.ParseQuery(filterQuery)
+	if err != nil {
+		return nil, err
+	}
+	v := qs.Get(ParamAdvancedFilter)
+	if v == "" {
+		return nil, nil
+	}
+	g := &AdvancedFilterGroup{}
+	if err := json.Unmarshal([]byte(v), g); err != nil {
+		return nil, errors.Wrap(err, "invalid advanced filter")
+	}
+	return g, nil
+}
+
+// Compile compiles the group into a parameterized SQL condition, the values are always passed as arguments,
+// the columns come from the fields, and the unknown fields and operators are rejected.
+// It returns nil if the group has no conditions.
+func (g *AdvancedFilterGroup) Compile(fields []*AdvancedFilterField) (*SQLCondition, error) {
+	fieldMap := lo.SliceToMap(fields, func(f *AdvancedFilterField) (string, *AdvancedFilterField) {
+		return f.Key, f
+	})
+	count := 0
+	query, args, err := g.compile(fieldMap, 0, &count)
+	if err != nil || query == "" {
+		return nil, err
+	}
+	return &SQLCondition{Query: query, Args: args}, nil
+}
+
+func (g *AdvancedFilterGroup) compile(fieldMap map[string]*AdvancedFilterField, depth int, count *int) (string, []interface{}, error) {
+	if depth >= advancedFilterMaxDepth {
+		return "", nil, errors.New("advanced filter is nested too deep")
+	}
+	var parts []string
+	var args []interface{}
+	for _, cond := range g.Conditions {
+		*count++
+		if *count > advancedFilterMaxConditions {
+			return "", nil, errors.New("advanced filter has too many conditions")
+		}
+		f, ok := fieldMap[cond.Field]
+		if !ok {
+			return "", nil, errors.Errorf("unknown field %q", cond.Field)
+		}
+		q, a, err := cond.compile(f)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, "("+q+")")
+		args = append(args, a...)
+	}
+	for _, sub := range g.Groups {
+		q, a, err := sub.compile(fieldMap, depth+1, count)
+		if err != nil {
+			return "", nil, err
+		}
+		if q == "" {
+			continue
+		}
+		parts = append(parts, "("+q+")")
+		args = append(args, a...)
+	}
+	sep := " AND "
+	if g.Or {
+		sep = " OR "
+	}
+	return strings.Join(parts, sep), args, nil
+}
+
+var likeWildcardReg = regexp.MustCompile(`([%_\\])`)
+
+func (cond *AdvancedFilterCondition) compile(f *AdvancedFilterField) (string, []interface{}, error) {
+	if !lo.Contains(advancedFilterOperators[f.Type], cond.Operator) {
+		return "", nil, errors.Errorf("unsupported operator %q for field %q", cond.Operator, f.Key)
+	}
+	col := f.Column
+
+	switch cond.Operator {
+	case AdvancedFilterOpIsEmpty:
+		if f.Type == AdvancedFilterFieldTypeString {
+			return fmt.Sprintf("%s IS NULL OR %s = ''", col, col), nil, nil
+		}
+		return fmt.Sprintf("%s IS NULL", col), nil, nil
+	case AdvancedFilterOpIsNotEmpty:
+		if f.Type == AdvancedFilterFieldTypeString {
+			return fmt.Sprintf("%s IS NOT NULL AND %s <> ''", col, col), nil, nil
+		}
+		return fmt.Sprintf("%s IS NOT NULL", col), nil, nil
+	case AdvancedFilterOpIsTrue:
+		return fmt.Sprintf("%s = ?", col), []interface{}{true}, nil
+	case AdvancedFilterOpIsFalse:
+		return fmt.Sprintf("%s = ?", col), []interface{}{false}, nil
+	case AdvancedFilterOpIn, AdvancedFilterOpNotIn:
+		if len(cond.Values) == 0 {
+			return "", nil, errors.Errorf("values of field %q are required", f.Key)
+		}
+		if cond.Operator == AdvancedFilterOpNotIn {
+			return fmt.Sprintf("%s NOT IN ?", col), []interface{}{cond.Values}, nil
+		}
+		return fmt.Sprintf("%s IN ?", col), []interface{}{cond.Values}, nil
+	}
+
+	var value interface{} = cond.Value
+	switch f.Type {
+	case AdvancedFilterFieldTypeNumber:
+		n, err := strconv.ParseFloat(strings.TrimSpace(cond.Value), 64)
+		if err != nil {
+			return "", nil, errors.Errorf("value of field %q must be a number", f.Key)
+		}
+		value = n
+	case AdvancedFilterFieldTypeDate:
+		day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(cond.Value), time.Local)
+		if err != nil {
+			return "", nil, errors.Errorf("value of field %q must be a date", f.Key)
+		}
+		next := day.AddDate(0, 0, 1)
+		switch cond.Operator {
+		case AdvancedFilterOpOn:
+			return fmt.Sprintf("%s >= ? AND %s < ?", col, col), []interface{}{day, next}, nil
+		case AdvancedFilterOpBefore:
+			return fmt.Sprintf("%s < ?", col), []interface{}{day}, nil
+		case AdvancedFilterOpBeforeOrOn:
+			return fmt.Sprintf("%s < ?", col), []interface{}{next}, nil
+		case AdvancedFilterOpAfter:
+			return fmt.Sprintf("%s >= ?", col), []interface{}{next}, nil
+		case AdvancedFilterOpAfterOrOn:
+			return fmt.Sprintf("%s >= ?", col), []interface{}{day}, nil
+		}
+	}
+
+	switch cond.Operator {
+	case AdvancedFilterOpEquals:
+		return fmt.Sprintf("%s = ?", col), []interface{}{value}, nil
+	case AdvancedFilterOpNotEquals:
+		return fmt.Sprintf("%s <> ?", col), []interface{}{value}, nil
+	case AdvancedFilterOpGreaterThan:
+		return fmt.Sprintf("%s > ?", col), []interface{}{value}, nil
+	case AdvancedFilterOpGreaterThanOrEqual:
+		return fmt.Sprintf("%s >= ?", col), []interface{}{value}, nil
+	case AdvancedFilterOpLessThan:
+		return fmt.Sprintf("%s < ?", col), []interface{}{value}, nil
+	case AdvancedFilterOpLessThanOrEqual:
+		return fmt.Sprintf("%s <= ?", col), []interface{}{value}, nil
+	case AdvancedFilterOpContains:
+		return fmt.Sprintf("%s ILIKE ?", col), []interface{}{"%" + likeWildcardReg.ReplaceAllString(cond.Value, `\$1`) + "%"}, nil
+	case AdvancedFilterOpNotContains:
+		return fmt.Sprintf("%s NOT ILIKE ?", col), []interface{}{"%" + likeWildcardReg.ReplaceAllString(cond.Value, `\$1`) + "%"}, nil
+	case AdvancedFilterOpStartsWith:
+		return fmt.Sprintf("%s ILIKE ?", col), []interface{}{likeWildcardReg.ReplaceAllString(cond.Value, `\$1`) + "%"}, nil
+	}
+	return "", nil, errors.Errorf("unsupported operator %q for field %q", cond.Operator, f.Key)
+}
+
+// normalize fills the nil slices so that the group can be edited in the front end
+func (g *AdvancedFilterGroup) normalize() *AdvancedFilterGroup {
+	if g.Conditions == nil {
+		g.Conditions = []*AdvancedFilterCondition{}
+	}
+	for _, cond := range g.Conditions {
+		if cond.Values == nil {
+			cond.Values = []string{}
+		}
+	}
+	if g.Groups == nil {
+		g.Groups = []*AdvancedFilterGroup{}
+	}
+	for _, sub := range g.Groups {
+		sub.normalize()
+	}
+	return g
+}
+
+func (c *ListingCompo) advancedFilterCondition(evCtx *web.EventContext) (*SQLCondition, error) {
+	if c.lb.advancedFilterFieldsFunc == nil {
+		return nil, nil
+	}
+	g, err := ParseAdvancedFilter(c.FilterQuery)
+	if err != nil || g == nil {
+		return nil, err
+	}
+	return g.Compile(c.lb.advancedFilterFieldsFunc(evCtx))
+}
+
+// checkAdvancedFilter returns the error of the advanced filter which can't be parsed or compiled,
+// the export and the bulk actions on all the matching records must not run without the filter.
+func (c *ListingCompo) checkAdvancedFilter(evCtx *web.EventContext) error {
+	if _, err := c.advancedFilterCondition(evCtx); err != nil {
+		return c.advancedFilterError(evCtx, err)
+	}
+	return nil
+}
+
+func (c *ListingCompo) advancedFilterError(evCtx *web.EventContext, err error) error {
+	return errors.Errorf("%s: %s", MustGetMessages(evCtx.R).AdvancedFilterInvalid, err.Error())
+}
+
+func (c *ListingCompo) advancedFilterButton(ctx context.Context) h.HTMLComponent {
+	if c.lb.advancedFilterFieldsFunc == nil {
+		return nil
+	}
+	_, msgr := c.MustGetEventContext(ctx)
+	g, _ := ParseAdvancedFilter(c.FilterQuery)
+	variant := VariantText
+	if g != nil {
+		variant = VariantTonal
+	}
+	return VBtn(msgr.AdvancedFilter).PrependIcon("mdi-filter-variant-plus").Variant(variant).Size(SizeSmall).
+		Color(ColorPrimary).Class("ml-2").
+		Attr("@click", stateful.PostAction(ctx, c, c.OpenAdvancedFilterDialog, OpenAdvancedFilterDialogRequest{}).Go())
+}
+
+type OpenAdvancedFilterDialogRequest struct{}
+
+func (c *ListingCompo) OpenAdvancedFilterDialog(ctx context.Context, _ OpenAdvancedFilterDialogRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if c.lb.advancedFilterFieldsFunc == nil {
+		return r, errors.New("advanced filter is not enabled")
+	}
+	fields := c.lb.advancedFilterFieldsFunc(evCtx)
+
+	g, _ := ParseAdvancedFilter(c.FilterQuery)
+	if g == nil {
+		g = &AdvancedFilterGroup{}
+	}
+
+	operatorLabels := map[string]string{
+		AdvancedFilterOpEquals:             msgr.FiltersStringEquals,
+		AdvancedFilterOpNotEquals:          msgr.AdvancedFilterNotEquals,
+		AdvancedFilterOpContains:           msgr.FiltersStringContains,
+		AdvancedFilterOpNotContains:        msgr.AdvancedFilterNotContains,
+		AdvancedFilterOpStartsWith:         msgr.AdvancedFilterStartsWith,
+		AdvancedFilterOpGreaterThan:        msgr.FiltersNumberGreaterThan,
+		AdvancedFilterOpGreaterThanOrEqual: msgr.AdvancedFilterGreaterThanOrEqual,
+		AdvancedFilterOpLessThan:           msgr.FiltersNumberLessThan,
+		AdvancedFilterOpLessThanOrEqual:    msgr.AdvancedFilterLessThanOrEqual,
+		AdvancedFilterOpOn:                 msgr.FiltersDateEquals,
+		AdvancedFilterOpBefore:             msgr.FiltersDateIsBefore,
+		AdvancedFilterOpBeforeOrOn:         msgr.FiltersDateIsBeforeOrOn,
+		AdvancedFilterOpAfter:              msgr.FiltersDateIsAfter,
+		AdvancedFilterOpAfterOrOn:          msgr.FiltersDateIsAfterOrOn,
+		AdvancedFilterOpIn:                 msgr.FiltersMultipleSelectIn,
+		AdvancedFilterOpNotIn:              msgr.FiltersMultipleSelectNotIn,
+		AdvancedFilterOpIsTrue:             msgr.AdvancedFilterIsTrue,
+		AdvancedFilterOpIsFalse:            msgr.AdvancedFilterIsFalse,
+		AdvancedFilterOpIsEmpty:            msgr.AdvancedFilterIsEmpty,
+		AdvancedFilterOpIsNotEmpty:         msgr.AdvancedFilterIsNotEmpty,
+	}
+	type item struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+	operators := map[AdvancedFilterFieldType][]item{}
+	for typ, ops := range advancedFilterOperators {
+		operators[typ] = lo.Map(ops, func(op string, _ int) item {
+			return item{Title: operatorLabels[op], Value: op}
+		})
+	}
+	type fieldInfo struct {
+		Type    AdvancedFilterFieldType `json:"type"`
+		Options []item                  `json:"options"`
+	}
+	fieldInfos := map[string]fieldInfo{}
+	for _, f := range fields {
+		fieldInfos[f.Key] = fieldInfo{
+			Type: f.Type,
+			Options: lo.Map(f.Options, func(o *vx.SelectItem, _ int) item {
+				return item{Title: o.Text, Value: o.Value}
+			}),
+		}
+	}
+
+	apply := func(filter string) string {
+		return stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+			target.Page = 0
+			target.After, target.Before = nil, nil
+		}, stateful.WithAppendFix(fmt.Sprintf(`
+			const qs = new URLSearchParams(v.compo.filter_query || "");
+			const filter = %s;
+			if (filter && (filter.conditions.length > 0 || filter.groups.length > 0)) {
+				qs.set(%q, JSON.stringify(filter));
+			} else {
+				qs.delete(%q);
+			}
+			v.compo.filter_query = qs.toString();`, filter, ParamAdvancedFilter, ParamAdvancedFilter)),
+		).ThenScript(c.closeActionDialog() + ListingCompo_JsScrollToTop).Go()
+	}
+
+	c.dialog(&r, web.Scope(
+		VCard(
+			VCardTitle(h.Text(msgr.AdvancedFilter)),
+			VCardText(
+				c.advancedFilterGroupEditor(ctx, fields, 0, "xlocals.filter", "", ""),
+			).Attr("style", "max-height: 70vh; overflow-y: auto;"),
+			VCardActions(
+				VBtn(msgr.FiltersClear).Variant(VariantText).Attr("@click", apply("null")),
+				VSpacer(),
+				VBtn(msgr.Cancel).Variant(VariantFlat).Class("ml-2").Attr("@click", c.closeActionDialog()),
+				VBtn(msgr.FilterApply).Color(ColorPrimary).Variant(VariantFlat).Theme(ThemeDark).
+					Attr("@click", apply("xlocals.filter")),
+			),
+		),
+	).VSlot("{ locals: xlocals }").Init(map[string]any{
+		"filter":    g.normalize(),
+		"fields":    fieldInfos,
+		"operators": operators,
+	}), "800")
+	return r, nil
+}
+
+// advancedFilterGroupEditor renders the editor of the group in the JS variable g,
+// the nested groups are rendered recursively with the variables of the depth.
+func (c *ListingCompo) advancedFilterGroupEditor(ctx context.Context, fields []*AdvancedFilterField, depth int, g, parent, index string) h.HTMLComponent {
+	_, msgr := c.MustGetEventContext(ctx)
+	cond := fmt.Sprintf("c%d", depth)
+	ci := fmt.Sprintf("ci%d", depth)
+	fieldType := fmt.Sprintf("xlocals.fields[%s.field]?.type", cond)
+
+	var removeGroup h.HTMLComponent
+	if parent != "" {
+		removeGroup = VBtn("").Icon("mdi-close").Size(SizeSmall).Variant(VariantText).
+			Attr("@click", fmt.Sprintf("%s.groups.splice(%s, 1)", parent, index))
+	}
+
+	var subGroups, addGroup h.HTMLComponent
+	if depth+1 < advancedFilterMaxDepth {
+		sub := fmt.Sprintf("g%d", depth+1)
+		si := fmt.Sprintf("gi%d", depth+1)
+		subGroups = h.Div(
+			c.advancedFilterGroupEditor(ctx, fields, depth+1, sub, g, si),
+		).Attr("v-for", fmt.Sprintf("(%s, %s) in %s.groups", sub, si, g)).Attr(":key", si)
+		addGroup = VBtn(msgr.AdvancedFilterAddGroup).PrependIcon("mdi-plus").Size(SizeSmall).Variant(VariantText).
+			Attr("@click", fmt.Sprintf("%s.groups.push({or: false, conditions: [], groups: []})", g))
+	}
+
+	return VSheet(
+		h.Div(
+			VBtnToggle(
+				VBtn(msgr.AdvancedFilterAnd).Size(SizeSmall).Value(false),
+				VBtn(msgr.AdvancedFilterOr).Size(SizeSmall).Value(true),
+			).Attr("v-model", g+".or").Mandatory(true).Density(DensityCompact).Variant(VariantOutlined).Divided(true),
+			VSpacer(),
+			removeGroup,
+		).Class("d-flex align-center mb-2"),
+		h.Div(
+			VSelect().Items(lo.Map(fields, func(f *AdvancedFilterField, _ int) map[string]string {
+				return map[string]string{"title": f.Label, "value": f.Key}
+			})).Label(msgr.AdvancedFilterField).Density(DensityCompact).HideDetails(true).Class("mr-2").
+				Attr("v-model", cond+".field").
+				Attr("@update:model-value", fmt.Sprintf(`%s.operator = (xlocals.operators[%s] || [{}])[0].value || ""; %s.value = ""; %s.values = [];`,
+					cond, fieldType, cond, cond)).
+				Attr("style", "max-width: 200px;"),
+			VSelect().Attr(":items", fmt.Sprintf("xlocals.operators[%s] || []", fieldType)).
+				Label(msgr.AdvancedFilterOperator).Density(DensityCompact).HideDetails(true).Class("mr-2").
+				Attr("v-model", cond+".operator").
+				Attr("style", "max-width: 220px;"),
+			VSelect().Attr(":items", fmt.Sprintf("xlocals.fields[%s.field].options", cond)).
+				Label(msgr.AdvancedFilterValue).Multiple(true).Chips(true).Density(DensityCompact).HideDetails(true).
+				Attr("v-model", cond+".values").
+				Attr("v-if", fmt.Sprintf("%s === %q", fieldType, AdvancedFilterFieldTypeSelect)),
+			VTextField().Label(msgr.AdvancedFilterValue).Density(DensityCompact).HideDetails(true).
+				Attr("v-model", cond+".value").
+				Attr(":type", fmt.Sprintf(`{%q: "number", %q: "date"}[%s] || "text"`, AdvancedFilterFieldTypeNumber, AdvancedFilterFieldTypeDate, fieldType)).
+				Attr("v-else-if", fmt.Sprintf(`%s && %s !== %q && ![%q, %q].includes(%s.operator)`,
+					cond+".field", fieldType, AdvancedFilterFieldTypeBool, AdvancedFilterOpIsEmpty, AdvancedFilterOpIsNotEmpty, cond)),
+			VSpacer(),
+			VBtn("").Icon("mdi-delete-outline").Size(SizeSmall).Variant(VariantText).
+				Attr("@click", fmt.Sprintf("%s.conditions.splice(%s, 1)", g, ci)),
+		).Class("d-flex align-center mb-2").
+			Attr("v-for", fmt.Sprintf("(%s, %s) in %s.conditions", cond, ci, g)).Attr(":key", ci),
+		subGroups,
+		h.Div(
+			VBtn(msgr.AdvancedFilterAddCondition).PrependIcon("mdi-plus").Size(SizeSmall).Variant(VariantText).
+				Attr("@click", fmt.Sprintf(`%s.conditions.push({field: "", operator: "", value: "", values: []})`, g)),
+			addGroup,
+		),
+	).Border(true).Rounded(true).Class("pa-2 mb-2")
+}
@@ -0,0 +1,113 @@
+package presets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+func TestAdvancedFilterCompile(t *testing.T) {
+	fields := []*AdvancedFilterField{
+		{Key: "status", Column: "status", Type: AdvancedFilterFieldTypeSelect},
+		{Key: "total", Column: "total", Type: AdvancedFilterFieldTypeNumber},
+		{Key: "name", Column: "name", Type: AdvancedFilterFieldTypeString},
+		{Key: "created", Column: "created_at", Type: AdvancedFilterFieldTypeDate},
+	}
+
+	filterQuery := url.Values{ParamAdvancedFilter: {`{
+		"conditions": [{"field": "total", "operator": "gt", "value": "100"}],
+		"groups": [{
+			"or": true,
+			"conditions": [
+				{"field": "status", "operator": "in", "values": ["paid"]},
+				{"field": "name", "operator": "contains", "value": "50%_off'; DROP TABLE x; --"}
+			]
+		}]
+	}`}, "status.in": {"paid"}}.Encode()
+	g, err := ParseAdvancedFilter(filterQuery)
+	require.NoError(t, err)
+	cond, err := g.Compile(fields)
+	require.NoError(t, err)
+	assert.Equal(t, "(total > ?) AND ((status IN ?) OR (name ILIKE ?))", cond.Query)
+	assert.Equal(t, []interface{}{float64(100), []string{"paid"}, `%50\%\_off'; DROP TABLE x; --%`}, cond.Args)
+
+	cond, err = (&AdvancedFilterGroup{Conditions: []*AdvancedFilterCondition{
+		{Field: "created", Operator: AdvancedFilterOpOn, Value: "2024-10-16"},
+	}}).Compile(fields)
+	require.NoError(t, err)
+	day := time.Date(2024, 10, 16, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, "(created_at >= ? AND created_at < ?)", cond.Query)
+	assert.Equal(t, []interface{}{day, day.AddDate(0, 0, 1)}, cond.Args)
+
+	cond, err = (&AdvancedFilterGroup{}).Compile(fields)
+	require.NoError(t, err)
+	assert.Nil(t, cond)
+
+	for _, c := range []*AdvancedFilterCondition{
+		{Field: "id; DROP TABLE x", Operator: AdvancedFilterOpEquals, Value: "1"},
+		{Field: "total", Operator: "= 1 OR 1", Value: "1"},
+		{Field: "total", Operator: AdvancedFilterOpContains, Value: "1"},
+		{Field: "total", Operator: AdvancedFilterOpEquals, Value: "1 OR 1=1"},
+		{Field: "created", Operator: AdvancedFilterOpBefore, Value: "yesterday"},
+		{Field: "status", Operator: AdvancedFilterOpIn},
+	} {
+		_, err := (&AdvancedFilterGroup{Conditions: []*AdvancedFilterCondition{c}}).Compile(fields)
+		assert.Error(t, err, c.Field)
+	}
+
+	deep := &AdvancedFilterGroup{Groups: []*AdvancedFilterGroup{{Groups: []*AdvancedFilterGroup{{Groups: []*AdvancedFilterGroup{{}}}}}}}
+	_, err = deep.Compile(fields)
+	assert.Error(t, err)
+
+	_, err = ParseAdvancedFilter(url.Values{ParamAdvancedFilter: {"{"}}.Encode())
+	assert.Error(t, err)
+}
+
+func TestInvalidAdvancedFilter(t *testing.T) {
+	var searched bool
+	mb := New().Model(&foo{})
+	mb.Listing().AdvancedFilterFieldsFunc(func(ctx *web.EventContext) []*AdvancedFilterField {
+		return []*AdvancedFilterField{{Key: "version", Column: "version", Type: AdvancedFilterFieldTypeString}}
+	}).SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		searched = true
+		return &SearchResult{Nodes: []*foo{}}, nil
+	}).BulkAction("Archive").
+		UpdateFunc(func(selectedIds []string, ctx *web.EventContext, r *web.EventResponse) (err error) {
+			return nil
+		}).
+		ComponentFunc(func(selectedIds []string, ctx *web.EventContext) h.HTMLComponent {
+			return nil
+		})
+	mb.Listing().Export()
+	filterQuery := url.Values{ParamAdvancedFilter: {`{"conditions": [{"field": "secret", "operator": "eq", "value": "x"}]}`}}.Encode()
+
+	// the listing lists nothing rather than all the records
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	c := &ListingCompo{lb: mb.Listing(), FilterQuery: filterQuery, SelectAllMatching: true}
+	params, _, _, filterScript := c.buildSearchParams(evCtx)
+	require.NotNil(t, filterScript)
+	assert.Contains(t, lo.Map(params.SQLConditions, func(cond *SQLCondition, _ int) string { return cond.Query }), "1 = 0")
+
+	r, err := c.DoBulkAction(web.WrapEventContext(context.Background(), evCtx), DoBulkActionRequest{Name: "Archive"})
+	require.NoError(t, err)
+	require.Len(t, r.UpdatePortals, 1)
+	assert.Contains(t, h.MustString(r.UpdatePortals[0].Body, context.Background()), Messages_en_US.AdvancedFilterInvalid)
+
+	searched = false
+	state, _ := json.Marshal(exportState{FilterQuery: filterQuery})
+	w := httptest.NewRecorder()
+	mb.Listing().exporting.ServeHTTP(w, httptest.NewRequest("GET", "/?"+url.Values{ParamExportFormat: {"csv"}, ParamExportState: {string(state)}}.Encode(), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), Messages_en_US.AdvancedFilterInvalid)
+	assert.False(t, searched)
+}
@@ -42,6 +42,49 @@ type DataOperator interface {
 	Delete(obj interface{}, id string, ctx *web.EventContext) (err error)
 }
 
+// Transactor is implemented by data operators that are able to run several data
+// operations atomically, all the operations called with the EventContext passed to f
+// must join the same transaction.
+type Transactor interface {
+	Transaction(ctx *web.EventContext, f func(ctx *web.EventContext) error) error
+}
+
+// Trasher is implemented by data operators that soft delete records, Trashable reports whether the records
+// of the model are soft deleted, FetchTrashed loads the soft deleted record to check the permissions on it,
+// Restore and Purge load it into obj, then restore it or delete it permanently.
+type Trasher interface {
+	Trashable(model interface{}) bool
+	FetchTrashed(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error)
+	Restore(obj interface{}, id string, ctx *web.EventContext) (err error)
+	Purge(obj interface{}, id string, ctx *web.EventContext) (err error)
+}
+
+// UniqueChecker is implemented by data operators that are able to check no other record than obj
+// has the value of the field, including the soft deleted ones, it's used by the unique field validator.
+type UniqueChecker interface {
+	IsUnique(obj interface{}, field string, value interface{}, ctx *web.EventContext) (unique bool, err error)
+}
+
+// AssociationLoader is implemented by data operators that are able to load the many2many associations
+// of the field of obj, it's used by the association picker to show the associated records.
+// The associations set by the pickers are replaced by the saver, see AssociationsToReplace.
+type AssociationLoader interface {
+	LoadAssociation(obj interface{}, field string, ctx *web.EventContext) (err error)
+}
+
+// Aggregator is implemented by data operators that are able to sum the field of the records matching
+// the search params in the data store, it's used by the dashboard widgets.
+type Aggregator interface {
+	Sum(ctx *web.EventContext, params *SearchParams, field string) (sum float64, err error)
+}
+
+// OrphanFinder is implemented by data operators that are able to search the records whose column
+// doesn't reference a record of the model any more, like the records whose parent is deleted,
+// it's used by the tree layout to list them as roots.
+type OrphanFinder interface {
+	OrphanCondition(model interface{}, column string, ctx *web.EventContext) (cond *SQLCondition, err error)
+}
+
 type (
 	SetterFunc         func(obj interface{}, ctx *web.EventContext)
 	FieldSetterFunc    func(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error)
@@ -70,6 +113,10 @@ type (
 		KeywordColumns []string
 		Keyword        string
 		SQLConditions  []*SQLCondition
+		// RankBy orders the records by relevance descending before OrderBys, it is set by the SearchIndex
+		RankBy *SQLCondition
+		// Trashed searches the soft deleted records only
+		Trashed bool
 
 		Page     int64
 		PerPage  int64
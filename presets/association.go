@@ -0,0 +1,239 @@
+package presets
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// AssociationPickerPerPage is the number of the records loaded by a page of the association picker
+const AssociationPickerPerPage = 20
+
+// associationPickerMarker is the prefix of the form key posted with the association picker,
+// so that the associations are only replaced when the picker is in the form even if nothing is picked.
+const associationPickerMarker = "presets_association_picker_"
+
+type ctxKeyAssociationsToReplace struct{}
+
+// AssociationsToReplace returns the fields of the record saved by the request which are set by the association pickers,
+// the saver should replace the associations of them with the records set to the fields in the same transaction.
+func AssociationsToReplace(ctx *web.EventContext) []string {
+	if ctx == nil || ctx.R == nil {
+		return nil
+	}
+	names, _ := ctx.R.Context().Value(ctxKeyAssociationsToReplace{}).([]string)
+	return names
+}
+
+func replaceAssociation(ctx *web.EventContext, name string) {
+	names := AssociationsToReplace(ctx)
+	if slices.Contains(names, name) {
+		return
+	}
+	ctx.WithContextValue(ctxKeyAssociationsToReplace{}, append(slices.Clone(names), name))
+}
+
+// many2manyModelType returns the model type of the field if it's a []*T field of a gorm many2many association
+func many2manyModelType(model interface{}, name string) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	sf, ok := t.FieldByName(name)
+	if !ok || !isMany2ManyField(sf) {
+		return nil
+	}
+	return sf.Type.Elem()
+}
+
+func isMany2ManyField(sf reflect.StructField) bool {
+	if !strings.Contains(sf.Tag.Get("gorm"), "many2many:") {
+		return false
+	}
+	t := sf.Type
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Ptr && t.Elem().Elem().Kind() == reflect.Struct
+}
+
+// modelBuilderOfType returns the model builder of the model type like *T
+func (b *Builder) modelBuilderOfType(t reflect.Type) *ModelBuilder {
+	for _, mb := range b.models {
+		if reflect.TypeOf(mb.model) == t {
+			return mb
+		}
+	}
+	return nil
+}
+
+// associationTarget returns the model builder of the records of the many2many field,
+// it's nil if the model isn't added to the presets, then the field is skipped.
+func associationTarget(obj interface{}, field *FieldContext) *ModelBuilder {
+	if field.ModelInfo == nil {
+		panic(fmt.Sprintf("the association picker of %s must be rendered in the form of a model", field.Name))
+	}
+	t := many2manyModelType(obj, field.Name)
+	if t == nil {
+		panic(fmt.Sprintf("%s isn't a []*T field of a many2many association", field.Name))
+	}
+	return field.ModelInfo.mb.p.modelBuilderOfType(t)
+}
+
+// AssociationPicker renders the many2many field as a searchable picker, the records are searched by the SearchFunc
+// of the model of the field page by page. It's the default component of the []*T fields of the many2many associations.
+func AssociationPicker(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	target := associationTarget(obj, field)
+	if target == nil {
+		return nil
+	}
+	msgr := MustGetMessages(ctx.R)
+
+	rv := reflectValueOf(obj, field.Name)
+	if rv.IsValid() && rv.IsNil() && ObjectID(obj) != "" {
+		if loader, ok := field.ModelInfo.mb.p.dataOperator.(AssociationLoader); ok {
+			if err := loader.LoadAssociation(obj, field.Name, ctx); err != nil {
+				panic(err)
+			}
+			rv = reflectValueOf(obj, field.Name)
+		}
+	}
+
+	selected := []FieldOption{}
+	values := []string{}
+	if rv.IsValid() {
+		for i := 0; i < rv.Len(); i++ {
+			record := rv.Index(i).Interface()
+			id := ObjectID(record)
+			selected = append(selected, FieldOption{Text: globalSearchTitle(target, record, id), Value: id})
+			values = append(values, id)
+		}
+	}
+
+	load := "locals.loading = true;" + web.Plaid().
+		URL(target.Info().ListingHref()).
+		EventFunc(actions.AssociationOptions).
+		Query(ParamAssociationKeyword, web.Var("locals.search")).
+		Query(ParamAssociationPage, web.Var("locals.page")).
+		ThenScript(fmt.Sprintf(`locals.loading = false;
+			const items = (r.data && r.data.items) || [];
+			const picked = [].concat(form[%s] || []);
+			const base = locals.page > 1 ? locals.items : locals.items.filter((i) => picked.includes(i.value));
+			locals.items = base.concat(items.filter((i) => !base.some((b) => b.value === i.value)));
+			locals.hasMore = !!(r.data && r.data.hasMore);`, h.JSONString(field.FormKey))).
+		Go()
+
+	return web.Scope(
+		VAutocomplete(
+			web.Slot(
+				h.Div(
+					VBtn(msgr.AssociationPickerLoadMore).
+						Variant(VariantText).
+						Size(SizeSmall).
+						Attr(":loading", "locals.loading").
+						Attr("@click", "locals.page++;"+load),
+				).Class("text-center").Attr("v-if", "locals.hasMore"),
+			).Name("append-item"),
+		).
+			Label(fieldLabelWithHints(field)).
+			Attr(web.VField(field.FormKey, values)...).
+			Attr(":items", "locals.items").
+			Attr(":loading", "locals.loading").
+			Attr("@update:search", fmt.Sprintf(`(v) => { locals.search = v || ""; clearTimeout(locals.timer); locals.timer = setTimeout(() => { locals.page = 1; %s }, 300); }`, load)).
+			Attr("@update:menu", fmt.Sprintf(`(open) => { if (open && !locals.loaded) { locals.loaded = true; %s } }`, load)).
+			ItemTitle("text").
+			ItemValue("value").
+			Multiple(true).
+			Chips(true).
+			ClosableChips(true).
+			NoFilter(true).
+			ErrorMessages(field.Errors...).
+			Disabled(field.Disabled),
+		h.Input("").Type("hidden").Attr(web.VField(associationPickerMarker+field.FormKey, "1")...),
+	).VSlot("{ locals }").Init(fmt.Sprintf(`{ items: %s, page: 1, search: "", hasMore: false, loading: false, loaded: false }`, h.JSONString(selected)))
+}
+
+// AssociationPickerSetter sets the records picked to the many2many field, the associations of the record
+// are replaced by them when it's saved. The records must be allowed to be listed and read by the current user.
+func AssociationPickerSetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error) {
+	if _, ok := ctx.R.Form[associationPickerMarker+field.FormKey]; !ok {
+		return
+	}
+	target := associationTarget(obj, field)
+	if target == nil {
+		return
+	}
+	verifier := target.Info().Verifier()
+	if verifier.Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+		return perm.PermissionDenied
+	}
+
+	ids := ctx.R.Form[field.FormKey]
+	records := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(target.model)), 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		record, err := target.editing.Fetcher(target.NewModel(), id, ctx)
+		if err != nil {
+			return err
+		}
+		if verifier.Do(PermGet).ObjectOn(record).WithReq(ctx.R).IsAllowed() != nil {
+			return perm.PermissionDenied
+		}
+		records = reflect.Append(records, reflect.ValueOf(record))
+	}
+	if err = reflectutils.Set(obj, field.Name, records.Interface()); err != nil {
+		return
+	}
+	if field.FormKey == field.Name {
+		replaceAssociation(ctx, field.Name)
+	}
+	return
+}
+
+// associationOptions searches the records of the model for the association pickers of the other models
+func (mb *ModelBuilder) associationOptions(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if mb.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+		ShowMessage(&r, perm.PermissionDenied.Error(), "warning")
+		return
+	}
+
+	page, _ := strconv.ParseInt(ctx.R.FormValue(ParamAssociationPage), 10, 64)
+	params := &SearchParams{
+		Model:          mb.NewModel(),
+		PageURL:        ctx.R.URL,
+		KeywordColumns: mb.listing.searchColumns,
+		Keyword:        ctx.R.FormValue(ParamAssociationKeyword),
+		SQLConditions:  mb.listing.conditions,
+		PerPage:        AssociationPickerPerPage,
+		Page:           max(page, 1),
+	}
+	if err = (&ListingCompo{lb: mb.listing}).applySearchIndex(ctx, params); err != nil {
+		return
+	}
+	sr, err := mb.listing.Searcher(ctx, params)
+	if err != nil {
+		return
+	}
+
+	items := []FieldOption{}
+	reflectutils.ForEach(sr.Nodes, func(obj interface{}) {
+		id := ObjectID(obj)
+		items = append(items, FieldOption{Text: globalSearchTitle(mb, obj, id), Value: id})
+	})
+	r.Data = map[string]interface{}{
+		"items":   items,
+		"hasMore": len(items) >= AssociationPickerPerPage,
+	}
+	return
+}
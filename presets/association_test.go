@@ -0,0 +1,155 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type pickerTag struct {
+	ID   uint
+	Name string
+}
+
+func (t *pickerTag) PageTitle() string {
+	return t.Name
+}
+
+type pickerPost struct {
+	ID    uint
+	Title string
+	Tags  []*pickerTag `gorm:"many2many:picker_post_tags"`
+	Notes []*pickerTag
+}
+
+func TestAssociationPicker(t *testing.T) {
+	var tags []*pickerTag
+	for i := 1; i <= AssociationPickerPerPage+5; i++ {
+		tags = append(tags, &pickerTag{ID: uint(i), Name: fmt.Sprintf("tag%d", i)})
+	}
+
+	pb := New()
+	mb := pb.Model(&pickerPost{})
+	tagMB := pb.Model(&pickerTag{})
+	tagMB.Listing().SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		var nodes []*pickerTag
+		for _, tag := range tags {
+			if strings.Contains(tag.Name, params.Keyword) {
+				nodes = append(nodes, tag)
+			}
+		}
+		start := min(int((params.Page-1)*params.PerPage), len(nodes))
+		end := min(start+int(params.PerPage), len(nodes))
+		return &SearchResult{Nodes: nodes[start:end]}, nil
+	})
+	tagMB.Editing().FetchFunc(func(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+		for _, tag := range tags {
+			if fmt.Sprint(tag.ID) == id {
+				return tag, nil
+			}
+		}
+		return nil, ErrRecordNotFound
+	})
+
+	// the []*T fields of the many2many associations are inferred
+	eb := mb.Editing()
+	require.NotNil(t, eb.GetField("Tags"))
+	assert.Nil(t, eb.GetField("Notes"))
+
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	body := h.MustString(eb.ToComponent(mb.Info(), &pickerPost{Tags: []*pickerTag{tags[1]}}, ctx), web.WrapEventContext(context.Background(), ctx))
+	assert.Contains(t, body, "<v-autocomplete")
+	assert.Contains(t, body, `v-assign='[form, {"Tags":["2"]}]'`)
+	assert.Contains(t, body, `{"text":"tag2","value":"2"}`)
+	assert.Contains(t, body, `presets_AssociationOptions`)
+
+	// the records are searched by the SearchFunc of the model of the field page by page
+	ctx = rowContext(ctx, url.Values{ParamAssociationKeyword: {"tag"}, ParamAssociationPage: {"2"}})
+	r, err := tagMB.associationOptions(ctx)
+	require.NoError(t, err)
+	data := r.Data.(map[string]interface{})
+	assert.Len(t, data["items"], 5)
+	assert.Equal(t, false, data["hasMore"])
+
+	// the picked records are set to the field, and marked to replace the associations
+	ctx = rowContext(ctx, url.Values{"Title": {"a"}, "Tags": {"1", "3"}, associationPickerMarker + "Tags": {"1"}})
+	obj := &pickerPost{}
+	vErr := eb.RunSetterFunc(ctx, false, obj)
+	require.False(t, vErr.HaveErrors())
+	require.Len(t, obj.Tags, 2)
+	assert.Equal(t, "tag3", obj.Tags[1].Name)
+	assert.Equal(t, []string{"Tags"}, AssociationsToReplace(ctx))
+
+	ctx = &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx = rowContext(ctx, url.Values{"Title": {"a"}})
+	eb.RunSetterFunc(ctx, false, &pickerPost{})
+	assert.Empty(t, AssociationsToReplace(ctx), "the associations are kept if the picker isn't in the form")
+}
+
+func TestAssociationPickerPermissions(t *testing.T) {
+	tags := []*pickerTag{{ID: 1, Name: "tag1"}, {ID: 2, Name: "tag2"}}
+	pb := New().Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("viewer").WhoAre(perm.Denied).ToDo(PermList).On(":presets:picker_tags:"),
+		perm.PolicyFor("stranger").WhoAre(perm.Denied).ToDo(PermGet).On(":presets:picker_tags:picker_tags:2:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&pickerPost{})
+	pb.Model(&pickerTag{}).Editing().FetchFunc(func(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+		for _, tag := range tags {
+			if fmt.Sprint(tag.ID) == id {
+				return tag, nil
+			}
+		}
+		return nil, ErrRecordNotFound
+	})
+	eb := mb.Editing()
+
+	set := func(role string, ids ...string) (*pickerPost, web.ValidationErrors) {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("role", role)
+		ctx := rowContext(&web.EventContext{R: r, W: httptest.NewRecorder()}, url.Values{"Tags": ids, associationPickerMarker + "Tags": {"1"}})
+		obj := &pickerPost{}
+		return obj, eb.RunSetterFunc(ctx, false, obj)
+	}
+
+	obj, vErr := set("", "1", "2")
+	require.False(t, vErr.HaveErrors())
+	assert.Len(t, obj.Tags, 2)
+
+	_, vErr = set("viewer", "1")
+	assert.Contains(t, vErr.GetFieldErrors("Tags"), perm.PermissionDenied.Error())
+
+	obj, vErr = set("stranger", "1")
+	require.False(t, vErr.HaveErrors())
+	assert.Len(t, obj.Tags, 1)
+	_, vErr = set("stranger", "1", "2")
+	assert.Contains(t, vErr.GetFieldErrors("Tags"), perm.PermissionDenied.Error())
+}
+
+func TestAssociationPickerTargetNotAdded(t *testing.T) {
+	mb := New().Model(&pickerPost{})
+	eb := mb.Editing()
+
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	body := h.MustString(eb.ToComponent(mb.Info(), &pickerPost{}, ctx), web.WrapEventContext(context.Background(), ctx))
+	assert.NotContains(t, body, "<v-autocomplete")
+
+	ctx = rowContext(ctx, url.Values{"Title": {"a"}, associationPickerMarker + "Tags": {"1"}})
+	obj := &pickerPost{}
+	vErr := eb.RunSetterFunc(ctx, false, obj)
+	require.False(t, vErr.HaveErrors())
+	assert.Equal(t, "a", obj.Title)
+	assert.Empty(t, AssociationsToReplace(ctx))
+}
@@ -1,6 +1,10 @@
 package presets
 
-const defaultBulkActionDialogWidth = "600"
+const (
+	defaultBulkActionDialogWidth = "600"
+
+	BulkActionBatchSizeDefault = 500
+)
 
 type BulkActionBuilder struct {
 	NameLabel
@@ -10,9 +14,13 @@ type BulkActionBuilder struct {
 	compFunc                       BulkActionComponentFunc
 	selectedIdsProcessorFunc       BulkActionSelectedIdsProcessorFunc
 	selectedIdsProcessorNoticeFunc BulkActionSelectedIdsProcessorNoticeFunc
+	undoFunc                       BulkActionUndoFunc
 
 	dialogWidth string
 	buttonColor string
+
+	batchSize           int
+	backgroundThreshold int
 }
 
 func getBulkAction(actions []*BulkActionBuilder, name string) *BulkActionBuilder {
@@ -54,6 +62,23 @@ func (b *BulkActionBuilder) SelectedIdsProcessorNoticeFunc(v BulkActionSelectedI
 	return b
 }
 
+// BatchSize is the number of the records the update func is called with at a time
+// when all the matching records are selected.
+func (b *BulkActionBuilder) BatchSize(v int) (r *BulkActionBuilder) {
+	b.batchSize = v
+	return b
+}
+
+// Background runs the action in the background with the progress shown in the dialog
+// when all the matching records are selected and there are more than threshold of them, 0 disables it.
+// The background run is a goroutine of the process and its progress is kept in memory, so it's lost
+// when the process restarts and it can only be followed on the same instance, use the worker package
+// for the actions which must survive restarts or run behind a load balancer without sticky sessions.
+func (b *BulkActionBuilder) Background(threshold int) (r *BulkActionBuilder) {
+	b.backgroundThreshold = threshold
+	return b
+}
+
 func (b *BulkActionBuilder) DialogWidth(v string) (r *BulkActionBuilder) {
 	b.dialogWidth = v
 	return b
@@ -74,6 +99,7 @@ func (b *ListingBuilder) BulkAction(name string) (r *BulkActionBuilder) {
 	r.name = name
 	r.buttonColor = "black"
 	r.dialogWidth = defaultBulkActionDialogWidth
+	r.batchSize = BulkActionBatchSizeDefault
 	b.bulkActions = append(b.bulkActions, r)
 	return
 }
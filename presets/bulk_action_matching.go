@@ -0,0 +1,320 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/samber/lo"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// bulkActionJobKeep is how long a finished background bulk action is kept for its progress
+const bulkActionJobKeep = time.Hour
+
+type ctxKeyBulkActionSearchParams struct{}
+
+// BulkActionSearchParams returns the search params of the listing when all the matching records are selected
+// for the bulk action, the update func is still called with the ids of the records in batches.
+func BulkActionSearchParams(ctx *web.EventContext) *SearchParams {
+	v, _ := ctx.ContextValue(ctxKeyBulkActionSearchParams{}).(*SearchParams)
+	return v
+}
+
+// matchingSearchParams are the search params of the listing without the pagination
+func (c *ListingCompo) matchingSearchParams(evCtx *web.EventContext) *SearchParams {
+	params, _, _, _ := c.buildSearchParams(evCtx)
+	params.RelayPagination = nil
+	params.RelayPaginateRequest = nil
+	params.Page = 1
+	return params
+}
+
+func (c *ListingCompo) countMatching(evCtx *web.EventContext) (int, error) {
+	params := c.matchingSearchParams(evCtx)
+	params.PerPage = 1
+	result, err := c.lb.Searcher(evCtx, params)
+	if err != nil {
+		return 0, err
+	}
+	return result.PageInfo.TotalCount, nil
+}
+
+// matchingIds resolves the ids of all the records matching the listing in batches, they are all resolved
+// before the action runs, so the records which don't match anymore after a batch are not skipped by the paging.
+func (c *ListingCompo) matchingIds(evCtx *web.EventContext, batchSize int) (ids []string, err error) {
+	params := c.matchingSearchParams(evCtx)
+	params.PerPage = int64(min(max(batchSize, 1), PerPageMax))
+	for {
+		result, err := c.lb.Searcher(evCtx, params)
+		if err != nil {
+			return nil, err
+		}
+		var n int
+		reflectutils.ForEach(result.Nodes, func(obj interface{}) {
+			ids = append(ids, ObjectID(obj))
+			n++
+		})
+		if int64(n) < params.PerPage {
+			return ids, nil
+		}
+		params.Page++
+	}
+}
+
+// runBulkAction calls the update func of the bulk action with the ids in batches, the selected ids processor
+// and the undo func are applied to each batch. It stops at the batch which sets the flash.
+func (c *ListingCompo) runBulkAction(evCtx *web.EventContext, bulk *BulkActionBuilder, ids []string, r *web.EventResponse, withUndo bool, progress func(done int)) (undo UndoFunc, err error) {
+	var undos []UndoFunc
+	var done int
+	for _, batch := range lo.Chunk(ids, max(bulk.batchSize, 1)) {
+		actionableIds := batch
+		if bulk.selectedIdsProcessorFunc != nil {
+			if actionableIds, err = bulk.selectedIdsProcessorFunc(batch, evCtx); err != nil {
+				return nil, err
+			}
+		}
+		if len(actionableIds) > 0 {
+			if withUndo && bulk.undoFunc != nil {
+				batchUndo, err := bulk.undoFunc(actionableIds, evCtx)
+				if err != nil {
+					return nil, err
+				}
+				if batchUndo != nil {
+					undos = append(undos, batchUndo)
+				}
+			}
+			if err = bulk.updateFunc(actionableIds, evCtx, r); err != nil {
+				return nil, err
+			}
+			if evCtx.Flash != nil {
+				return nil, nil
+			}
+		}
+		done += len(batch)
+		if progress != nil {
+			progress(done)
+		}
+	}
+	if len(undos) == 0 {
+		return nil, nil
+	}
+	return func(ctx *web.EventContext, r *web.EventResponse) error {
+		for i := len(undos) - 1; i >= 0; i-- {
+			if err := undos[i](ctx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// doMatchingBulkAction runs the bulk action on all the records matching the listing, the first batch always
+// runs in the request so that the form of the action is validated, and the rest of them run in the background
+// if there are more records than the threshold of the action. The background run isn't persisted, see Background.
+func (c *ListingCompo) doMatchingBulkAction(ctx context.Context, bulk *BulkActionBuilder, r *web.EventResponse) (err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if err = c.checkAdvancedFilter(evCtx); err != nil {
+		return err
+	}
+	params := c.matchingSearchParams(evCtx)
+	evCtx.WithContextValue(ctxKeyBulkActionSearchParams{}, params)
+
+	ids, err := c.matchingIds(evCtx, bulk.batchSize)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return errors.New(msgr.BulkActionNoRecordsSelected)
+	}
+
+	if bulk.backgroundThreshold <= 0 || len(ids) <= bulk.backgroundThreshold {
+		undo, err := c.runBulkAction(evCtx, bulk, ids, r, true, nil)
+		if err != nil || evCtx.Flash != nil {
+			return err
+		}
+		c.lb.mb.p.ShowUndoMessage(r, evCtx, msgr.SuccessfullyUpdated, undo)
+		web.AppendRunScripts(r, c.closeActionDialog())
+		return nil
+	}
+
+	n := min(max(bulk.batchSize, 1), len(ids))
+	first, rest := ids[:n], ids[n:]
+	if _, err = c.runBulkAction(evCtx, bulk, first, r, false, nil); err != nil || evCtx.Flash != nil {
+		return err
+	}
+	job := &bulkActionJob{total: len(ids), done: len(first)}
+	token, err := c.lb.mb.p.bulkActionJobs.add(job)
+	if err != nil {
+		return err
+	}
+
+	// the background run outlives the request
+	bgCtx := *evCtx
+	bgCtx.R = evCtx.R.WithContext(context.WithoutCancel(evCtx.R.Context()))
+	go func() {
+		var err error
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("%v", rec)
+			}
+			job.finish(err)
+		}()
+		var bgR web.EventResponse
+		_, err = c.runBulkAction(&bgCtx, bulk, rest, &bgR, false, func(done int) {
+			job.progress(len(first) + done)
+		})
+		if err == nil && bgCtx.Flash != nil {
+			err = errors.New(fmt.Sprint(bgCtx.Flash))
+			if vErr, ok := bgCtx.Flash.(*web.ValidationErrors); ok {
+				err = errors.New(vErr.Error())
+			}
+		}
+	}()
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: c.actionDialogContentPortalName(),
+		Body: c.bulkProgressPanel(ctx, bulk, token, job),
+	})
+	return nil
+}
+
+type BulkActionProgressRequest struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// BulkActionProgress refreshes the progress of the bulk action running in the background
+func (c *ListingCompo) BulkActionProgress(ctx context.Context, req BulkActionProgressRequest) (r web.EventResponse, err error) {
+	_, msgr := c.MustGetEventContext(ctx)
+	bulk, exists := lo.Find(c.lb.bulkActions, func(ba *BulkActionBuilder) bool {
+		return ba.name == req.Name
+	})
+	job := c.lb.mb.p.bulkActionJobs.get(req.Token)
+	if !exists || job == nil {
+		ShowMessage(&r, msgr.BulkActionNotFound, ColorWarning)
+		web.AppendRunScripts(&r, c.closeActionDialog())
+		return r, nil
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: c.actionDialogContentPortalName(),
+		Body: c.bulkProgressPanel(ctx, bulk, req.Token, job),
+	})
+	if _, _, finished, jobErr := job.state(); finished && jobErr == nil {
+		ShowMessage(&r, msgr.SuccessfullyUpdated, "")
+		web.AppendRunScripts(&r, c.closeActionDialog(), stateful.ReloadAction(ctx, c, nil).Go())
+	}
+	return r, nil
+}
+
+// bulkProgressPanel shows the progress of the bulk action running in the background, it's refreshed every second
+// until the action finishes or the dialog is closed.
+func (c *ListingCompo) bulkProgressPanel(ctx context.Context, bulk *BulkActionBuilder, token string, job *bulkActionJob) h.HTMLComponent {
+	_, msgr := c.MustGetEventContext(ctx)
+	done, total, finished, jobErr := job.state()
+
+	var refresh, errCompo h.HTMLComponent
+	if !finished {
+		refresh = h.Div().Attr("v-on-mounted", fmt.Sprintf(`() => { setTimeout(() => { if (locals.dialog) { %s } }, 1000) }`,
+			stateful.PostAction(ctx, c, c.BulkActionProgress, BulkActionProgressRequest{
+				Name:  bulk.name,
+				Token: token,
+			}).Go()))
+	}
+	if jobErr != nil {
+		errCompo = VAlert(h.Text(jobErr.Error())).Border("left").Type("error").Elevation(2).Class("mb-4")
+	}
+
+	return VCard(
+		VCardTitle(
+			h.Text(bulk.NameLabel.label),
+		),
+		VCardText(
+			errCompo,
+			VProgressLinear().ModelValue(100*done/max(total, 1)).Height(8).Color(ColorPrimary).Rounded(true).
+				Indeterminate(false),
+			h.Div(h.Text(msgr.BulkActionProgress(done, total))).Class("text-body-2 mt-2"),
+			h.If(!finished, h.Div(h.Text(msgr.BulkActionRunningInBackground)).Class("text-caption text-grey mt-2")),
+			refresh,
+		),
+		VCardActions(
+			VSpacer(),
+			VBtn(msgr.OK).Variant(VariantFlat).Class("ml-2").Attr("@click", c.closeActionDialog()),
+		),
+	)
+}
+
+type bulkActionJob struct {
+	mu         sync.Mutex
+	total      int
+	done       int
+	finished   bool
+	finishedAt time.Time
+	err        error
+}
+
+func (j *bulkActionJob) progress(done int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done = done
+}
+
+func (j *bulkActionJob) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finished = true
+	j.finishedAt = time.Now()
+	j.err = err
+}
+
+func (j *bulkActionJob) expired() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.finished && time.Since(j.finishedAt) > bulkActionJobKeep
+}
+
+func (j *bulkActionJob) state() (done int, total int, finished bool, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done, j.total, j.finished, j.err
+}
+
+// bulkActionJobs keeps the bulk actions running in the background in memory for their progress,
+// the jobs are local to the process.
+type bulkActionJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*bulkActionJob
+}
+
+func newBulkActionJobs() *bulkActionJobs {
+	return &bulkActionJobs{jobs: make(map[string]*bulkActionJob)}
+}
+
+func (s *bulkActionJobs) add(job *bulkActionJob) (token string, err error) {
+	if token, err = randomToken(); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, j := range s.jobs {
+		if j.expired() {
+			delete(s.jobs, k)
+		}
+	}
+	s.jobs[token] = job
+	return token, nil
+}
+
+func (s *bulkActionJobs) get(token string) *bulkActionJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[token]
+}
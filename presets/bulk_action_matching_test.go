@@ -0,0 +1,130 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+var bulkActionTokenReg = regexp.MustCompile(`"token": "([0-9a-f]+)"`)
+
+func TestSelectAllMatching(t *testing.T) {
+	op := &apiTestOperator{}
+	for i := 1; i <= 7; i++ {
+		r := &foo{Version: fmt.Sprintf("v%d", i)}
+		r.ID = uint(i)
+		op.records = append(op.records, r)
+	}
+	op.records = append(op.records, &foo{Version: "x8"})
+	op.records[7].ID = 8
+
+	pb := New().DataOperator(op)
+	mb := pb.Model(&foo{})
+
+	var mu sync.Mutex
+	var batches [][]string
+	var params *SearchParams
+	bulk := mb.Listing().BulkAction("Archive").BatchSize(3).
+		SelectedIdsProcessorFunc(func(selectedIds []string, ctx *web.EventContext) ([]string, error) {
+			var ids []string
+			for _, id := range selectedIds {
+				num, _, _ := strings.Cut(id, "_")
+				if n, _ := strconv.Atoi(num); n%2 == 1 {
+					ids = append(ids, id)
+				}
+			}
+			return ids, nil
+		}).
+		UpdateFunc(func(selectedIds []string, ctx *web.EventContext, r *web.EventResponse) (err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, selectedIds)
+			params = BulkActionSearchParams(ctx)
+			return nil
+		}).
+		ComponentFunc(func(selectedIds []string, ctx *web.EventContext) h.HTMLComponent {
+			return nil
+		})
+
+	newCompo := func() (*ListingCompo, context.Context) {
+		evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+		c := &ListingCompo{lb: mb.Listing(), Keyword: "v", SelectAllMatching: true}
+		return c, web.WrapEventContext(context.Background(), evCtx)
+	}
+
+	c, ctx := newCompo()
+	evCtx := web.MustGetEventContext(ctx)
+	ids, err := c.matchingIds(evCtx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1_v1", "2_v2", "3_v3", "4_v4", "5_v5", "6_v6", "7_v7"}, ids, "the keyword of the listing is applied")
+
+	_, err = c.DoBulkAction(ctx, DoBulkActionRequest{Name: "Archive"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"1_v1", "3_v3"}, {"5_v5"}, {"7_v7"}}, batches, "the processor is applied to each batch")
+	require.NotNil(t, params)
+	assert.Equal(t, "v", params.Keyword)
+
+	// the first batch runs in the request and the rest of them in the background
+	batches = nil
+	bulk.Background(4)
+	c, ctx = newCompo()
+	r, err := c.DoBulkAction(ctx, DoBulkActionRequest{Name: "Archive"})
+	require.NoError(t, err)
+	require.Len(t, r.UpdatePortals, 1)
+	body := h.MustString(r.UpdatePortals[0].Body, context.Background())
+	m := bulkActionTokenReg.FindStringSubmatch(body)
+	require.NotNil(t, m, body)
+	job := pb.bulkActionJobs.get(m[1])
+	require.NotNil(t, job)
+
+	require.Eventually(t, func() bool {
+		_, _, finished, _ := job.state()
+		return finished
+	}, time.Second, 10*time.Millisecond)
+	done, total, _, jobErr := job.state()
+	assert.NoError(t, jobErr)
+	assert.Equal(t, 7, done)
+	assert.Equal(t, 7, total)
+	mu.Lock()
+	assert.Equal(t, [][]string{{"1_v1", "3_v3"}, {"5_v5"}, {"7_v7"}}, batches)
+	mu.Unlock()
+
+	c, ctx = newCompo()
+	r, err = c.BulkActionProgress(ctx, BulkActionProgressRequest{Name: "Archive", Token: m[1]})
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, Messages_en_US.SuccessfullyUpdated)
+}
+
+func TestSelectAllMatchingCountError(t *testing.T) {
+	mb := New().Model(&foo{})
+	mb.Listing().SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		return nil, errors.New("database is down")
+	}).BulkAction("Archive").
+		UpdateFunc(func(selectedIds []string, ctx *web.EventContext, r *web.EventResponse) (err error) {
+			return nil
+		}).
+		ComponentFunc(func(selectedIds []string, ctx *web.EventContext) h.HTMLComponent {
+			return nil
+		})
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing(), SelectAllMatching: true}
+	r, err := c.OpenBulkActionDialog(ctx, OpenBulkActionDialogRequest{Name: "Archive"})
+	require.NoError(t, err)
+	require.Len(t, r.UpdatePortals, 1)
+	body := h.MustString(r.UpdatePortals[0].Body, ctx)
+	assert.Contains(t, body, "database is down")
+}
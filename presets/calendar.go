@@ -0,0 +1,409 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+	"github.com/theplant/relay"
+)
+
+const (
+	CalendarRangeMonth = "month"
+	CalendarRangeWeek  = "week"
+	CalendarRangeDay   = "day"
+)
+
+const calendarDateLayout = "2006-01-02"
+
+type CalendarBuilder struct {
+	mb           *ModelBuilder
+	startField   string
+	endField     string
+	startColumn  string
+	endColumn    string
+	entryFunc    ObjectComponentFunc
+	defaultRange string
+	weekStart    time.Weekday
+}
+
+// Calendar adds a calendar layout to the listing, the records are placed on the calendar by the time field,
+// and dragging an entry to another day reschedules it with the editing validator and saver.
+func (b *ListingBuilder) Calendar(startField string) (r *CalendarBuilder) {
+	if b.calendar != nil && b.calendar.startField == startField {
+		return b.calendar
+	}
+	b.calendar = &CalendarBuilder{
+		mb:           b.mb,
+		startField:   startField,
+		startColumn:  strcase.ToSnake(startField),
+		defaultRange: CalendarRangeMonth,
+		weekStart:    time.Sunday,
+	}
+	b.calendar.checkField(startField)
+	return b.calendar
+}
+
+// EndField makes the entries span from the start field to the end field, a record without an end lasts for its start time
+func (b *CalendarBuilder) EndField(v string) (r *CalendarBuilder) {
+	b.checkField(v)
+	b.endField = v
+	b.endColumn = strcase.ToSnake(v)
+	return b
+}
+
+// DBColumns sets the columns which are used to search the records in the visible range,
+// default is the snake case of the fields
+func (b *CalendarBuilder) DBColumns(start, end string) (r *CalendarBuilder) {
+	b.startColumn = start
+	b.endColumn = end
+	return b
+}
+
+// EntryFunc renders the content of an entry, the first listing field is rendered by default
+func (b *CalendarBuilder) EntryFunc(v ObjectComponentFunc) (r *CalendarBuilder) {
+	b.entryFunc = v
+	return b
+}
+
+// DefaultRange sets the range shown at first, it's one of CalendarRangeMonth, CalendarRangeWeek and CalendarRangeDay
+func (b *CalendarBuilder) DefaultRange(v string) (r *CalendarBuilder) {
+	b.defaultRange = v
+	return b
+}
+
+func (b *CalendarBuilder) WeekStart(v time.Weekday) (r *CalendarBuilder) {
+	b.weekStart = v
+	return b
+}
+
+func (b *CalendarBuilder) checkField(name string) {
+	t := reflectutils.GetType(b.mb.model, name)
+	if t == nil {
+		panic(fmt.Sprintf("calendar field %s not found in %s", name, b.mb.modelType))
+	}
+	if t != reflect.TypeOf(time.Time{}) && t != reflect.TypeOf(&time.Time{}) {
+		panic(fmt.Sprintf("calendar field %s must be time.Time or *time.Time", name))
+	}
+}
+
+// calendarTime returns the value of the time field, false if it is nil or zero
+func calendarTime(obj any, field string) (time.Time, bool) {
+	v, err := reflectutils.Get(obj, field)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch vv := v.(type) {
+	case time.Time:
+		return vv, !vv.IsZero()
+	case *time.Time:
+		if vv == nil {
+			return time.Time{}, false
+		}
+		return *vv, !vv.IsZero()
+	}
+	return time.Time{}, false
+}
+
+// span returns the start and the end of the entry in local time
+func (b *CalendarBuilder) span(obj any) (start, end time.Time, ok bool) {
+	start, ok = calendarTime(obj, b.startField)
+	if !ok {
+		return
+	}
+	start = start.Local()
+	end = start
+	if b.endField != "" {
+		if v, ok := calendarTime(obj, b.endField); ok && v.After(start) {
+			end = v.Local()
+		}
+	}
+	return
+}
+
+func (b *CalendarBuilder) normalizeRange(v string) string {
+	switch v {
+	case CalendarRangeMonth, CalendarRangeWeek, CalendarRangeDay:
+		return v
+	}
+	if b.defaultRange != "" {
+		return b.defaultRange
+	}
+	return CalendarRangeMonth
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (b *CalendarBuilder) startOfWeek(t time.Time) time.Time {
+	d := startOfDay(t)
+	return d.AddDate(0, 0, -((int(d.Weekday()) - int(b.weekStart) + 7) % 7))
+}
+
+// visibleRange returns the days shown for the range around the date, the end is exclusive.
+// A month is shown in whole weeks.
+func (b *CalendarBuilder) visibleRange(rng string, date time.Time) (start, end time.Time) {
+	switch rng {
+	case CalendarRangeDay:
+		start = startOfDay(date)
+		return start, start.AddDate(0, 0, 1)
+	case CalendarRangeWeek:
+		start = b.startOfWeek(date)
+		return start, start.AddDate(0, 0, 7)
+	}
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	start = b.startOfWeek(first)
+	end = b.startOfWeek(first.AddDate(0, 1, -1)).AddDate(0, 0, 7)
+	return start, end
+}
+
+// rangeConditions selects the records overlapping with the visible range
+func (b *CalendarBuilder) rangeConditions(start, end time.Time) []*SQLCondition {
+	if b.endField == "" {
+		return []*SQLCondition{{
+			Query: fmt.Sprintf("%s >= ? AND %s < ?", b.startColumn, b.startColumn),
+			Args:  []interface{}{start, end},
+		}}
+	}
+	return []*SQLCondition{{
+		Query: fmt.Sprintf("%s < ? AND COALESCE(%s, %s) >= ?", b.startColumn, b.endColumn, b.startColumn),
+		Args:  []interface{}{end, start},
+	}}
+}
+
+// shiftCalendarDate returns the date of the previous or next range
+func shiftCalendarDate(rng string, date time.Time, n int) time.Time {
+	switch rng {
+	case CalendarRangeDay:
+		return date.AddDate(0, 0, n)
+	case CalendarRangeWeek:
+		return date.AddDate(0, 0, 7*n)
+	}
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	return first.AddDate(0, n, 0)
+}
+
+func (c *ListingCompo) calendarDate() time.Time {
+	if d, err := time.ParseInLocation(calendarDateLayout, c.CalendarDate, time.Local); err == nil {
+		return d
+	}
+	return startOfDay(time.Now())
+}
+
+func (c *ListingCompo) calendarMoveAllowed(evCtx *web.EventContext, obj any) bool {
+	cb := c.lb.calendar
+	info := c.lb.mb.Info()
+	for _, field := range []string{cb.startField, cb.endField} {
+		if field == "" {
+			continue
+		}
+		if info.Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn("f_"+field).WithReq(evCtx.R).IsAllowed() != nil ||
+			!info.FieldWritable(evCtx.R, field) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *ListingCompo) calendarView(ctx context.Context, searchParams *SearchParams) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	cb := c.lb.calendar
+	rng := cb.normalizeRange(c.CalendarRange)
+	start, end := cb.visibleRange(rng, c.calendarDate())
+
+	// all the records in the visible range are listed, page by page
+	params := *searchParams
+	params.OrderBys = []relay.OrderBy{{Field: cb.startField}}
+	objs := c.searchAll(evCtx, &params, cb.rangeConditions(start, end)...)
+
+	var days []time.Time
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+
+	columns := 7
+	if rng == CalendarRangeDay {
+		columns = 1
+	}
+	var heads []h.HTMLComponent
+	for _, d := range days[:columns] {
+		heads = append(heads, h.Div(h.Text(d.Format("Mon"))).Class("text-caption text-grey text-center py-1"))
+	}
+	var cells []h.HTMLComponent
+	for _, d := range days {
+		cells = append(cells, c.calendarDay(ctx, rng, d, objs))
+	}
+
+	return h.Div(
+		c.calendarToolbar(ctx, rng, start, end),
+		h.Div(heads...).Style(fmt.Sprintf("display: grid; grid-template-columns: repeat(%d, 1fr);", columns)),
+		h.Div(cells...).Class("presets-calendar").
+			Style(fmt.Sprintf("display: grid; grid-template-columns: repeat(%d, minmax(0, 1fr)); gap: 1px;", columns)),
+	)
+}
+
+func (c *ListingCompo) calendarToolbar(ctx context.Context, rng string, start, end time.Time) h.HTMLComponent {
+	_, msgr := c.MustGetEventContext(ctx)
+	date := c.calendarDate()
+
+	var title string
+	switch rng {
+	case CalendarRangeDay:
+		title = date.Format("Monday, January 2, 2006")
+	case CalendarRangeWeek:
+		title = fmt.Sprintf("%s - %s", start.Format("Jan 2"), end.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+	default:
+		title = date.Format("January 2006")
+	}
+
+	navigate := func(rng string, date time.Time) string {
+		return stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+			target.CalendarRange = rng
+			target.CalendarDate = date.Format(calendarDateLayout)
+		}).Go()
+	}
+
+	var rangeBtns []h.HTMLComponent
+	for _, v := range []struct{ rng, label string }{
+		{CalendarRangeMonth, msgr.ListingCalendarMonth},
+		{CalendarRangeWeek, msgr.ListingCalendarWeek},
+		{CalendarRangeDay, msgr.ListingCalendarDay},
+	} {
+		rangeBtns = append(rangeBtns, VBtn(v.label).Size(SizeSmall).Value(v.rng).Attr("@click", navigate(v.rng, date)))
+	}
+
+	return h.Div(
+		VBtn("").Icon("mdi-chevron-left").Size(SizeSmall).Variant(VariantText).
+			Attr("@click", navigate(rng, shiftCalendarDate(rng, date, -1))),
+		VBtn("").Icon("mdi-chevron-right").Size(SizeSmall).Variant(VariantText).
+			Attr("@click", navigate(rng, shiftCalendarDate(rng, date, 1))),
+		VBtn(msgr.ListingCalendarToday).Size(SizeSmall).Variant(VariantOutlined).Class("ml-1").
+			Attr("@click", navigate(rng, startOfDay(time.Now()))),
+		h.Div(h.Text(title)).Class("text-h6 ml-4"),
+		VSpacer(),
+		VBtnToggle(rangeBtns...).ModelValue(rng).Mandatory(true).Density(DensityCompact).
+			Variant(VariantOutlined).Divided(true),
+	).Class("d-flex align-center mb-2")
+}
+
+func (c *ListingCompo) calendarDay(ctx context.Context, rng string, day time.Time, objs []any) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	cb := c.lb.calendar
+	dayEnd := day.AddDate(0, 0, 1)
+	date := c.calendarDate()
+
+	var entries []h.HTMLComponent
+	for _, obj := range objs {
+		start, end, ok := cb.span(obj)
+		if !ok || !start.Before(dayEnd) || (start.Before(day) && !end.After(day)) {
+			continue
+		}
+		entries = append(entries, c.calendarEntry(evCtx, rng, obj, start))
+	}
+
+	minHeight := "110px"
+	if rng != CalendarRangeMonth {
+		minHeight = "400px"
+	}
+	label := h.Div(h.Text(fmt.Sprint(day.Day()))).Class("text-caption mb-1")
+	if day.Equal(startOfDay(time.Now())) {
+		label.Class("font-weight-bold text-primary")
+	}
+	cell := h.Div(label, h.Div(entries...)).Class("pa-1").
+		Style(fmt.Sprintf("min-height: %s; outline: 1px solid rgba(0, 0, 0, 0.08);", minHeight)).
+		Attr("@dragover.prevent", true).
+		Attr("@drop.prevent", fmt.Sprintf(`const id = $event.dataTransfer.getData("text/plain"); if (id) { %s }`,
+			stateful.PostAction(ctx, c, c.RescheduleCalendarEntry, CalendarRescheduleRequest{Date: day.Format(calendarDateLayout)},
+				stateful.WithAppendFix(`v.request.id = id;`),
+			).Go(),
+		))
+	if rng == CalendarRangeMonth && day.Month() != date.Month() {
+		cell.Class("bg-grey-lighten-4")
+	}
+	return cell
+}
+
+func (c *ListingCompo) calendarEntry(evCtx *web.EventContext, rng string, obj any, start time.Time) h.HTMLComponent {
+	cb := c.lb.calendar
+	id := ObjectID(obj)
+
+	var body h.HTMLComponent
+	if cb.entryFunc != nil {
+		body = cb.entryFunc(obj, evCtx)
+	} else {
+		body = c.recordSummary(evCtx, obj, cb.startField, cb.endField)
+	}
+	var startTime h.HTMLComponent
+	if rng != CalendarRangeMonth {
+		startTime = h.Span(start.Format("15:04")).Class("mr-1 font-weight-bold")
+	}
+
+	entry := h.Div(startTime, body).Class("presets-calendar-entry d-flex text-caption text-truncate rounded px-1 mb-1 bg-blue-lighten-5 cursor-pointer").
+		Attr("@click.stop", c.openRecordEvent(id))
+	if c.calendarMoveAllowed(evCtx, obj) {
+		entry.Attr("draggable", "true").
+			Attr("@dragstart", fmt.Sprintf(`$event.dataTransfer.setData("text/plain", %s)`, h.JSONString(id)))
+	}
+	return entry
+}
+
+type CalendarRescheduleRequest struct {
+	ID   string `json:"id"`
+	Date string `json:"date"`
+}
+
+// RescheduleCalendarEntry moves the record to the date keeping its time of day and duration
+func (c *ListingCompo) RescheduleCalendarEntry(ctx context.Context, req CalendarRescheduleRequest) (r web.EventResponse, err error) {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	cb := c.lb.calendar
+	if cb == nil {
+		return r, errors.New("calendar is not enabled")
+	}
+	date, err := time.ParseInLocation(calendarDateLayout, req.Date, time.Local)
+	if err != nil {
+		return r, err
+	}
+
+	obj, err := c.lb.mb.editing.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		return r, err
+	}
+	if !c.calendarMoveAllowed(evCtx, obj) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+
+	start, ok := calendarTime(obj, cb.startField)
+	if !ok {
+		return r, nil
+	}
+	start = start.Local()
+	moved := time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), time.Local)
+	delta := moved.Sub(start)
+	if delta == 0 {
+		return r, nil
+	}
+	if err := reflectutils.Set(obj, cb.startField, moved); err != nil {
+		return r, err
+	}
+	if cb.endField != "" {
+		if end, ok := calendarTime(obj, cb.endField); ok {
+			if err := reflectutils.Set(obj, cb.endField, end.Add(delta)); err != nil {
+				return r, err
+			}
+		}
+	}
+
+	c.saveMovedRecord(&r, evCtx, obj, req.ID)
+	return r, nil
+}
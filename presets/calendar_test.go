@@ -0,0 +1,90 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type calendarEvent struct {
+	ID      uint
+	Title   string
+	StartAt time.Time
+	EndAt   time.Time
+}
+
+func TestCalendarVisibleRange(t *testing.T) {
+	cb := New().Model(&calendarEvent{}).Listing().Calendar("StartAt")
+	date := time.Date(2024, 10, 16, 15, 4, 0, 0, time.Local)
+
+	start, end := cb.visibleRange(CalendarRangeMonth, date)
+	assert.Equal(t, time.Date(2024, 9, 29, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2024, 11, 3, 0, 0, 0, 0, time.Local), end)
+
+	start, end = cb.visibleRange(CalendarRangeWeek, date)
+	assert.Equal(t, time.Date(2024, 10, 13, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2024, 10, 20, 0, 0, 0, 0, time.Local), end)
+
+	cb.WeekStart(time.Monday)
+	start, _ = cb.visibleRange(CalendarRangeWeek, date)
+	assert.Equal(t, time.Date(2024, 10, 14, 0, 0, 0, 0, time.Local), start)
+
+	start, end = cb.visibleRange(CalendarRangeDay, date)
+	assert.Equal(t, time.Date(2024, 10, 16, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2024, 10, 17, 0, 0, 0, 0, time.Local), end)
+
+	conds := cb.rangeConditions(start, end)
+	assert.Equal(t, "start_at >= ? AND start_at < ?", conds[0].Query)
+	cb.EndField("EndAt")
+	conds = cb.rangeConditions(start, end)
+	assert.Equal(t, "start_at < ? AND COALESCE(end_at, start_at) >= ?", conds[0].Query)
+	assert.Equal(t, []interface{}{end, start}, conds[0].Args)
+}
+
+func TestRescheduleCalendarEntry(t *testing.T) {
+	op := &memoryTestOperator[calendarEvent]{records: []*calendarEvent{{
+		ID:      1,
+		Title:   "Conference",
+		StartAt: time.Date(2024, 10, 16, 9, 30, 0, 0, time.Local),
+		EndAt:   time.Date(2024, 10, 17, 18, 0, 0, 0, time.Local),
+	}}}
+	mb := New().DataOperator(op).Model(&calendarEvent{})
+	mb.Listing().Calendar("StartAt").EndField("EndAt")
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing()}
+
+	_, err := c.RescheduleCalendarEntry(ctx, CalendarRescheduleRequest{ID: "1", Date: "2024-10-20"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 20, 9, 30, 0, 0, time.Local), op.records[0].StartAt)
+	assert.Equal(t, time.Date(2024, 10, 21, 18, 0, 0, 0, time.Local), op.records[0].EndAt)
+}
+
+func TestCalendarViewAllEntries(t *testing.T) {
+	op := &memoryTestOperator[calendarEvent]{}
+	// more entries than a page of the searcher
+	for i := 1; i <= PerPageMax+5; i++ {
+		op.records = append(op.records, &calendarEvent{
+			ID:      uint(i),
+			Title:   fmt.Sprintf("entry %d", i),
+			StartAt: time.Date(2024, 10, 16, 9, 0, 0, 0, time.Local),
+		})
+	}
+	mb := New().DataOperator(op).Model(&calendarEvent{})
+	mb.Listing("Title").Calendar("StartAt")
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing(), Layout: ListingLayoutCalendar, CalendarDate: "2024-10-16"}
+
+	body := h.MustString(c.dataTable(ctx), ctx)
+	assert.Contains(t, body, fmt.Sprintf("entry %d<", PerPageMax+5))
+}
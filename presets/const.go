@@ -12,10 +12,16 @@ const (
 	PermActions         = "presets:actions:*"
 	PermDoListingAction = "presets:do_listing_action:*"
 	PermBulkActions     = "presets:bulk_actions:*"
+	PermExport          = "presets:export"
+	PermImport          = "presets:import"
+	PermRestore         = "presets:restore"
+	PermPurge           = "presets:purge"
 
 	permActions         = "actions"
 	permDoListingAction = "do_listing_action"
 	permBulkActions     = "bulk_actions"
+	// the field resources are presets:fields:<model>:<field>, PermGet is required to read the field and PermUpdate to write it
+	permFields = "fields"
 )
 
 var PermRead = []string{PermList, PermGet}
@@ -29,6 +35,11 @@ const (
 	ParamOverlayUpdateID          = "overlay_update_id"
 	ParamAfterDeleteEvent         = "presets_after_delete_event"
 	ParamPortalName               = "portal_name"
+	ParamDependentFieldFormKey    = "presets_dependent_field_form_key"
+	ParamWizardStep               = "presets_wizard_step"
+	ParamWizardTo                 = "presets_wizard_to"
+	ParamAssociationKeyword       = "presets_association_keyword"
+	ParamAssociationPage          = "presets_association_page"
 
 	VarsPresetsDataChanged = "presetsDataChanged"
 
@@ -0,0 +1,252 @@
+package presets
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/samber/lo"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// FieldOption is an option of the select rendered by OptionsFrom
+type FieldOption struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// FieldOptionsFunc returns the options of the field, obj has the values of the form being edited
+type FieldOptionsFunc func(obj interface{}, field *FieldContext, ctx *web.EventContext) []FieldOption
+
+// fieldCondition matches if the value of the field is one of the values, or isn't empty if there are no values
+type fieldCondition struct {
+	field  string
+	values []string
+}
+
+func (c *fieldCondition) match(obj interface{}) bool {
+	rv := reflectValueOf(obj, c.field)
+	if len(c.values) == 0 {
+		return !isEmptyValue(rv)
+	}
+	return slices.Contains(c.values, reflectValueString(rv))
+}
+
+// jsExpr checks the condition with the values of the form in the browser,
+// the value of obj is used if the field it depends on isn't in the form
+func (c *fieldCondition) jsExpr(obj interface{}, parentFormKey string) string {
+	value := fmt.Sprintf("String(form[%s] ?? %s)",
+		h.JSONString(joinFormKey(parentFormKey, c.field)),
+		h.JSONString(reflectValueString(reflectValueOf(obj, c.field))))
+	if len(c.values) == 0 {
+		return fmt.Sprintf(`!["", "0", "false"].includes(%s)`, value)
+	}
+	return fmt.Sprintf("%s.includes(%s)", h.JSONString(c.values), value)
+}
+
+// VisibleWhen shows the field only when the value of the field named field in the same form is one of the values,
+// or isn't empty if there are no values. The hidden field isn't set from the form or validated.
+func (b *FieldBuilder) VisibleWhen(field string, values ...string) (r *FieldBuilder) {
+	b.visibleWhen = &fieldCondition{field: field, values: values}
+	return b
+}
+
+// RequiredWhen requires the field only when the value of the field named field matches like VisibleWhen,
+// the field is rendered again when the value changes so that its required marker is updated.
+func (b *FieldBuilder) RequiredWhen(field string, values ...string) (r *FieldBuilder) {
+	b.requiredWhen = &fieldCondition{field: field, values: values}
+	b.reloadWhenChanged(field)
+	return b
+}
+
+// OptionsFrom renders the field as a select with the options returned by f,
+// they are loaded again when the field named field in the same form changes.
+func (b *FieldBuilder) OptionsFrom(field string, f FieldOptionsFunc) (r *FieldBuilder) {
+	b.reloadWhenChanged(field)
+	b.ComponentFunc(func(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+		return SelectField(obj, field, ctx).
+			Items(f(obj, field, ctx)).
+			ItemTitle("text").
+			ItemValue("value").
+			Disabled(field.Disabled)
+	})
+	return b
+}
+
+func (b *FieldBuilder) reloadWhenChanged(field string) {
+	if !slices.Contains(b.reloadOn, field) {
+		b.reloadOn = append(b.reloadOn, field)
+	}
+}
+
+// activeValidators are the validators of the field, with the required one if RequiredWhen matches obj
+func (b *FieldBuilder) activeValidators(obj interface{}) []*FieldValidator {
+	if b.requiredWhen == nil || !b.requiredWhen.match(obj) {
+		return b.validators
+	}
+	return append([]*FieldValidator{Required()}, b.validators...)
+}
+
+type ctxKeyDependentFieldEvent struct{}
+
+// withDependentFieldEvent sets the event the fields of the form rendered for the request are reloaded with,
+// it's the one posted to the editing form or the section the fields are in.
+func withDependentFieldEvent(ctx *web.EventContext, event func() *web.VueEventTagBuilder) {
+	ctx.WithContextValue(ctxKeyDependentFieldEvent{}, event)
+}
+
+func dependentFieldPortalName(formKey string) string {
+	return fmt.Sprintf("presets_DependentFieldPortal_%s", formKey)
+}
+
+// withDependencies hides comp by the VisibleWhen condition in the browser, and makes it reloaded when
+// the fields it depends on change
+func (b *FieldBuilder) withDependencies(obj interface{}, field *FieldContext, parentFormKey string, comp h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	if comp == nil {
+		return nil
+	}
+	if event, ok := ctx.ContextValue(ctxKeyDependentFieldEvent{}).(func() *web.VueEventTagBuilder); ok && len(b.reloadOn) > 0 {
+		keys := lo.Map(b.reloadOn, func(name string, _ int) string {
+			return fmt.Sprintf("form[%s]", h.JSONString(joinFormKey(parentFormKey, name)))
+		})
+		comp = h.Div(
+			web.Portal(comp).Name(dependentFieldPortalName(field.FormKey)),
+			h.Div().Style("display: none;").Attr("v-on-mounted", fmt.Sprintf(`({watch}) => {
+				let timer;
+				watch(() => [%s], () => {
+					clearTimeout(timer);
+					timer = setTimeout(() => { %s }, 300);
+				});
+			}`,
+				strings.Join(keys, ", "),
+				event().EventFunc(actions.ReloadDependentField).Query(ParamDependentFieldFormKey, field.FormKey).Go(),
+			)),
+		)
+	}
+	if b.visibleWhen != nil {
+		comp = h.Div(comp).Attr("v-show", b.visibleWhen.jsExpr(obj, parentFormKey))
+	}
+	return comp
+}
+
+// reloadDependentField renders the field again with the values of the form, in the editing form or in a section
+func (mb *ModelBuilder) reloadDependentField(ctx *web.EventContext) (r web.EventResponse, err error) {
+	id := ctx.R.FormValue(ParamID)
+	formKey := ctx.R.FormValue(ParamDependentFieldFormKey)
+	section := ctx.R.FormValue(SectionFieldName)
+	if mb.formPermission(ctx, id) != nil {
+		ShowMessage(&r, perm.PermissionDenied.Error(), "warning")
+		return
+	}
+
+	var (
+		fb        *FieldsBuilder
+		obj       interface{}
+		parentKey string
+	)
+	if section != "" {
+		f := mb.detailing.Section(section)
+		if obj, err = mb.detailing.GetFetchFunc()(mb.NewModel(), id, ctx); err != nil {
+			return
+		}
+		if f.setter != nil {
+			f.setter(obj, ctx)
+		}
+		if err = f.unmarshalFunc(ctx, obj); err != nil {
+			return
+		}
+		fb, parentKey = &f.editingFB, section
+	} else {
+		usingB := mb.editing
+		if mb.creating != nil && id == "" {
+			usingB = mb.creating
+		}
+		obj, _ = usingB.FetchAndUnmarshal(id, false, ctx)
+		fb = &usingB.FieldsBuilder
+	}
+
+	fb, obj, parentKey, name, ok := fb.lookupFormKey(obj, parentKey, formKey)
+	if !ok {
+		return r, fmt.Errorf("field %q not found", formKey)
+	}
+	f, field := fb.fieldContext(mb.Info(), obj, parentKey, ctx, name, id != "", &web.ValidationErrors{})
+	if field == nil {
+		return
+	}
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: dependentFieldPortalName(formKey),
+		Body: f.lazyCompFunc()(obj, field, ctx),
+	})
+	return
+}
+
+// lookupFormKey finds the fields builder of the field of the form key and the object holding it,
+// through the nested fields and the list fields.
+func (b *FieldsBuilder) lookupFormKey(obj interface{}, parentKey string, formKey string) (fb *FieldsBuilder, holder interface{}, holderKey string, name string, ok bool) {
+	rel := formKey
+	if parentKey != "" {
+		if rel, ok = strings.CutPrefix(formKey, parentKey+"."); !ok {
+			return
+		}
+	}
+	fb, holder, holderKey = b, obj, parentKey
+	segs := strings.Split(rel, ".")
+	for _, seg := range segs[:len(segs)-1] {
+		fieldName, _, _ := strings.Cut(seg, "[")
+		f := fb.GetField(fieldName)
+		if f == nil || f.nestedFieldsBuilder == nil {
+			return nil, nil, "", "", false
+		}
+		child, err := reflectutils.Get(holder, seg)
+		if err != nil {
+			return nil, nil, "", "", false
+		}
+		if rv := reflect.ValueOf(child); !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+			t := reflectutils.GetType(holder, seg)
+			if t == nil || t.Kind() != reflect.Ptr {
+				return nil, nil, "", "", false
+			}
+			child = reflect.New(t.Elem()).Interface()
+		}
+		fb, holder, holderKey = f.nestedFieldsBuilder, child, joinFormKey(holderKey, seg)
+	}
+	name = segs[len(segs)-1]
+	if fb.GetField(name) == nil {
+		return nil, nil, "", "", false
+	}
+	return fb, holder, holderKey, name, true
+}
+
+func joinFormKey(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return fmt.Sprintf("%s.%s", parent, name)
+}
+
+func reflectValueOf(obj interface{}, name string) reflect.Value {
+	val, err := reflectutils.Get(obj, name)
+	if err != nil {
+		return reflect.Value{}
+	}
+	rv := reflect.ValueOf(val)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func reflectValueString(rv reflect.Value) string {
+	if !rv.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(rv.Interface())
+}
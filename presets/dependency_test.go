@@ -0,0 +1,99 @@
+package presets
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type dependentAddress struct {
+	Country string
+	City    string
+}
+
+type dependentOrder struct {
+	ID           uint
+	Type         string
+	DiscountCode string `presets_validate:"required"`
+	GiftMessage  string
+	Address      *dependentAddress
+}
+
+func TestFieldDependencies(t *testing.T) {
+	pb := New()
+	mb := pb.Model(&dependentOrder{})
+	addressFB := pb.NewFieldsBuilder(WRITE).Model(&dependentAddress{}).Only("Country", "City")
+	addressFB.Field("City").OptionsFrom("Country", func(obj interface{}, field *FieldContext, ctx *web.EventContext) []FieldOption {
+		if obj.(*dependentAddress).Country == "jp" {
+			return []FieldOption{{Text: "Tokyo", Value: "tokyo"}, {Text: "Osaka", Value: "osaka"}}
+		}
+		return []FieldOption{{Text: "Beijing", Value: "beijing"}}
+	})
+	eb := mb.Editing("Type", "DiscountCode", "GiftMessage", "Address")
+	eb.Field("DiscountCode").VisibleWhen("Type", "coupon")
+	eb.Field("GiftMessage").RequiredWhen("Type", "gift")
+	eb.Field("Address").Nested(addressFB)
+
+	newCtx := func(values url.Values) *web.EventContext {
+		base := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+		return rowContext(base, values)
+	}
+
+	// the hidden field isn't set or validated
+	ctx := newCtx(url.Values{"Type": {"normal"}, "DiscountCode": {"CRAFTED"}})
+	obj := &dependentOrder{DiscountCode: "OLD"}
+	vErr := eb.RunSetterFunc(ctx, false, obj)
+	require.False(t, vErr.HaveErrors())
+	assert.Equal(t, "OLD", obj.DiscountCode)
+	obj.DiscountCode = ""
+	vErr = eb.Validate(obj, ctx)
+	assert.False(t, vErr.HaveErrors(), "%v", vErr.Error())
+
+	// the conditions are checked with the new values of the form
+	ctx = newCtx(url.Values{"DiscountCode": {"SAVE10"}, "Type": {"coupon"}})
+	obj = &dependentOrder{}
+	eb.RunSetterFunc(ctx, false, obj)
+	assert.Equal(t, "SAVE10", obj.DiscountCode)
+
+	obj = &dependentOrder{Type: "gift"}
+	vErr = eb.Validate(obj, ctx)
+	assert.Equal(t, []string{Messages_en_US.ValidationRequired}, vErr.GetFieldErrors("GiftMessage"))
+	assert.Empty(t, vErr.GetFieldErrors("DiscountCode"))
+
+	// the visibility is toggled in the browser, and the dependent fields are reloaded by the event of the form
+	ctx = newCtx(nil)
+	withDependentFieldEvent(ctx, func() *web.VueEventTagBuilder {
+		return web.Plaid().URL(mb.Info().ListingHref())
+	})
+	body := h.MustString(eb.ToComponent(mb.Info(), &dependentOrder{Type: "gift", Address: &dependentAddress{}}, ctx), web.WrapEventContext(context.Background(), ctx))
+	assert.Contains(t, body, `v-show='["coupon"].includes(String(form["Type"] ?? "gift"))'`)
+	assert.Contains(t, body, dependentFieldPortalName("GiftMessage"))
+	assert.Contains(t, body, dependentFieldPortalName("Address.City"))
+	assert.Contains(t, body, `watch(() => [form["Address.Country"]]`)
+	assert.Contains(t, body, "Gift Message *", "the required marker is shown if the condition matches")
+	assert.Contains(t, body, "Beijing")
+
+	// the options are loaded with the values of the form
+	ctx = newCtx(url.Values{
+		ParamDependentFieldFormKey: {"Address.City"},
+		"Type":                     {"normal"},
+		"Address.Country":          {"jp"},
+	})
+	r, err := mb.reloadDependentField(ctx)
+	require.NoError(t, err)
+	require.Len(t, r.UpdatePortals, 1)
+	assert.Equal(t, dependentFieldPortalName("Address.City"), r.UpdatePortals[0].Name)
+	body = h.MustString(r.UpdatePortals[0].Body, web.WrapEventContext(context.Background(), ctx))
+	assert.Contains(t, body, "Tokyo")
+	assert.NotContains(t, body, "Beijing")
+
+	ctx = newCtx(url.Values{ParamDependentFieldFormKey: {"Address.Missing"}})
+	_, err = mb.reloadDependentField(ctx)
+	assert.Error(t, err)
+}
@@ -438,6 +438,7 @@ func (b *DetailingBuilder) EditDetailField(ctx *web.EventContext) (r web.EventRe
 		return
 	}
 
+	offerDraft(ctx)
 	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
 		Name: f.FieldPortalName(),
 		Body: f.editComponent(obj, &FieldContext{
@@ -487,6 +488,13 @@ func (b *DetailingBuilder) SaveDetailField(ctx *web.EventContext) (r web.EventRe
 		return
 	}
 
+	if err = b.mb.checkVersion(ctx, obj); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			err = b.sectionVersionConflict(ctx, &r, f, field, id)
+		}
+		return
+	}
+
 	if err = f.unmarshalFunc(ctx, obj); err != nil {
 		ShowMessage(&r, err.Error(), "warning")
 		return r, nil
@@ -503,10 +511,15 @@ func (b *DetailingBuilder) SaveDetailField(ctx *web.EventContext) (r web.EventRe
 
 	if needSave {
 		err = f.saver(obj, id, ctx)
+		if errors.Is(err, ErrVersionConflict) {
+			err = b.sectionVersionConflict(ctx, &r, f, field, id)
+			return
+		}
 		if err != nil {
 			ShowMessage(&r, err.Error(), "warning")
 			return r, nil
 		}
+		b.mb.discardDraft(ctx, &r, id, f.name)
 	}
 
 	if _, ok := ctx.Flash.(*web.ValidationErrors); ok {
@@ -529,6 +542,39 @@ func (b *DetailingBuilder) SaveDetailField(ctx *web.EventContext) (r web.EventRe
 	return r, nil
 }
 
+// sectionVersionConflict keeps the section in editing with the user's changes on top of the latest version,
+// cancelling the section discards the changes.
+func (b *DetailingBuilder) sectionVersionConflict(ctx *web.EventContext, r *web.EventResponse, f *SectionBuilder, field *FieldContext, id string) error {
+	latest, err := b.GetFetchFunc()(b.mb.NewModel(), id, ctx)
+	if err != nil {
+		return err
+	}
+	yours, err := b.GetFetchFunc()(b.mb.NewModel(), id, ctx)
+	if err != nil {
+		return err
+	}
+	if f.setter != nil {
+		f.setter(yours, ctx)
+	}
+	if err = f.unmarshalFunc(ctx, yours); err != nil {
+		ShowMessage(r, err.Error(), "warning")
+		return nil
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: f.FieldPortalName(),
+		Body: f.editComponent(yours, field, ctx),
+	})
+	b.mb.versionConflictDialog(ctx, r, latest, yours, web.Plaid().
+		URL(ctx.R.URL.Path).
+		EventFunc(actions.DoSaveDetailingField).
+		Query(SectionFieldName, f.name).
+		Query(ParamID, id).
+		Query(SectionIsCancel, true).
+		Go())
+	return nil
+}
+
 // EditDetailListField Event: click detail list field element edit button
 func (b *DetailingBuilder) EditDetailListField(ctx *web.EventContext) (r web.EventResponse, err error) {
 	var (
@@ -0,0 +1,275 @@
+package presets
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+// DraftDebounce is how long the form must be idle before its draft is saved
+const DraftDebounce = 2 * time.Second
+
+// DraftKey identifies the draft of a user for the editing form of a record, or for one of its sections,
+// RecordID is empty for the creating form and Section is empty for the editing form.
+type DraftKey struct {
+	User     string
+	Model    string
+	RecordID string
+	Section  string
+}
+
+type Draft struct {
+	Values  url.Values
+	SavedAt time.Time
+}
+
+// DraftStore persists the unsaved form values.
+type DraftStore interface {
+	// Get returns nil if there is no draft.
+	Get(ctx *web.EventContext, key DraftKey) (*Draft, error)
+	Save(ctx *web.EventContext, key DraftKey, values url.Values) error
+	Delete(ctx *web.EventContext, key DraftKey) error
+}
+
+// DraftUserFunc returns the user the drafts of the request belong to, the drafts are disabled if it's empty.
+type DraftUserFunc func(r *http.Request) string
+
+// Drafts autosaves the unsaved values of the editing form and the detailing sections of the model to the store,
+// reopening the record offers to restore them, and they are discarded once the record is saved.
+func (b *EditingBuilder) Drafts(store DraftStore, userFunc DraftUserFunc) (r *EditingBuilder) {
+	b.draftStore = store
+	b.draftUserFunc = userFunc
+	return b
+}
+
+// DraftSensitiveFields keeps the values of the fields, like the passwords, out of the drafts
+func (b *EditingBuilder) DraftSensitiveFields(names ...string) (r *EditingBuilder) {
+	b.draftSensitiveFields = append(b.draftSensitiveFields, names...)
+	return b
+}
+
+type ctxKeyOfferDraft struct{}
+
+// offerDraft makes the forms rendered for the request offer to restore the drafts
+func offerDraft(ctx *web.EventContext) {
+	ctx.WithContextValue(ctxKeyOfferDraft{}, true)
+}
+
+func (mb *ModelBuilder) draftKey(ctx *web.EventContext, id string, section string) (key DraftKey, ok bool) {
+	eb := mb.editing
+	if eb.draftStore == nil || eb.draftUserFunc == nil {
+		return
+	}
+	user := eb.draftUserFunc(ctx.R)
+	if user == "" {
+		return
+	}
+	return DraftKey{
+		User:     user,
+		Model:    mb.Info().URIName(),
+		RecordID: id,
+		Section:  section,
+	}, true
+}
+
+func draftTimerKey(key DraftKey) string {
+	return fmt.Sprintf("%s_%s_%s", key.Model, key.RecordID, key.Section)
+}
+
+// draftAutosaveScript saves the form as the draft once it's idle for DraftDebounce
+func (mb *ModelBuilder) draftAutosaveScript(ctx *web.EventContext, id string, section string, event func() *web.VueEventTagBuilder) string {
+	key, ok := mb.draftKey(ctx, id, section)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(`vars.presetsDraftTimers = vars.presetsDraftTimers || {}; clearTimeout(vars.presetsDraftTimers[%q]); vars.presetsDraftTimers[%q] = setTimeout(function(){ %s }, %d);`,
+		draftTimerKey(key), draftTimerKey(key), event().EventFunc(actions.SaveDraft).Go(), DraftDebounce.Milliseconds())
+}
+
+// draftClearTimerScript stops the pending autosave of the form
+func (mb *ModelBuilder) draftClearTimerScript(ctx *web.EventContext, id string, section string) string {
+	key, ok := mb.draftKey(ctx, id, section)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(`if (vars.presetsDraftTimers) { clearTimeout(vars.presetsDraftTimers[%q]) };`, draftTimerKey(key))
+}
+
+// draftOffer shows the alert which restores or discards the draft of the form if there is one
+func (mb *ModelBuilder) draftOffer(ctx *web.EventContext, id string, section string, event func() *web.VueEventTagBuilder) h.HTMLComponent {
+	if offer, _ := ctx.ContextValue(ctxKeyOfferDraft{}).(bool); !offer {
+		return nil
+	}
+	key, ok := mb.draftKey(ctx, id, section)
+	if !ok {
+		return nil
+	}
+	draft, err := mb.editing.draftStore.Get(ctx, key)
+	if err != nil || draft == nil {
+		return nil
+	}
+
+	msgr := MustGetMessages(ctx.R)
+	return web.Scope(
+		VAlert(
+			h.Div(
+				h.Div(
+					h.Div(h.Text(msgr.DraftFound)),
+					h.Div(h.Text(draft.SavedAt.Local().Format("2006-01-02 15:04:05"))).Class("text-caption"),
+				),
+				VSpacer(),
+				VBtn(msgr.DraftDiscard).Variant(VariantText).Size(SizeSmall).
+					Attr("@click", "locals.show = false;"+event().EventFunc(actions.DiscardDraft).Go()),
+				VBtn(msgr.DraftRestore).Variant(VariantFlat).Color(ColorPrimary).Size(SizeSmall).Class("ml-2").
+					Attr("@click", "locals.show = false;"+event().EventFunc(actions.RestoreDraft).Go()),
+			).Class("d-flex align-center"),
+		).Type("info").Variant(VariantTonal).Density(DensityCompact).Class("mb-4").
+			Attr("v-if", "locals.show"),
+	).VSlot("{ locals }").Init(`{ show: true }`)
+}
+
+// discardDraft removes the draft of the saved form
+func (mb *ModelBuilder) discardDraft(ctx *web.EventContext, r *web.EventResponse, id string, section string) {
+	key, ok := mb.draftKey(ctx, id, section)
+	if !ok {
+		return
+	}
+	web.AppendRunScripts(r, mb.draftClearTimerScript(ctx, id, section))
+	_ = mb.editing.draftStore.Delete(ctx, key)
+}
+
+// draftValues returns the values of the form posted, the files, the sensitive fields
+// and the fields which the user can't change are not kept
+func (mb *ModelBuilder) draftValues(ctx *web.EventContext, section string) url.Values {
+	posted := ctx.R.PostForm
+	if ctx.R.MultipartForm != nil {
+		posted = url.Values(ctx.R.MultipartForm.Value)
+	}
+	values := url.Values{}
+	for k, vs := range posted {
+		field := k
+		if section != "" {
+			field = strings.TrimPrefix(field, section+".")
+		}
+		field, _, _ = strings.Cut(field, ".")
+		field, _, _ = strings.Cut(field, "[")
+		if slices.Contains(mb.editing.draftSensitiveFields, field) || !mb.Info().FieldWritable(ctx.R, field) {
+			continue
+		}
+		values[k] = vs
+	}
+	return values
+}
+
+// formPermission checks the user can submit the creating form, or the editing form of the record
+func (mb *ModelBuilder) formPermission(ctx *web.EventContext, id string) error {
+	if id == "" {
+		return mb.Info().Verifier().Do(PermCreate).WithReq(ctx.R).IsAllowed()
+	}
+	obj, err := mb.editing.Fetcher(mb.NewModel(), id, ctx)
+	if err != nil {
+		return err
+	}
+	return mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).WithReq(ctx.R).IsAllowed()
+}
+
+func (mb *ModelBuilder) saveDraft(ctx *web.EventContext) (r web.EventResponse, err error) {
+	id := ctx.R.FormValue(ParamID)
+	section := ctx.R.FormValue(SectionFieldName)
+	key, ok := mb.draftKey(ctx, id, section)
+	if !ok || mb.formPermission(ctx, id) != nil {
+		return
+	}
+	values := mb.draftValues(ctx, section)
+	if len(values) == 0 {
+		return
+	}
+	err = mb.editing.draftStore.Save(ctx, key, values)
+	return
+}
+
+func (mb *ModelBuilder) discardDraftEvent(ctx *web.EventContext) (r web.EventResponse, err error) {
+	key, ok := mb.draftKey(ctx, ctx.R.FormValue(ParamID), ctx.R.FormValue(SectionFieldName))
+	if !ok {
+		return
+	}
+	err = mb.editing.draftStore.Delete(ctx, key)
+	return
+}
+
+// restoreDraft fills the form with the draft, the values go through the setters of the fields like the submitted ones
+func (mb *ModelBuilder) restoreDraft(ctx *web.EventContext) (r web.EventResponse, err error) {
+	id := ctx.R.FormValue(ParamID)
+	section := ctx.R.FormValue(SectionFieldName)
+	if mb.formPermission(ctx, id) != nil {
+		ShowMessage(&r, perm.PermissionDenied.Error(), "warning")
+		return
+	}
+	key, ok := mb.draftKey(ctx, id, section)
+	if !ok {
+		return
+	}
+	draft, err := mb.editing.draftStore.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	if draft == nil {
+		ShowMessage(&r, MustGetMessages(ctx.R).DraftNotFound, "warning")
+		return
+	}
+	draftCtx := rowContext(ctx, draft.Values)
+	if step, err := strconv.Atoi(draft.Values.Get(ParamWizardStep)); err == nil {
+		withWizardStep(ctx, step)
+	}
+
+	if section != "" {
+		err = mb.detailing.restoreSectionDraft(ctx, draftCtx, &r, mb.detailing.Section(section), id)
+		return
+	}
+
+	usingB := mb.editing
+	if mb.creating != nil && id == "" {
+		usingB = mb.creating
+	}
+	obj, vErr := usingB.FetchAndUnmarshal(id, true, draftCtx)
+	var flash error
+	if vErr.HaveErrors() {
+		flash = &vErr
+	}
+	usingB.UpdateOverlayContent(ctx, &r, obj, "", flash)
+	return
+}
+
+func (b *DetailingBuilder) restoreSectionDraft(ctx *web.EventContext, draftCtx *web.EventContext, r *web.EventResponse, f *SectionBuilder, id string) error {
+	obj, err := b.GetFetchFunc()(b.mb.NewModel(), id, ctx)
+	if err != nil {
+		return err
+	}
+	if f.setter != nil {
+		f.setter(obj, draftCtx)
+	}
+	if err = f.unmarshalFunc(draftCtx, obj); err != nil {
+		ShowMessage(r, err.Error(), "warning")
+		return nil
+	}
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: f.FieldPortalName(),
+		Body: f.editComponent(obj, &FieldContext{
+			ModelInfo: b.mb.modelInfo,
+			FormKey:   f.name,
+			Name:      f.name,
+			Label:     f.label,
+		}, ctx),
+	})
+	return nil
+}
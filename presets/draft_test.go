@@ -0,0 +1,136 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type memoryDraftStore map[DraftKey]*Draft
+
+func (s memoryDraftStore) Get(ctx *web.EventContext, key DraftKey) (*Draft, error) {
+	return s[key], nil
+}
+
+func (s memoryDraftStore) Save(ctx *web.EventContext, key DraftKey, values url.Values) error {
+	s[key] = &Draft{Values: values, SavedAt: time.Now()}
+	return nil
+}
+
+func (s memoryDraftStore) Delete(ctx *web.EventContext, key DraftKey) error {
+	delete(s, key)
+	return nil
+}
+
+func draftTestContext(target string, form url.Values) *web.EventContext {
+	r := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return &web.EventContext{R: r, W: httptest.NewRecorder()}
+}
+
+type draftProfile struct {
+	ID       uint
+	Name     string
+	Bio      string
+	Password string
+}
+
+func TestDraft(t *testing.T) {
+	op := &memoryTestOperator[draftProfile]{records: []*draftProfile{{ID: 1, Name: "Alice", Bio: "Gopher"}}}
+	pb := New().DataOperator(op)
+	mb := pb.Model(&draftProfile{})
+	store := memoryDraftStore{}
+	mb.Editing("Name", "Bio").Drafts(store, func(r *http.Request) string { return r.Header.Get("User") })
+
+	var restored *draftProfile
+	mb.Editing().Field("Bio").SetterFunc(func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+		restored = obj.(*draftProfile)
+		restored.Bio = ctx.R.FormValue(field.FormKey) + "!"
+		return nil
+	})
+	form := func(bio string) url.Values {
+		return url.Values{"Name": {"Alice"}, "Bio": {bio}}
+	}
+
+	// no user, no draft
+	_, err := mb.saveDraft(draftTestContext("/?id=1", form("Rustacean")))
+	require.NoError(t, err)
+	require.Empty(t, store)
+
+	ctx := draftTestContext("/?id=1", form("Rustacean"))
+	ctx.R.Header.Set("User", "alice")
+	_, err = mb.saveDraft(ctx)
+	require.NoError(t, err)
+	key := DraftKey{User: "alice", Model: "draft-profiles", RecordID: "1"}
+	require.Contains(t, store, key)
+
+	ctx = draftTestContext("/?id=1", nil)
+	ctx.R.Header.Set("User", "alice")
+	assert.Nil(t, mb.draftOffer(ctx, "1", "", web.Plaid), "the draft is only offered when the form is opened")
+	offerDraft(ctx)
+	offer := h.MustString(mb.draftOffer(ctx, "1", "", web.Plaid), context.Background())
+	assert.Contains(t, offer, Messages_en_US.DraftFound)
+	ctx.R.Header.Set("User", "bob")
+	assert.Nil(t, mb.draftOffer(ctx, "1", "", web.Plaid), "the drafts belong to the user")
+
+	ctx = draftTestContext("/?id=1", nil)
+	ctx.R.Header.Set("User", "alice")
+	_, err = mb.restoreDraft(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	assert.Equal(t, "Rustacean!", restored.Bio)
+	assert.Equal(t, "Gopher", op.records[0].Bio, "restoring doesn't save the record")
+
+	ctx = draftTestContext("/?id=1", form("Pythonista"))
+	ctx.R.Header.Set("User", "alice")
+	_, err = mb.editing.defaultUpdate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Pythonista!", op.records[0].Bio)
+	assert.NotContains(t, store, key, "the draft is discarded once the record is saved")
+}
+
+func TestDraftValues(t *testing.T) {
+	op := &memoryTestOperator[draftProfile]{records: []*draftProfile{{ID: 1, Name: "Alice"}}}
+	pb := New().DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("clerk").WhoAre(perm.Denied).ToDo(PermUpdate).On(":presets:fields:draft_profiles:name:"),
+		perm.PolicyFor("stranger").WhoAre(perm.Denied).ToDo(PermUpdate).On(":presets:draft_profiles:draft_profiles:1:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&draftProfile{})
+	store := memoryDraftStore{}
+	mb.Editing("Name", "Bio", "Password").Drafts(store, func(r *http.Request) string { return "alice" }).DraftSensitiveFields("Password")
+	key := DraftKey{User: "alice", Model: "draft-profiles", RecordID: "1"}
+
+	save := func(role string) {
+		ctx := draftTestContext("/?id=1", url.Values{"Name": {"Alicia"}, "Password": {"secret"}, "Bio": {"Gopher"}})
+		ctx.R.Header.Set("role", role)
+		_, err := mb.saveDraft(ctx)
+		require.NoError(t, err)
+	}
+
+	// the sensitive fields are never kept
+	save("")
+	require.Contains(t, store, key)
+	assert.Equal(t, url.Values{"Name": {"Alicia"}, "Bio": {"Gopher"}}, store[key].Values)
+
+	// the fields the user can't change are not kept
+	save("clerk")
+	assert.Equal(t, url.Values{"Bio": {"Gopher"}}, store[key].Values)
+
+	// the permission is checked on the record
+	delete(store, key)
+	save("stranger")
+	assert.NotContains(t, store, key)
+}
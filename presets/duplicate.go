@@ -0,0 +1,225 @@
+package presets
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+const duplicateActionName = "Duplicate"
+
+// DuplicateResetFunc resets the fields of the copy which can't be the same as the record copied, like the unique ones
+type DuplicateResetFunc func(obj interface{}, ctx *web.EventContext) error
+
+type DuplicateBuilder struct {
+	mb           *ModelBuilder
+	associations []string
+	resetFunc    DuplicateResetFunc
+}
+
+// Duplicate adds the Duplicate row menu item and detailing action, which save a copy of the record
+// with the creating saver and open it in the editor. The primary field and the timestamps of the copy are reset,
+// the other fields can be reset by ResetFunc.
+func (mb *ModelBuilder) Duplicate() (r *DuplicateBuilder) {
+	if mb.duplicating != nil {
+		return mb.duplicating
+	}
+	mb.duplicating = &DuplicateBuilder{mb: mb}
+
+	mb.listing.RowMenu().RowMenuItem(duplicateActionName).ComponentFunc(func(obj interface{}, id string, ctx *web.EventContext) h.HTMLComponent {
+		if !mb.duplicating.allowed(obj, ctx) {
+			return nil
+		}
+		return VListItem(
+			web.Slot(VIcon("mdi-content-copy")).Name("prepend"),
+			VListItemTitle(h.Text(MustGetMessages(ctx.R).Duplicate)),
+		).Attr("@click", mb.duplicating.event(id, ctx))
+	})
+	mb.detailing.Action(duplicateActionName).ButtonCompFunc(func(ctx *web.EventContext) h.HTMLComponent {
+		if mb.Info().Verifier().Do(PermCreate).WithReq(ctx.R).IsAllowed() != nil {
+			return nil
+		}
+		return VBtn(MustGetMessages(ctx.R).Duplicate).
+			PrependIcon("mdi-content-copy").
+			Variant(VariantTonal).
+			Attr("@click", mb.duplicating.event(ctx.Param(ParamID), ctx))
+	})
+	return mb.duplicating
+}
+
+// Associations are the fields of the associations copied with the record, they are loaded by the data operator
+// implementing AssociationLoader. The records of a many2many association are shared by the copy,
+// while the ones of the other associations are copied with their primary fields reset.
+// The associations not set here are copied as they are fetched, the default fetcher doesn't load them.
+func (b *DuplicateBuilder) Associations(names ...string) (r *DuplicateBuilder) {
+	b.associations = names
+	return b
+}
+
+// ResetFunc resets the fields of the copy before it's saved, like making the unique fields unique
+func (b *DuplicateBuilder) ResetFunc(v DuplicateResetFunc) (r *DuplicateBuilder) {
+	b.resetFunc = v
+	return b
+}
+
+func (b *DuplicateBuilder) allowed(obj interface{}, ctx *web.EventContext) bool {
+	verifier := b.mb.Info().Verifier()
+	return verifier.Do(PermGet).ObjectOn(obj).WithReq(ctx.R).IsAllowed() == nil &&
+		verifier.Do(PermCreate).WithReq(ctx.R).IsAllowed() == nil
+}
+
+func (b *DuplicateBuilder) event(id string, ctx *web.EventContext) string {
+	e := web.Plaid().
+		EventFunc(actions.Duplicate).
+		Query(ParamID, id).
+		URL(b.mb.Info().ListingHref())
+	if IsInDialog(ctx) {
+		e.Query(ParamOverlay, actions.Dialog)
+	}
+	return e.Go()
+}
+
+// Copy fetches the record and returns the copy of it which isn't saved yet
+func (b *DuplicateBuilder) Copy(id string, ctx *web.EventContext) (obj interface{}, err error) {
+	obj, err = b.mb.editing.Fetcher(b.mb.NewModel(), id, ctx)
+	if err != nil {
+		return
+	}
+	err = b.copy(obj, ctx)
+	return
+}
+
+// copy turns the fetched record obj into the copy of it
+func (b *DuplicateBuilder) copy(obj interface{}, ctx *web.EventContext) (err error) {
+	mb := b.mb
+	loader, _ := mb.p.dataOperator.(AssociationLoader)
+	for _, name := range b.associations {
+		if loader == nil {
+			return errors.Errorf("the data operator must implement AssociationLoader to copy the association %s", name)
+		}
+		if err = loader.LoadAssociation(obj, name, ctx); err != nil {
+			return
+		}
+		if many2manyModelType(obj, name) != nil {
+			continue
+		}
+		if err = b.resetAssociation(obj, name); err != nil {
+			return
+		}
+	}
+
+	if err = resetCopiedRecord(obj, mb.primaryField); err != nil {
+		return
+	}
+	if b.resetFunc != nil {
+		if err = b.resetFunc(obj, ctx); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// resetAssociation resets the primary fields of the records of the association, so that they are saved as new records
+func (b *DuplicateBuilder) resetAssociation(obj interface{}, name string) error {
+	rv := reflectValueOf(obj, name)
+	if !rv.IsValid() {
+		return nil
+	}
+	var records []interface{}
+	switch rv.Kind() {
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			records = append(records, rv.Index(i).Addr().Interface())
+		}
+	case reflect.Struct:
+		records = append(records, rv.Addr().Interface())
+	default:
+		return errors.Errorf("%s isn't an association", name)
+	}
+
+	for _, record := range records {
+		if v := reflect.ValueOf(record).Elem(); v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			record = v.Interface()
+		}
+		primaryField := "ID"
+		if mb := b.mb.p.modelBuilderOfType(reflect.TypeOf(record)); mb != nil {
+			primaryField = mb.primaryField
+		}
+		if err := resetCopiedRecord(record, primaryField); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetCopiedRecord resets the primary field and the timestamps of the copy
+func resetCopiedRecord(obj interface{}, primaryField string) error {
+	for _, name := range []string{primaryField, "CreatedAt", "UpdatedAt", "DeletedAt"} {
+		if name == "" {
+			continue
+		}
+		t := reflectutils.GetType(obj, name)
+		if t == nil {
+			continue
+		}
+		if err := reflectutils.Set(obj, name, reflect.Zero(t).Interface()); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// duplicate saves the copy of the record as a new record and opens it in the editor
+func (mb *ModelBuilder) duplicate(ctx *web.EventContext) (r web.EventResponse, err error) {
+	b := mb.duplicating
+	if b == nil {
+		return r, errors.New("duplicate is not enabled")
+	}
+	msgr := MustGetMessages(ctx.R)
+
+	obj, err := mb.editing.Fetcher(mb.NewModel(), ctx.R.FormValue(ParamID), ctx)
+	if err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+	// the permissions are checked on the record copied, the primary field of the copy is reset
+	if !b.allowed(obj, ctx) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+	if err = b.copy(obj, ctx); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	usingB := mb.editing
+	if mb.creating != nil {
+		usingB = mb.creating
+	}
+	if err = usingB.Saver(obj, "", ctx); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	r.Emit(mb.NotifModelsCreated(), PayloadModelsCreated{Models: []any{obj}})
+	ShowMessage(&r, msgr.SuccessfullyCreated, "")
+
+	edit := web.Plaid().
+		EventFunc(actions.Edit).
+		Query(ParamID, ObjectID(obj)).
+		URL(mb.Info().ListingHref())
+	if overlay := ctx.R.FormValue(ParamOverlay); overlay != "" {
+		edit.Query(ParamOverlay, overlay)
+	}
+	web.AppendRunScripts(&r, edit.Go())
+	return
+}
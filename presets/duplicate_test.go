@@ -0,0 +1,80 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type duplicateProduct struct {
+	ID   uint
+	Name string
+}
+
+func TestDuplicate(t *testing.T) {
+	op := &memoryTestOperator[duplicateProduct]{records: []*duplicateProduct{{ID: 1, Name: "Tea"}}}
+	pb := New().DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("viewer").WhoAre(perm.Denied).ToDo(PermCreate).On(perm.Anything),
+		perm.PolicyFor("stranger").WhoAre(perm.Denied).ToDo(PermGet).On(":presets:duplicate_products:duplicate_products:1:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&duplicateProduct{})
+	mb.Editing("Name")
+	mb.Duplicate().ResetFunc(func(obj interface{}, ctx *web.EventContext) error {
+		obj.(*duplicateProduct).Name += " copy"
+		return nil
+	})
+
+	request := func(role string) *web.EventContext {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("role", role)
+		return rowContext(&web.EventContext{R: r, W: httptest.NewRecorder()}, url.Values{ParamID: {"1"}})
+	}
+	rowMenu := func(ctx *web.EventContext) string {
+		var comps []h.HTMLComponent
+		for _, f := range mb.Listing().RowMenu().listingItemFuncs(ctx) {
+			comps = append(comps, f(op.records[0], "1", ctx))
+		}
+		return h.MustString(h.Components(comps...), web.WrapEventContext(context.Background(), ctx))
+	}
+
+	ctx := request("viewer")
+	assert.NotContains(t, rowMenu(ctx), Messages_en_US.Duplicate)
+	r, err := mb.duplicate(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	assert.Len(t, op.records, 1)
+
+	// the record copied can't be read, while the copy whose primary field is reset could be
+	ctx = request("stranger")
+	assert.NotContains(t, rowMenu(ctx), Messages_en_US.Duplicate)
+	r, err = mb.duplicate(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	assert.Len(t, op.records, 1)
+
+	ctx = request("admin")
+	body := rowMenu(ctx)
+	assert.Contains(t, body, Messages_en_US.Duplicate)
+	assert.Contains(t, body, actions.Duplicate)
+
+	r, err = mb.duplicate(ctx)
+	require.NoError(t, err)
+	require.Len(t, op.records, 2)
+	assert.Equal(t, uint(2), op.records[1].ID)
+	assert.Equal(t, "Tea copy", op.records[1].Name)
+	assert.Equal(t, "Tea", op.records[0].Name)
+	assert.Contains(t, r.RunScript, actions.Edit, "the copy is opened in the editor")
+	assert.Contains(t, r.RunScript, `.query("id", "2")`)
+}
@@ -1,6 +1,7 @@
 package presets
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -26,6 +27,10 @@ type EditingBuilder struct {
 	editingTitleFunc         EditingTitleComponentFunc
 	onChangeAction           OnChangeActionFunc
 	idCurrentActiveProcessor IdCurrentActiveProcessor
+	draftStore               DraftStore
+	draftUserFunc            DraftUserFunc
+	draftSensitiveFields     []string
+	wizardSteps              []*WizardStepBuilder
 	FieldsBuilder
 }
 
@@ -142,6 +147,16 @@ func (b *EditingBuilder) WrapValidateFunc(w func(in ValidateFunc) ValidateFunc)
 	return b
 }
 
+// Validate runs the ValidateFunc and then the validators of the editing fields,
+// the errors of the validators are keyed by the form keys of the fields.
+func (b *EditingBuilder) Validate(obj interface{}, ctx *web.EventContext) (vErr web.ValidationErrors) {
+	if b.Validator != nil {
+		vErr = b.Validator(obj, ctx)
+	}
+	b.FieldsBuilder.validate(obj, b.mb.Info(), "", ctx, &vErr)
+	return
+}
+
 func (b *EditingBuilder) SetterFunc(v SetterFunc) (r *EditingBuilder) {
 	b.Setter = v
 	return b
@@ -212,6 +227,7 @@ func (b *EditingBuilder) formNew(ctx *web.EventContext) (r web.EventResponse, er
 	if b.idCurrentActiveProcessor != nil {
 		ctx.WithContextValue(ctxKeyIdCurrentActiveProcessor{}, b.idCurrentActiveProcessor)
 	}
+	offerDraft(ctx)
 	b.mb.p.overlay(ctx, &r, creatingB.editFormFor(nil, ctx), b.mb.rightDrawerWidth)
 	return
 }
@@ -224,6 +240,7 @@ func (b *EditingBuilder) formEdit(ctx *web.EventContext) (r web.EventResponse, e
 	if b.idCurrentActiveProcessor != nil {
 		ctx.WithContextValue(ctxKeyIdCurrentActiveProcessor{}, b.idCurrentActiveProcessor)
 	}
+	offerDraft(ctx)
 	b.mb.p.overlay(ctx, &r, b.editFormFor(nil, ctx), b.mb.rightDrawerWidth)
 	return
 }
@@ -247,6 +264,7 @@ func (b *EditingBuilder) singletonPageFunc(ctx *web.EventContext) (r web.PageRes
 	if err != nil {
 		return
 	}
+	offerDraft(ctx)
 	r.Body = web.Portal(b.editFormFor(obj, ctx)).Name(singletonEditingPortalName)
 	return
 }
@@ -326,10 +344,15 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 	if b.mb.singleton {
 		queries.Set(ParamID, id)
 	}
+	draftEvent := func() *web.VueEventTagBuilder {
+		return web.Plaid().
+			Queries(queries).
+			URL(b.mb.Info().ListingHref())
+	}
 	updateBtn := VBtn(buttonLabel).
 		Color("primary").
 		Variant(VariantFlat).
-		Attr("@click", web.Plaid().
+		Attr("@click", b.mb.draftClearTimerScript(ctx, id, "")+web.Plaid().
 			EventFunc(actions.Update).
 			Queries(queries).
 			URL(b.mb.Info().ListingHref()).
@@ -345,6 +368,10 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 		updateBtn,
 	)
 
+	if b.isWizard(id) {
+		actionButtons = b.wizardButtons(queries, updateBtn, ctx)
+	}
+
 	if b.actionsFunc != nil {
 		actionButtons = b.actionsFunc(obj, ctx)
 	}
@@ -353,11 +380,22 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 	for _, hf := range b.hiddenFuncs {
 		hiddenComps = append(hiddenComps, hf(obj, ctx))
 	}
+	if len(id) > 0 {
+		hiddenComps = append(hiddenComps, b.mb.versionHidden(obj))
+	}
 
+	withDependentFieldEvent(ctx, draftEvent)
+	var fieldsComp h.HTMLComponent
+	if b.isWizard(id) {
+		fieldsComp = b.wizardComponent(obj, ctx)
+	} else {
+		fieldsComp = b.ToComponent(b.mb.Info(), obj, ctx)
+	}
 	formContent := web.Scope(h.Components(
 		VCardText(
+			h.If(!isAutoSave, b.mb.draftOffer(ctx, id, "", draftEvent)),
 			h.Components(hiddenComps...),
-			b.ToComponent(b.mb.Info(), obj, ctx),
+			fieldsComp,
 		),
 		h.If(!isAutoSave, VCardActions(actionButtons)),
 	))
@@ -395,7 +433,7 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 	if isAutoSave {
 		return scope.OnChange(onChangeEvent + b.onChangeAction(id, ctx))
 	}
-	return scope.OnChange(onChangeEvent).UseDebounce(150)
+	return scope.OnChange(onChangeEvent + b.mb.draftAutosaveScript(ctx, id, "", draftEvent)).UseDebounce(150)
 }
 
 func (b *EditingBuilder) doDelete(ctx *web.EventContext) (r web.EventResponse, err1 error) {
@@ -417,6 +455,9 @@ func (b *EditingBuilder) doDelete(ctx *web.EventContext) (r web.EventResponse, e
 			b.mb.NotifModelsDeleted(),
 			PayloadModelsDeleted{Ids: []string{id}},
 		)
+		if undo := b.mb.listing.deleteUndo([]string{id}); undo != nil {
+			b.mb.p.ShowUndoMessage(&r, ctx, MustGetMessages(ctx.R).SuccessfullyDeleted, undo)
+		}
 	}
 
 	web.AppendRunScripts(&r, "locals.deleteConfirmation = false")
@@ -473,6 +514,14 @@ func (b *EditingBuilder) doUpdate(
 			b.UpdateOverlayContent(ctx, r, obj, "", perm.PermissionDenied)
 			return created, perm.PermissionDenied
 		}
+		if err = b.mb.checkVersion(ctx, obj); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				b.versionConflict(ctx, r, id)
+				return
+			}
+			b.UpdateOverlayContent(ctx, r, obj, "", err)
+			return
+		}
 	} else {
 		if b.mb.Info().Verifier().Do(PermCreate).ObjectOn(obj).WithReq(ctx.R).IsAllowed() != nil {
 			b.UpdateOverlayContent(ctx, r, obj, "", perm.PermissionDenied)
@@ -480,19 +529,26 @@ func (b *EditingBuilder) doUpdate(
 		}
 	}
 
-	if usingB.Validator != nil {
-		if vErr = usingB.Validator(obj, ctx); vErr.HaveErrors() {
-			usingB.UpdateOverlayContent(ctx, r, obj, "", &vErr)
-			return created, &vErr
-		}
+	if vErr = usingB.Validate(obj, ctx); !vErr.HaveErrors() && usingB.isWizard(id) {
+		vErr = usingB.validateWizardSteps(obj, ctx)
+	}
+	if vErr.HaveErrors() {
+		usingB.UpdateOverlayContent(ctx, r, obj, "", &vErr)
+		return created, &vErr
 	}
 
 	err1 := usingB.Saver(obj, id, ctx)
+	if errors.Is(err1, ErrVersionConflict) && len(id) > 0 {
+		b.versionConflict(ctx, r, id)
+		return created, err1
+	}
 	if err1 != nil {
 		usingB.UpdateOverlayContent(ctx, r, obj, "", err1)
 		return created, err1
 	}
 
+	b.mb.discardDraft(ctx, r, id, "")
+
 	if id == "" {
 		r.Emit(
 			b.mb.NotifModelsCreated(),
@@ -531,6 +587,28 @@ func (b *EditingBuilder) doUpdate(
 	return
 }
 
+// versionConflict keeps the user's changes in the form on top of the latest version of the record,
+// and asks the user whether to keep them or to discard them and reload the latest version.
+func (b *EditingBuilder) versionConflict(ctx *web.EventContext, r *web.EventResponse, id string) {
+	latest, err := b.Fetcher(b.mb.NewModel(), id, ctx)
+	if err != nil {
+		b.UpdateOverlayContent(ctx, r, nil, "", err)
+		return
+	}
+	yours, vErr := b.FetchAndUnmarshal(id, true, ctx)
+	var flash error
+	if vErr.HaveErrors() {
+		flash = &vErr
+	}
+	b.UpdateOverlayContent(ctx, r, yours, "", flash)
+	b.mb.versionConflictDialog(ctx, r, latest, yours,
+		web.Plaid().EventFunc(actions.Edit).
+			Queries(ctx.Queries()).
+			URL(b.mb.Info().ListingHref()).
+			Go(),
+	)
+}
+
 func (b *EditingBuilder) defaultUpdate(ctx *web.EventContext) (r web.EventResponse, err error) {
 	created, uErr := b.doUpdate(ctx, &r, false)
 	if uErr == nil {
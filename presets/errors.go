@@ -2,4 +2,7 @@ package presets
 
 import "errors"
 
-var ErrRecordNotFound = errors.New("record not found")
+var (
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrVersionConflict = errors.New("the record has been modified by someone else")
+)
@@ -0,0 +1,418 @@
+package presets
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/samber/lo"
+	"github.com/theplant/relay"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+const (
+	ParamExportFormat = "export_format"
+	ParamExportState  = "export_state"
+
+	exportBatchSizeDefault = 500
+)
+
+// ExportValueFunc returns the text written into the exported cell of the field,
+// if not set, the text of the listing field component will be used.
+type ExportValueFunc func(obj interface{}, field *FieldContext, ctx *web.EventContext) (string, error)
+
+type ExportBuilder struct {
+	lb           *ListingBuilder
+	formats      []ExportFormat
+	batchSize    int64
+	fileNameFunc func(ctx *web.EventContext, format ExportFormat) string
+	valueFuncs   map[string]ExportValueFunc
+}
+
+// Export enables the export action of the listing, it exports all the records
+// that match the current keyword, filters and order bys with the visible columns.
+// if no formats is given, csv and xlsx will be both available.
+func (b *ListingBuilder) Export(formats ...ExportFormat) (r *ExportBuilder) {
+	if b.exporting == nil {
+		b.exporting = &ExportBuilder{
+			lb:         b,
+			formats:    []ExportFormat{ExportFormatCSV, ExportFormatXLSX},
+			batchSize:  exportBatchSizeDefault,
+			valueFuncs: make(map[string]ExportValueFunc),
+		}
+	}
+	if len(formats) > 0 {
+		b.exporting.formats = formats
+	}
+	return b.exporting
+}
+
+func (b *ExportBuilder) Formats(vs ...ExportFormat) (r *ExportBuilder) {
+	b.formats = vs
+	return b
+}
+
+// BatchSize is the number of records fetched from the searcher at a time.
+func (b *ExportBuilder) BatchSize(v int64) (r *ExportBuilder) {
+	if v <= 0 || v > PerPageMax {
+		v = exportBatchSizeDefault
+	}
+	b.batchSize = v
+	return b
+}
+
+func (b *ExportBuilder) FileNameFunc(v func(ctx *web.EventContext, format ExportFormat) string) (r *ExportBuilder) {
+	b.fileNameFunc = v
+	return b
+}
+
+func (b *ExportBuilder) ValueFunc(field string, v ExportValueFunc) (r *ExportBuilder) {
+	b.valueFuncs[field] = v
+	return b
+}
+
+type exportState struct {
+	Keyword        string           `json:"keyword,omitempty"`
+	OrderBys       []ColOrderBy     `json:"order_bys,omitempty"`
+	DisplayColumns []*DisplayColumn `json:"display_columns,omitempty"`
+	FilterQuery    string           `json:"filter_query,omitempty"`
+	Trash          bool             `json:"trash,omitempty"`
+}
+
+func (b *ExportBuilder) href(c *ListingCompo, format ExportFormat) string {
+	state, err := json.Marshal(exportState{
+		Keyword:        c.Keyword,
+		OrderBys:       c.OrderBys,
+		DisplayColumns: c.DisplayColumns,
+		FilterQuery:    c.FilterQuery,
+		Trash:          c.Trash,
+	})
+	if err != nil {
+		panic(err)
+	}
+	q := url.Values{}
+	q.Set(ParamExportFormat, string(format))
+	q.Set(ParamExportState, string(state))
+	return fmt.Sprintf("%s?%s", b.lb.mb.Info().ExportHref(), q.Encode())
+}
+
+func (b *ExportBuilder) fileName(evCtx *web.EventContext, format ExportFormat) string {
+	if b.fileNameFunc != nil {
+		return b.fileNameFunc(evCtx, format)
+	}
+	return fmt.Sprintf("%s-%s.%s", b.lb.mb.uriName, time.Now().Format("20060102150405"), format)
+}
+
+func (b *ExportBuilder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	evCtx := &web.EventContext{R: r, W: w}
+	verifier := b.lb.mb.Info().Verifier()
+	if verifier.Do(PermList).WithReq(r).IsAllowed() != nil ||
+		verifier.Do(PermExport).WithReq(r).IsAllowed() != nil {
+		http.Error(w, perm.PermissionDenied.Error(), http.StatusForbidden)
+		return
+	}
+
+	format := ExportFormat(r.FormValue(ParamExportFormat))
+	if !lo.Contains(b.formats, format) {
+		http.Error(w, fmt.Sprintf("unsupported export format %q", format), http.StatusBadRequest)
+		return
+	}
+
+	var state exportState
+	if v := r.FormValue(ParamExportState); v != "" {
+		if err := json.Unmarshal([]byte(v), &state); err != nil {
+			http.Error(w, "invalid export state", http.StatusBadRequest)
+			return
+		}
+	}
+
+	c := &ListingCompo{
+		lb:             b.lb,
+		Keyword:        state.Keyword,
+		OrderBys:       state.OrderBys,
+		DisplayColumns: state.DisplayColumns,
+		FilterQuery:    state.FilterQuery,
+		Trash:          state.Trash,
+	}
+	if err := c.checkAdvancedFilter(evCtx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	open := func() exportWriter {
+		switch format {
+		case ExportFormatXLSX:
+			w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		default:
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", b.fileName(evCtx, format)))
+		if format == ExportFormatXLSX {
+			return newXLSXExportWriter(w)
+		}
+		return newCSVExportWriter(w)
+	}
+
+	ew, err := b.export(evCtx, c, open)
+	if err != nil {
+		b.lb.mb.p.logger.Error(fmt.Sprintf("export %s failed: %v", b.lb.mb.uriName, err))
+		// nothing is written yet, the error can still be responded
+		if ew == nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := ew.Close(); err != nil {
+		b.lb.mb.p.logger.Error(fmt.Sprintf("export %s failed: %v", b.lb.mb.uriName, err))
+	}
+}
+
+// export writes the records into the writer returned by open, which is only called once the first page
+// of the records is loaded, so ew is nil if the export fails before anything is written.
+func (b *ExportBuilder) export(evCtx *web.EventContext, c *ListingCompo, open func() exportWriter) (ew exportWriter, err error) {
+	if b.lb.Searcher == nil {
+		return nil, errors.New("function Searcher is not set")
+	}
+
+	ctx := web.WrapEventContext(evCtx.R.Context(), evCtx)
+	_, columns, err := c.getColumns(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get columns error")
+	}
+	columns = lo.Filter(columns, func(col *Column, _ int) bool {
+		return col.Visible
+	})
+
+	start := func() error {
+		if ew != nil {
+			return nil
+		}
+		ew = open()
+		return ew.WriteRow(lo.Map(columns, func(col *Column, _ int) string {
+			return col.Label
+		}))
+	}
+
+	err = b.eachRecord(evCtx, c, func(obj interface{}) error {
+		if err := start(); err != nil {
+			return err
+		}
+		cells := make([]string, 0, len(columns))
+		for _, col := range columns {
+			v, err := b.cellValue(ctx, evCtx, obj, col)
+			if err != nil {
+				return err
+			}
+			cells = append(cells, v)
+		}
+		return ew.WriteRow(cells)
+	})
+	if err != nil {
+		return
+	}
+	// no records, only the header row is exported
+	err = start()
+	return
+}
+
+// eachRecord walks through every page of the search result of the listing.
+func (b *ExportBuilder) eachRecord(evCtx *web.EventContext, c *ListingCompo, f func(obj interface{}) error) error {
+	searchParams, _, _, _ := c.buildSearchParams(evCtx)
+	searchParams.PerPage = b.batchSize
+	searchParams.Page = 1
+	if searchParams.RelayPagination != nil {
+		searchParams.RelayPaginateRequest = &relay.PaginateRequest[any]{
+			OrderBys: searchParams.OrderBys,
+			First:    lo.ToPtr(int(b.batchSize)),
+		}
+	}
+
+	var fetched int
+	for {
+		result, err := b.lb.Searcher(evCtx, searchParams)
+		if err != nil {
+			return errors.Wrap(err, "searcher error")
+		}
+
+		nodes := reflect.ValueOf(result.Nodes)
+		if nodes.Kind() != reflect.Slice {
+			return errors.New("search result nodes must be a slice")
+		}
+		for i := 0; i < nodes.Len(); i++ {
+			if err := f(nodes.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		fetched += nodes.Len()
+
+		if nodes.Len() < int(b.batchSize) ||
+			(result.PageInfo.TotalCount > 0 && fetched >= result.PageInfo.TotalCount) {
+			return nil
+		}
+		if searchParams.RelayPagination != nil {
+			if !result.PageInfo.HasNextPage || result.PageInfo.EndCursor == nil {
+				return nil
+			}
+			searchParams.RelayPaginateRequest.After = result.PageInfo.EndCursor
+			continue
+		}
+		searchParams.Page++
+	}
+}
+
+func (b *ExportBuilder) cellValue(ctx context.Context, evCtx *web.EventContext, obj interface{}, col *Column) (string, error) {
+	f := b.lb.getFieldOrDefault(col.Name)
+	field := b.lb.mb.getComponentFuncField(f)
+	field.Label = col.Label
+	field.Context = f.context
+	if vf, ok := b.valueFuncs[col.Name]; ok {
+		return vf(obj, field, evCtx)
+	}
+
+	comp := f.lazyCompFunc()(obj, field, evCtx)
+	if comp == nil {
+		return "", nil
+	}
+	bs, err := comp.MarshalHTML(ctx)
+	if err != nil {
+		return "", err
+	}
+	return exportCellText(string(bs)), nil
+}
+
+var (
+	exportTagReg   = regexp.MustCompile(`<[^>]*>`)
+	exportSpaceReg = regexp.MustCompile(`\s+`)
+)
+
+// exportCellText turns the html of the listing cell into plain text
+func exportCellText(s string) string {
+	s = exportTagReg.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(exportSpaceReg.ReplaceAllString(s, " "))
+}
+
+// exportCellSafe prefixes the text which would be taken as a formula by the spreadsheet applications with a quote
+func exportCellSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type exportWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) *csvExportWriter {
+	// UTF-8 BOM makes Excel detect the encoding correctly
+	_, _ = w.Write([]byte("\xEF\xBB\xBF"))
+	return &csvExportWriter{w: csv.NewWriter(w)}
+}
+
+func (ew *csvExportWriter) WriteRow(cells []string) error {
+	return ew.w.Write(lo.Map(cells, func(cell string, _ int) string {
+		return exportCellSafe(cell)
+	}))
+}
+
+func (ew *csvExportWriter) Close() error {
+	ew.w.Flush()
+	return ew.w.Error()
+}
+
+// xlsxExportWriter streams rows into a single sheet workbook with inline strings,
+// so that no rows need to be kept in memory.
+type xlsxExportWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	err   error
+}
+
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func newXLSXExportWriter(w io.Writer) *xlsxExportWriter {
+	ew := &xlsxExportWriter{zw: zip.NewWriter(w)}
+	for _, part := range xlsxStaticParts {
+		var pw io.Writer
+		pw, ew.err = ew.zw.Create(part.name)
+		if ew.err != nil {
+			return ew
+		}
+		if _, ew.err = io.WriteString(pw, part.content); ew.err != nil {
+			return ew
+		}
+	}
+	ew.sheet, ew.err = ew.zw.Create("xl/worksheets/sheet1.xml")
+	if ew.err != nil {
+		return ew
+	}
+	_, ew.err = io.WriteString(ew.sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return ew
+}
+
+func (ew *xlsxExportWriter) WriteRow(cells []string) error {
+	if ew.err != nil {
+		return ew.err
+	}
+	var sb strings.Builder
+	sb.WriteString("<row>")
+	for _, cell := range cells {
+		sb.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if ew.err = xml.EscapeText(&sb, []byte(exportCellSafe(cell))); ew.err != nil {
+			return ew.err
+		}
+		sb.WriteString("</t></is></c>")
+	}
+	sb.WriteString("</row>")
+	_, ew.err = io.WriteString(ew.sheet, sb.String())
+	return ew.err
+}
+
+func (ew *xlsxExportWriter) Close() error {
+	if ew.err != nil {
+		return ew.err
+	}
+	if _, err := io.WriteString(ew.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return ew.zw.Close()
+}
@@ -0,0 +1,115 @@
+package presets
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCellText(t *testing.T) {
+	assert.Equal(t, "Tom & Jerry", exportCellText(`<td><span> Tom</span> &amp;
+		Jerry </td>`))
+	assert.Equal(t, "", exportCellText(`<td></td>`))
+}
+
+func TestExportEachRecord(t *testing.T) {
+	mb := New().Model(&foo{})
+	var pages []int64
+	mb.Listing().SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		pages = append(pages, params.Page)
+		var nodes []*foo
+		for i := int64(0); i < params.PerPage; i++ {
+			n := (params.Page-1)*params.PerPage + i
+			if n >= 5 {
+				break
+			}
+			nodes = append(nodes, &foo{Version: string(rune('a' + n))})
+		}
+		return &SearchResult{Nodes: nodes}, nil
+	})
+	eb := mb.Listing().Export().BatchSize(2)
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("GET", "/", nil)}
+	var versions []string
+	err := eb.eachRecord(evCtx, &ListingCompo{lb: mb.Listing()}, func(obj interface{}) error {
+		versions = append(versions, obj.(*foo).Version)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, pages)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, versions)
+}
+
+func TestExportWriters(t *testing.T) {
+	rows := [][]string{{"Name", "Note"}, {"a,b", "<x> & \"y\""}}
+
+	var csvBuf bytes.Buffer
+	cw := newCSVExportWriter(&csvBuf)
+	for _, row := range rows {
+		require.NoError(t, cw.WriteRow(row))
+	}
+	require.NoError(t, cw.Close())
+	assert.Equal(t, "\xEF\xBB\xBFName,Note\n\"a,b\",\"<x> & \"\"y\"\"\"\n", csvBuf.String())
+
+	var xlsxBuf bytes.Buffer
+	xw := newXLSXExportWriter(&xlsxBuf)
+	for _, row := range rows {
+		require.NoError(t, xw.WriteRow(row))
+	}
+	require.NoError(t, xw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(xlsxBuf.Bytes()), int64(xlsxBuf.Len()))
+	require.NoError(t, err)
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		bs, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		sheet = string(bs)
+	}
+	assert.Equal(t, 2, strings.Count(sheet, "<row>"))
+	assert.Contains(t, sheet, "&lt;x&gt; &amp; &#34;y&#34;")
+}
+
+func TestExportCellSafe(t *testing.T) {
+	for _, s := range []string{"=1+1", "+1", "-1", "@SUM(A1)", "\tx", "\rx"} {
+		assert.Equal(t, "'"+s, exportCellSafe(s))
+	}
+	assert.Equal(t, "a=1", exportCellSafe("a=1"))
+	assert.Equal(t, "", exportCellSafe(""))
+
+	var buf bytes.Buffer
+	cw := newCSVExportWriter(&buf)
+	require.NoError(t, cw.WriteRow([]string{`=HYPERLINK("http://x")`, "ok"}))
+	require.NoError(t, cw.Close())
+	assert.Equal(t, "\xEF\xBB\xBF\"'=HYPERLINK(\"\"http://x\"\")\",ok\n", buf.String())
+}
+
+func TestExportSearchError(t *testing.T) {
+	mb := New().Model(&foo{})
+	mb.Listing("Version").SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		return nil, errors.New("database is down")
+	})
+	mb.Listing().Export()
+
+	// the first page is loaded before the file is written, so the error is responded
+	w := httptest.NewRecorder()
+	mb.Listing().exporting.ServeHTTP(w, httptest.NewRequest("GET", "/?"+url.Values{ParamExportFormat: {"csv"}}.Encode(), nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.NotContains(t, w.Body.String(), "database is down")
+}
@@ -31,6 +31,7 @@ type FieldContext struct {
 	NestedFieldsBuilder *FieldsBuilder
 	Context             context.Context
 	Disabled            bool
+	Validators          []*FieldValidator
 }
 
 func (fc *FieldContext) StringValue(obj interface{}) (r string) {
@@ -84,6 +85,10 @@ type FieldBuilder struct {
 	nestedFieldsBuilder *FieldsBuilder
 	tabFieldsBuilders   *TabsFieldBuilder
 	plugins             []FieldPlugin
+	validators          []*FieldValidator
+	visibleWhen         *fieldCondition
+	requiredWhen        *fieldCondition
+	reloadOn            []string
 }
 
 func (b *FieldsBuilder) appendNewFieldWithName(name string) (r *FieldBuilder) {
@@ -104,6 +109,7 @@ func (b *FieldsBuilder) NewFieldWithName(name string) (r *FieldBuilder) {
 		fType = reflect.TypeOf("")
 	}
 	r.rt = fType
+	r.validators = validatorsFromTag(b.model, name)
 
 	// if b.defaults == nil {
 	// 	panic("field defaults must be provided")
@@ -138,6 +144,10 @@ func (b *FieldBuilder) Clone() (r *FieldBuilder) {
 	r.context = b.context
 	r.rt = b.rt
 	r.plugins = b.plugins
+	r.validators = slices.Clone(b.validators)
+	r.visibleWhen = b.visibleWhen
+	r.requiredWhen = b.requiredWhen
+	r.reloadOn = slices.Clone(b.reloadOn)
 	return r
 }
 
@@ -367,74 +377,95 @@ func (b *FieldsBuilder) Unmarshal(toObj interface{}, info *ModelInfo, removeDele
 }
 
 func (b *FieldsBuilder) SetObjectFields(fromObj interface{}, toObj interface{}, parent *FieldContext, removeDeletedAndSort bool, modifiedIndexes *ModifiedIndexesBuilder, ctx *web.EventContext) (vErr web.ValidationErrors) {
+	// the fields visible conditionally are set after the others, so that their conditions are
+	// checked with the new values of the fields they depend on, the hidden ones are skipped
+	var conditionals []*FieldBuilder
 	for _, f := range b.fields {
-		info := parent.ModelInfo
-		if info != nil {
-			if info.Verifier().Do(PermCreate).ObjectOn(toObj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil && info.Verifier().Do(PermUpdate).ObjectOn(toObj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil {
-				continue
-			}
+		if f.visibleWhen != nil {
+			conditionals = append(conditionals, f)
+			continue
 		}
-
-		if f.nestedFieldsBuilder != nil {
-			formKey := f.name
-			if parent != nil && parent.FormKey != "" {
-				formKey = fmt.Sprintf("%s.%s", parent.FormKey, f.name)
-			}
-			switch f.rt.Kind() {
-			case reflect.Slice:
-				b.setWithChildFromObjs(fromObj, formKey, f, info, modifiedIndexes, toObj, removeDeletedAndSort, ctx)
-				b.setToObjNilOrDelete(toObj, formKey, f, modifiedIndexes, removeDeletedAndSort)
-				continue
-			default:
-				pf := &FieldContext{
-					ModelInfo: info,
-					FormKey:   formKey,
-				}
-				rt := reflectutils.GetType(toObj, f.name)
-				childFromObj := reflectutils.MustGet(fromObj, f.name)
-				if childFromObj == nil {
-					childFromObj = reflect.New(rt.Elem()).Interface()
-				}
-				childToObj := reflectutils.MustGet(toObj, f.name)
-				if childToObj == nil {
-					childToObj = reflect.New(rt.Elem()).Interface()
-				}
-				if rt.Kind() == reflect.Struct {
-					prv := reflect.New(rt)
-					prv.Elem().Set(reflect.ValueOf(childToObj))
-					childToObj = prv.Interface()
-				}
-				f.nestedFieldsBuilder.SetObjectFields(childFromObj, childToObj, pf, removeDeletedAndSort, modifiedIndexes, ctx)
-				if err := reflectutils.Set(toObj, f.name, childToObj); err != nil {
-					panic(err)
-				}
-				continue
-			}
+		b.setObjectField(f, fromObj, toObj, parent, removeDeletedAndSort, modifiedIndexes, ctx, &vErr)
+	}
+	for _, f := range conditionals {
+		if !f.visibleWhen.match(toObj) {
+			continue
 		}
+		b.setObjectField(f, fromObj, toObj, parent, removeDeletedAndSort, modifiedIndexes, ctx, &vErr)
+	}
+	return
+}
 
-		val, err1 := reflectutils.Get(fromObj, f.name)
-		if err1 == nil {
-			reflectutils.Set(toObj, f.name, val)
+func (b *FieldsBuilder) setObjectField(f *FieldBuilder, fromObj interface{}, toObj interface{}, parent *FieldContext, removeDeletedAndSort bool, modifiedIndexes *ModifiedIndexesBuilder, ctx *web.EventContext, vErr *web.ValidationErrors) {
+	info := parent.ModelInfo
+	if info != nil {
+		if info.Verifier().Do(PermCreate).ObjectOn(toObj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil && info.Verifier().Do(PermUpdate).ObjectOn(toObj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil {
+			return
+		}
+		// the setter is skipped so that the field can't be changed by a crafted form
+		if !info.FieldWritable(ctx.R, f.name) {
+			return
 		}
-		keyPath := f.name
+	}
+
+	if f.nestedFieldsBuilder != nil {
+		formKey := f.name
 		if parent != nil && parent.FormKey != "" {
-			keyPath = fmt.Sprintf("%s.%s", parent.FormKey, f.name)
+			formKey = fmt.Sprintf("%s.%s", parent.FormKey, f.name)
 		}
-		err1 = f.lazySetterFunc()(toObj, &FieldContext{
-			ModelInfo: info,
-			FormKey:   keyPath,
-			Name:      f.name,
-			Label:     b.getLabel(f.NameLabel),
-		}, ctx)
-		if err1 != nil {
-			if web.IsValidationGlobalError(err1) {
-				vErr.GlobalError(err1.Error())
-			} else {
-				vErr.FieldError(f.name, err1.Error())
+		switch f.rt.Kind() {
+		case reflect.Slice:
+			b.setWithChildFromObjs(fromObj, formKey, f, info, modifiedIndexes, toObj, removeDeletedAndSort, ctx)
+			b.setToObjNilOrDelete(toObj, formKey, f, modifiedIndexes, removeDeletedAndSort)
+			return
+		default:
+			pf := &FieldContext{
+				ModelInfo: info,
+				FormKey:   formKey,
+			}
+			rt := reflectutils.GetType(toObj, f.name)
+			childFromObj := reflectutils.MustGet(fromObj, f.name)
+			if childFromObj == nil {
+				childFromObj = reflect.New(rt.Elem()).Interface()
+			}
+			childToObj := reflectutils.MustGet(toObj, f.name)
+			if childToObj == nil {
+				childToObj = reflect.New(rt.Elem()).Interface()
+			}
+			if rt.Kind() == reflect.Struct {
+				prv := reflect.New(rt)
+				prv.Elem().Set(reflect.ValueOf(childToObj))
+				childToObj = prv.Interface()
+			}
+			f.nestedFieldsBuilder.SetObjectFields(childFromObj, childToObj, pf, removeDeletedAndSort, modifiedIndexes, ctx)
+			if err := reflectutils.Set(toObj, f.name, childToObj); err != nil {
+				panic(err)
 			}
+			return
+		}
+	}
+
+	val, err1 := reflectutils.Get(fromObj, f.name)
+	if err1 == nil {
+		reflectutils.Set(toObj, f.name, val)
+	}
+	keyPath := f.name
+	if parent != nil && parent.FormKey != "" {
+		keyPath = fmt.Sprintf("%s.%s", parent.FormKey, f.name)
+	}
+	err1 = f.lazySetterFunc()(toObj, &FieldContext{
+		ModelInfo: info,
+		FormKey:   keyPath,
+		Name:      f.name,
+		Label:     b.getLabel(f.NameLabel),
+	}, ctx)
+	if err1 != nil {
+		if web.IsValidationGlobalError(err1) {
+			vErr.GlobalError(err1.Error())
+		} else {
+			vErr.FieldError(f.name, err1.Error())
 		}
 	}
-	return
 }
 
 func (b *FieldsBuilder) setToObjNilOrDelete(toObj interface{}, formKey string, f *FieldBuilder, modifiedIndexes *ModifiedIndexesBuilder, removeDeletedAndSort bool) {
@@ -600,7 +631,13 @@ func (b *FieldsBuilder) getFieldOrDefault(name string) (r *FieldBuilder) {
 			fType = reflect.TypeOf("")
 		}
 
-		ft := b.defaults.fieldTypeByTypeOrCreate(fType)
+		ft := b.defaults.fieldTypeByType(fType)
+		if ft == nil && b.defaults.many2manyType != nil && many2manyModelType(b.model, name) != nil {
+			ft = b.defaults.many2manyType
+		}
+		if ft == nil {
+			ft = b.defaults.fieldTypeByTypeOrCreate(fType)
+		}
 		r.ComponentFunc(ft.compFunc)
 		if r.setterFunc == nil {
 			r.SetterFunc(ft.setterFunc)
@@ -837,10 +874,19 @@ func (b *FieldsBuilder) toComponentWithFormValueKey(info *ModelInfo, obj interfa
 }
 
 func (b *FieldsBuilder) fieldToComponentWithFormValueKey(info *ModelInfo, obj interface{}, parentFormValueKey string, ctx *web.EventContext, name string, edit bool, vErr *web.ValidationErrors) h.HTMLComponent {
-	f := b.getFieldOrDefault(name)
-	if info != nil && info.Verifier().Do(PermGet).ObjectOn(obj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil {
+	f, field := b.fieldContext(info, obj, parentFormValueKey, ctx, name, edit, vErr)
+	if field == nil {
 		return nil
 	}
+	return f.withDependencies(obj, field, parentFormValueKey, f.lazyCompFunc()(obj, field, ctx), ctx)
+}
+
+// fieldContext returns nil FieldContext if the field can't be read by the request
+func (b *FieldsBuilder) fieldContext(info *ModelInfo, obj interface{}, parentFormValueKey string, ctx *web.EventContext, name string, edit bool, vErr *web.ValidationErrors) (*FieldBuilder, *FieldContext) {
+	f := b.getFieldOrDefault(name)
+	if info != nil && (info.Verifier().Do(PermGet).ObjectOn(obj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil || !info.FieldReadable(ctx.R, f.name)) {
+		return f, nil
+	}
 
 	label := b.getLabel(f.NameLabel)
 	if info != nil {
@@ -859,17 +905,25 @@ func (b *FieldsBuilder) fieldToComponentWithFormValueKey(info *ModelInfo, obj in
 		} else {
 			disabled = info.Verifier().Do(PermCreate).ObjectOn(obj).SnakeOn("f_"+f.name).WithReq(ctx.R).IsAllowed() != nil
 		}
+		disabled = disabled || !info.FieldWritable(ctx.R, f.name)
 	}
-	return f.lazyCompFunc()(obj, &FieldContext{
+
+	// the errors of the field validators are keyed by the form key
+	errs := vErr.GetFieldErrors(f.name)
+	if contextKeyPath != f.name {
+		errs = append(errs, vErr.GetFieldErrors(contextKeyPath)...)
+	}
+	return f, &FieldContext{
 		ModelInfo:           info,
 		Name:                f.name,
 		FormKey:             contextKeyPath,
 		Label:               label,
-		Errors:              vErr.GetFieldErrors(f.name),
+		Errors:              errs,
 		NestedFieldsBuilder: f.nestedFieldsBuilder,
 		Context:             f.context,
 		Disabled:            disabled,
-	}, ctx)
+		Validators:          f.activeValidators(obj),
+	}
 }
 
 type RowFunc func(obj interface{}, formKey string, content h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent
@@ -64,6 +64,7 @@ var timeVals = []interface{}{
 type FieldDefaults struct {
 	mode             FieldMode
 	fieldTypes       []*FieldDefaultBuilder
+	many2manyType    *FieldDefaultBuilder
 	excludesPatterns []string
 }
 
@@ -79,6 +80,15 @@ func (b *FieldDefaults) FieldType(v interface{}) (r *FieldDefaultBuilder) {
 	return b.fieldTypeByTypeOrCreate(reflect.TypeOf(v))
 }
 
+// Many2ManyFieldType is the field type of the []*T fields of the gorm many2many associations,
+// which are rendered as the association picker in the editing form by default.
+func (b *FieldDefaults) Many2ManyFieldType() (r *FieldDefaultBuilder) {
+	if b.many2manyType == nil {
+		b.many2manyType = NewFieldDefault(nil)
+	}
+	return b.many2manyType
+}
+
 func (b *FieldDefaults) Exclude(patterns ...string) (r *FieldDefaults) {
 	b.excludesPatterns = patterns
 	return b
@@ -109,6 +119,9 @@ func (b *FieldDefaults) inspectFieldsAndCollectName(val interface{}, collectType
 		f := t.Field(i)
 
 		ft := b.fieldTypeByType(f.Type)
+		if ft == nil && b.many2manyType != nil && isMany2ManyField(f) {
+			ft = b.many2manyType
+		}
 
 		if !hasMatched(b.excludesPatterns, f.Name) && ft != nil {
 			r.Field(f.Name).
@@ -183,12 +196,19 @@ func cfCheckbox(obj interface{}, field *FieldContext, ctx *web.EventContext) h.H
 }
 
 func cfNumber(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
-	return vuetifyx.VXField().
+	comp := vuetifyx.VXField().
 		Type("number").
 		Attr(web.VField(field.FormKey, fmt.Sprint(reflectutils.MustGet(obj, field.Name)))...).
-		Label(field.Label).
+		Label(fieldLabelWithHints(field)).
 		ErrorMessages(field.Errors...).
 		Disabled(field.Disabled)
+	if minValue, ok := field.ValidatorParam(ValidatorRuleMin); ok {
+		comp.Attr("min", minValue)
+	}
+	if maxValue, ok := field.ValidatorParam(ValidatorRuleMax); ok {
+		comp.Attr("max", maxValue)
+	}
+	return comp
 }
 
 func cfTime(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
@@ -205,7 +225,7 @@ func cfTime(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLC
 		}
 	}
 	return vuetifyx.VXDateTimePicker().
-		Label(field.Label).
+		Label(fieldLabelWithHints(field)).
 		Attr(web.VField(field.FormKey, val)...).
 		Value(val).
 		TimePickerProps(vuetifyx.TimePickerProps{
@@ -222,9 +242,12 @@ func cfTimeSetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (
 	if v == "" {
 		return reflectutils.Set(obj, field.Name, nil)
 	}
+	// the time picker posts the local time, and the REST API posts RFC3339 like its output
 	t, err := time.ParseInLocation("2006-01-02 15:04", v, time.Local)
 	if err != nil {
-		return err
+		if t, err = time.Parse(time.RFC3339, v); err != nil {
+			return err
+		}
 	}
 	return reflectutils.Set(obj, field.Name, t)
 }
@@ -234,15 +257,27 @@ func cfTextField(obj interface{}, field *FieldContext, ctx *web.EventContext) h.
 }
 
 func TextField(obj interface{}, field *FieldContext, ctx *web.EventContext) *vuetifyx.VXFieldBuilder {
-	return vuetifyx.VXField().Label(field.Label).
+	comp := vuetifyx.VXField().Label(fieldLabelWithHints(field)).
 		Attr(web.VField(field.FormKey, fmt.Sprint(reflectutils.MustGet(obj, field.Name)))...).
 		ErrorMessages(field.Errors...).
 		Disabled(field.Disabled)
+	if maxLength, ok := field.ValidatorParam(ValidatorRuleMax); ok {
+		comp.Attr("counter", maxLength)
+	}
+	return comp
+}
+
+// fieldLabelWithHints marks the label of the required field
+func fieldLabelWithHints(field *FieldContext) string {
+	if field.Label != "" && field.Required() {
+		return field.Label + " *"
+	}
+	return field.Label
 }
 
 func SelectField(obj interface{}, field *FieldContext, ctx *web.EventContext) *vuetifyx.VXSelectBuilder {
 	return vuetifyx.VXSelect().
-		Label(field.Label).
+		Label(fieldLabelWithHints(field)).
 		Attr(web.VField(field.FormKey, fmt.Sprint(reflectutils.MustGet(obj, field.Name)))...).
 		ErrorMessages(field.Errors...)
 }
@@ -323,5 +358,9 @@ func (b *FieldDefaults) builtInFieldTypes() {
 			SetterFunc(cfTimeSetter)
 	}
 
+	b.Many2ManyFieldType().
+		ComponentFunc(AssociationPicker).
+		SetterFunc(AssociationPickerSetter)
+
 	b.Exclude("ID")
 }
@@ -0,0 +1,79 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type permEmployee struct {
+	ID     uint
+	Name   string
+	Salary int
+}
+
+func TestFieldPermissions(t *testing.T) {
+	op := &memoryTestOperator[permEmployee]{records: []*permEmployee{{ID: 1, Name: "Alice", Salary: 1000}}}
+	pb := New().DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("sales").WhoAre(perm.Denied).ToDo(PermUpdate).On(":presets:fields:perm_employees:salary:"),
+		perm.PolicyFor("guest").WhoAre(perm.Denied).ToDo(PermGet, PermUpdate).On(":presets:fields:perm_employees:salary:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&permEmployee{})
+	mb.Listing("ID", "Name", "Salary")
+	mb.Editing("Name", "Salary")
+
+	request := func(role string) *web.EventContext {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("role", role)
+		return rowContext(&web.EventContext{R: r, W: httptest.NewRecorder()}, url.Values{"Name": {"Alicia"}, "Salary": {"2000"}})
+	}
+
+	info := mb.Info()
+	for _, c := range []struct {
+		role     string
+		readable bool
+		writable bool
+	}{
+		{"admin", true, true},
+		{"sales", true, false},
+		{"guest", false, false},
+	} {
+		evCtx := request(c.role)
+		assert.Equal(t, c.readable, info.FieldReadable(evCtx.R, "Salary"), c.role)
+		assert.Equal(t, c.writable, info.FieldWritable(evCtx.R, "Salary"), c.role)
+
+		obj := &permEmployee{Name: "Alice", Salary: 1000}
+		vErr := mb.Editing().RunSetterFunc(evCtx, false, obj)
+		require.False(t, vErr.HaveErrors())
+		assert.Equal(t, "Alicia", obj.Name, c.role)
+		if c.writable {
+			assert.Equal(t, 2000, obj.Salary, c.role)
+		} else {
+			assert.Equal(t, 1000, obj.Salary, c.role)
+		}
+
+		ctx := web.WrapEventContext(context.Background(), evCtx)
+		_, columns, err := (&ListingCompo{lb: mb.Listing()}).getColumns(ctx)
+		require.NoError(t, err)
+		var names []string
+		for _, col := range columns {
+			names = append(names, col.Name)
+		}
+		assert.Contains(t, names, "Name", c.role)
+		if c.readable {
+			assert.Contains(t, names, "Salary", c.role)
+		} else {
+			assert.NotContains(t, names, "Salary", c.role)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+const (
+	GlobalSearchTimeoutDefault  = 3 * time.Second
+	GlobalSearchPerModelDefault = 5
+
+	ParamGlobalSearchKeyword = "global_search_keyword"
+
+	globalSearchResultsPortalName = "presets_GlobalSearchResultsPortal"
+)
+
+// GlobalSearchOff hides the search box in the layout which searches the keyword in all the models
+func (b *Builder) GlobalSearchOff(v bool) (r *Builder) {
+	b.globalSearchOff = v
+	return b
+}
+
+// GlobalSearchTimeout is how long the search of each model can take, the models timed out are skipped
+func (b *Builder) GlobalSearchTimeout(v time.Duration) (r *Builder) {
+	b.globalSearchTimeout = v
+	return b
+}
+
+// GlobalSearchPerModel is the max number of the records shown for each model
+func (b *Builder) GlobalSearchPerModel(v int) (r *Builder) {
+	b.globalSearchPerModel = v
+	return b
+}
+
+type globalSearchResult struct {
+	mb    *ModelBuilder
+	nodes []any
+	err   error
+}
+
+// globalSearchModels are the models searched by the global search, which are listed in the menu,
+// have search columns and can be listed by the request
+func (b *Builder) globalSearchModels(ctx *web.EventContext) (mbs []*ModelBuilder) {
+	for _, mb := range b.models {
+		lb := mb.listing
+		if mb.singleton || mb.notInMenu || lb.keywordSearchOff || len(lb.searchColumns) == 0 || lb.Searcher == nil {
+			continue
+		}
+		if mb.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+			continue
+		}
+		mbs = append(mbs, mb)
+	}
+	return
+}
+
+// globalSearch searches the keyword in the models concurrently, the results are in the order of the models
+func (b *Builder) globalSearch(ctx *web.EventContext, keyword string) []*globalSearchResult {
+	mbs := b.globalSearchModels(ctx)
+	results := make([]*globalSearchResult, len(mbs))
+	var wg sync.WaitGroup
+	for i, mb := range mbs {
+		results[i] = &globalSearchResult{mb: mb}
+		wg.Add(1)
+		go func(result *globalSearchResult) {
+			defer wg.Done()
+			result.nodes, result.err = b.globalSearchModel(ctx, result.mb, keyword)
+		}(results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+func (b *Builder) globalSearchModel(ctx *web.EventContext, mb *ModelBuilder, keyword string) (nodes []any, err error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx.R.Context(), b.globalSearchTimeout)
+	defer cancel()
+
+	// the searchers may change the event context, so every model has its own one
+	evCtx := *ctx
+	evCtx.R = ctx.R.WithContext(timeoutCtx)
+
+	done := make(chan *globalSearchResult, 1)
+	go func() {
+		result := &globalSearchResult{mb: mb}
+		defer func() {
+			if r := recover(); r != nil {
+				result.err = fmt.Errorf("%v", r)
+			}
+			done <- result
+		}()
+		params := &SearchParams{
+			Model:          mb.NewModel(),
+			PageURL:        ctx.R.URL,
+			KeywordColumns: mb.listing.searchColumns,
+			Keyword:        keyword,
+			SQLConditions:  mb.listing.conditions,
+			PerPage:        int64(b.globalSearchPerModel),
+			Page:           1,
+		}
+		if result.err = (&ListingCompo{lb: mb.listing}).applySearchIndex(&evCtx, params); result.err != nil {
+			return
+		}
+		sr, err := mb.listing.Searcher(&evCtx, params)
+		if err != nil {
+			result.err = err
+			return
+		}
+		reflectutils.ForEach(sr.Nodes, func(obj interface{}) {
+			result.nodes = append(result.nodes, obj)
+		})
+	}()
+
+	select {
+	case result := <-done:
+		return result.nodes, result.err
+	case <-timeoutCtx.Done():
+		return nil, timeoutCtx.Err()
+	}
+}
+
+// globalSearchTitle is the page title of the record, or the first search column having a value
+func globalSearchTitle(mb *ModelBuilder, obj any, id string) string {
+	if _, ok := obj.(pageTitle); ok {
+		return getPageTitle(obj, id)
+	}
+	for _, col := range mb.listing.searchColumns {
+		v, err := reflectutils.Get(obj, col)
+		if err != nil {
+			continue
+		}
+		if s := strings.TrimSpace(fmt.Sprint(reflect.Indirect(reflect.ValueOf(v)))); s != "" {
+			return s
+		}
+	}
+	return id
+}
+
+// globalSearchOpenEvent opens the detailing page of the record, or its editing drawer if there is no detailing
+func globalSearchOpenEvent(mb *ModelBuilder, id string) string {
+	if mb.hasDetailing {
+		return web.Plaid().PushStateURL(mb.Info().DetailingHref(id)).Go()
+	}
+	return web.Plaid().URL(mb.Info().ListingHref()).EventFunc(actions.Edit).Query(ParamID, id).Go()
+}
+
+func (b *Builder) globalSearchResults(ctx *web.EventContext, keyword string, results []*globalSearchResult) h.HTMLComponent {
+	if keyword == "" {
+		return nil
+	}
+	msgr := MustGetMessages(ctx.R)
+	list := VList().Density(DensityCompact)
+	var found int
+	for _, result := range results {
+		label := result.mb.Info().LabelName(ctx, false)
+		if result.err != nil {
+			b.logger.Sugar().Warnf("global search %s: %v", result.mb.uriName, result.err)
+			if errors.Is(result.err, context.DeadlineExceeded) {
+				list.AppendChildren(
+					VListSubheader(h.Text(label)),
+					VListItem(VListItemSubtitle(h.Text(msgr.GlobalSearchTimedOut))),
+				)
+			}
+			continue
+		}
+		if len(result.nodes) == 0 {
+			continue
+		}
+		list.AppendChildren(VListSubheader(h.Text(label)))
+		for _, obj := range result.nodes {
+			id := ObjectID(obj)
+			list.AppendChildren(
+				VListItem(
+					VListItemTitle(h.Text(globalSearchTitle(result.mb, obj, id))),
+				).Attr("@click", "vars.presetsGlobalSearch = false;"+globalSearchOpenEvent(result.mb, id)),
+			)
+			found++
+		}
+	}
+	if found == 0 {
+		list.AppendChildren(VListItem(VListItemSubtitle(h.Text(msgr.GlobalSearchNoResults))))
+	}
+	return list
+}
+
+func (b *Builder) globalSearchEvent(ctx *web.EventContext) (r web.EventResponse, err error) {
+	keyword := strings.TrimSpace(ctx.R.FormValue(ParamGlobalSearchKeyword))
+	var results []*globalSearchResult
+	if keyword != "" {
+		results = b.globalSearch(ctx, keyword)
+	}
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: globalSearchResultsPortalName,
+		Body: b.globalSearchResults(ctx, keyword, results),
+	})
+	return
+}
+
+// globalSearchBox opens the search dialog when it's clicked or Ctrl/Cmd+K is pressed
+func (b *Builder) globalSearchBox(ctx *web.EventContext) h.HTMLComponent {
+	if b.globalSearchOff {
+		return nil
+	}
+	msgr := MustGetMessages(ctx.R)
+	searchEvent := web.Plaid().
+		EventFunc(actions.GlobalSearch).
+		Query(ParamGlobalSearchKeyword, web.Var("locals.keyword")).
+		Go()
+	return web.Scope(
+		VTextField().
+			Placeholder(msgr.GlobalSearch).
+			PrependInnerIcon("mdi-magnify").
+			Variant(VariantOutlined).
+			Density(DensityCompact).
+			HideDetails(true).
+			Readonly(true).
+			Attr("@click", "vars.presetsGlobalSearch = true").
+			Attr("v-on-mounted", `({window}) => {
+				locals.onKeydown = (e) => {
+					if ((e.ctrlKey || e.metaKey) && e.key === 'k') {
+						e.preventDefault();
+						vars.presetsGlobalSearch = true;
+					}
+				};
+				window.addEventListener('keydown', locals.onKeydown);
+			}`).
+			Attr("v-on-unmounted", `({window}) => window.removeEventListener('keydown', locals.onKeydown)`),
+		VDialog(
+			VCard(
+				VCardText(
+					VTextField().
+						Attr("v-model", "locals.keyword").
+						Placeholder(msgr.GlobalSearchPlaceholder).
+						PrependInnerIcon("mdi-magnify").
+						Variant(VariantOutlined).
+						Density(DensityCompact).
+						HideDetails(true).
+						Autofocus(true).
+						Clearable(true).
+						Attr("@update:model-value", fmt.Sprintf(`clearTimeout(locals.timer); locals.timer = setTimeout(() => { %s }, 300)`, searchEvent)),
+					web.Portal().Name(globalSearchResultsPortalName),
+				),
+			),
+		).Attr("v-model", "vars.presetsGlobalSearch").Width(640).Scrollable(true),
+	).VSlot("{ locals }").Init(`{ keyword: "", timer: null, onKeydown: null }`)
+}
@@ -0,0 +1,78 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type searchArticle struct {
+	ID    uint
+	Title string
+}
+
+type slowArticle struct {
+	searchArticle
+}
+
+type secretArticle struct {
+	searchArticle
+}
+
+func TestGlobalSearch(t *testing.T) {
+	op := &memoryTestOperator[searchArticle]{records: []*searchArticle{
+		{ID: 1, Title: "Go generics"},
+		{ID: 2, Title: "Go modules"},
+		{ID: 3, Title: "Rust traits"},
+	}}
+	pb := New().DataOperator(op).GlobalSearchTimeout(50 * time.Millisecond).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Denied).ToDo(PermList).On("*:presets:secret_articles:*"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{"editor"}
+	}))
+	mb := pb.Model(&searchArticle{})
+	mb.Listing().SearchColumns("Title")
+	pb.Model(&slowArticle{}).Listing().SearchColumns("Title").SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		<-ctx.R.Context().Done()
+		return nil, ctx.R.Context().Err()
+	})
+	pb.Model(&secretArticle{}).Listing().SearchColumns("Title")
+	pb.Model(&searchArticle{}).URIName("hidden-articles").InMenu(false)
+
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	var uris []string
+	for _, m := range pb.globalSearchModels(ctx) {
+		uris = append(uris, m.uriName)
+	}
+	assert.Equal(t, []string{"search-articles", "slow-articles"}, uris)
+
+	start := time.Now()
+	results := pb.globalSearch(ctx, "Go")
+	assert.Less(t, time.Since(start), time.Second, "the models are searched concurrently with the timeout")
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].err)
+	assert.Len(t, results[0].nodes, 2)
+	assert.ErrorIs(t, results[1].err, context.DeadlineExceeded)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{ParamGlobalSearchKeyword: {"modules"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	er, err := pb.globalSearchEvent(&web.EventContext{R: r, W: httptest.NewRecorder()})
+	require.NoError(t, err)
+	require.Len(t, er.UpdatePortals, 1)
+	body := h.MustString(er.UpdatePortals[0].Body, context.Background())
+	assert.Contains(t, body, "Go modules")
+	assert.NotContains(t, body, "Go generics")
+	assert.Contains(t, body, mb.Info().ListingHref())
+	assert.Contains(t, body, Messages_en_US.GlobalSearchTimedOut)
+}
@@ -0,0 +1,91 @@
+package gorm2op
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const DraftsTableDefault = "presets_drafts"
+
+type draftRecord struct {
+	UserID     string `gorm:"primaryKey;size:191"`
+	Model      string `gorm:"primaryKey;size:191"`
+	RecordID   string `gorm:"primaryKey;size:191"`
+	Section    string `gorm:"primaryKey;size:191"`
+	FormValues string
+	SavedAt    time.Time
+}
+
+type DraftStoreBuilder struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ presets.DraftStore = (*DraftStoreBuilder)(nil)
+
+// DraftStore keeps the drafts of the forms in a table, the form values are stored as JSON.
+func DraftStore(db *gorm.DB) (r *DraftStoreBuilder) {
+	return &DraftStoreBuilder{db: db, table: DraftsTableDefault}
+}
+
+func (b *DraftStoreBuilder) Table(v string) (r *DraftStoreBuilder) {
+	if !identifierReg.MatchString(v) {
+		panic(fmt.Sprintf("invalid table name %q", v))
+	}
+	b.table = v
+	return b
+}
+
+func (b *DraftStoreBuilder) AutoMigrate() error {
+	return b.db.Table(b.table).AutoMigrate(&draftRecord{})
+}
+
+func (b *DraftStoreBuilder) where(ctx *web.EventContext, key presets.DraftKey) *gorm.DB {
+	return txOr(ctx, b.db).Table(b.table).
+		Where("user_id = ? AND model = ? AND record_id = ? AND section = ?", key.User, key.Model, key.RecordID, key.Section)
+}
+
+func (b *DraftStoreBuilder) Get(ctx *web.EventContext, key presets.DraftKey) (*presets.Draft, error) {
+	var rec draftRecord
+	if err := b.where(ctx, key).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var values url.Values
+	if err := json.Unmarshal([]byte(rec.FormValues), &values); err != nil {
+		return nil, err
+	}
+	return &presets.Draft{Values: values, SavedAt: rec.SavedAt}, nil
+}
+
+func (b *DraftStoreBuilder) Save(ctx *web.EventContext, key presets.DraftKey, values url.Values) error {
+	bs, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return txOr(ctx, b.db).Table(b.table).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "model"}, {Name: "record_id"}, {Name: "section"}},
+		DoUpdates: clause.AssignmentColumns([]string{"form_values", "saved_at"}),
+	}).Create(&draftRecord{
+		UserID:     key.User,
+		Model:      key.Model,
+		RecordID:   key.RecordID,
+		Section:    key.Section,
+		FormValues: string(bs),
+		SavedAt:    time.Now(),
+	}).Error
+}
+
+func (b *DraftStoreBuilder) Delete(ctx *web.EventContext, key presets.DraftKey) error {
+	return b.where(ctx, key).Delete(&draftRecord{}).Error
+}
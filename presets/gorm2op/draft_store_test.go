@@ -0,0 +1,58 @@
+package gorm2op
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDraftStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := DraftStore(db)
+	if err := store.AutoMigrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/articles", nil)}
+	key := presets.DraftKey{User: "1", Model: "articles", RecordID: "2"}
+
+	draft, err := store.Get(ctx, key)
+	if err != nil || draft != nil {
+		t.Fatalf("expected no draft, got %v, %v", draft, err)
+	}
+
+	for _, title := range []string{"Draft", "Draft 2"} {
+		if err := store.Save(ctx, key, url.Values{"Title": {title}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Save(ctx, presets.DraftKey{User: "2", Model: "articles", RecordID: "2"}, url.Values{"Title": {"Other"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	draft, err = store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draft == nil || draft.Values.Get("Title") != "Draft 2" || draft.SavedAt.IsZero() {
+		t.Fatalf("unexpected draft %#v", draft)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if draft, _ = store.Get(ctx, key); draft != nil {
+		t.Fatalf("expected the draft to be deleted, got %#v", draft)
+	}
+	if draft, _ = store.Get(ctx, presets.DraftKey{User: "2", Model: "articles", RecordID: "2"}); draft == nil {
+		t.Fatal("expected the draft of the other user to be kept")
+	}
+}
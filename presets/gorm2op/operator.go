@@ -1,6 +1,7 @@
 package gorm2op
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -15,6 +16,7 @@ import (
 	"github.com/theplant/relay/cursor"
 	"github.com/theplant/relay/gormrelay"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var wildcardReg = regexp.MustCompile(`[%_]`)
@@ -24,32 +26,54 @@ func DataOperator(db *gorm.DB) (r *DataOperatorBuilder) {
 	return
 }
 
-type ctxKeyDB struct{}
+type (
+	ctxKeyDB struct{}
+	ctxKeyTx struct{}
+)
 
 type DataOperatorBuilder struct {
 	db *gorm.DB
 }
 
-func (op *DataOperatorBuilder) Search(ctx *web.EventContext, params *presets.SearchParams) (result *presets.SearchResult, err error) {
-	ilike := "ILIKE"
-	if op.db.Dialector.Name() == "sqlite" {
-		ilike = "LIKE"
-	}
+var _ presets.Transactor = (*DataOperatorBuilder)(nil)
 
-	wh := op.db.Model(params.Model)
-	if len(params.KeywordColumns) > 0 && len(params.Keyword) > 0 {
-		var segs []string
-		var args []interface{}
-		for _, c := range params.KeywordColumns {
-			segs = append(segs, fmt.Sprintf("%s %s ?", c, ilike))
-			kw := wildcardReg.ReplaceAllString(params.Keyword, `\$0`)
-			args = append(args, fmt.Sprintf("%%%s%%", kw))
-		}
-		wh = wh.Where(strings.Join(segs, " OR "), args...)
+var _ presets.UniqueChecker = (*DataOperatorBuilder)(nil)
+
+var _ presets.AssociationLoader = (*DataOperatorBuilder)(nil)
+
+// Transaction runs f in a database transaction, the data operations called with
+// the EventContext passed to f will all use the transaction.
+func (op *DataOperatorBuilder) Transaction(ctx *web.EventContext, f func(ctx *web.EventContext) error) error {
+	return op.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		txCtx := *ctx
+		txCtx.R = ctx.R.WithContext(context.WithValue(ctx.R.Context(), ctxKeyTx{}, tx))
+		return f(&txCtx)
+	})
+}
+
+// dbFrom returns the transaction started by Transaction if there is one,
+// the request context is passed to the db so that the callbacks like the tenant scoping can read it.
+func (op *DataOperatorBuilder) dbFrom(ctx *web.EventContext) *gorm.DB {
+	return txOr(ctx, op.db)
+}
+
+func txOr(ctx *web.EventContext, db *gorm.DB) *gorm.DB {
+	if ctx == nil || ctx.R == nil {
+		return db
+	}
+	if tx, ok := ctx.R.Context().Value(ctxKeyTx{}).(*gorm.DB); ok {
+		db = tx
 	}
+	return db.WithContext(ctx.R.Context())
+}
 
-	for _, cond := range params.SQLConditions {
-		wh = wh.Where(strings.Replace(cond.Query, " ILIKE ", " "+ilike+" ", -1), cond.Args...)
+func (op *DataOperatorBuilder) Search(ctx *web.EventContext, params *presets.SearchParams) (result *presets.SearchResult, err error) {
+	wh, err := op.searchWhere(op.dbFrom(ctx), params)
+	if err != nil {
+		return nil, err
+	}
+	if params.RankBy != nil {
+		wh = wh.Order(clause.Expr{SQL: params.RankBy.Query + " DESC", Vars: params.RankBy.Args})
 	}
 
 	var p relay.Pagination[any]
@@ -106,8 +130,76 @@ func (op *DataOperatorBuilder) Search(ctx *web.EventContext, params *presets.Sea
 	}, nil
 }
 
-func (op *DataOperatorBuilder) primarySluggerWhere(obj interface{}, id string) *gorm.DB {
-	wh := op.db.Model(obj)
+// searchWhere applies the keyword, the conditions and the trashed flag of the search params
+func (op *DataOperatorBuilder) searchWhere(db *gorm.DB, params *presets.SearchParams) (*gorm.DB, error) {
+	ilike := "ILIKE"
+	if db.Dialector.Name() == "sqlite" {
+		ilike = "LIKE"
+	}
+
+	wh := db.Model(params.Model)
+	if params.Trashed {
+		column, err := deletedAtColumn(db, params.Model)
+		if err != nil {
+			return nil, err
+		}
+		wh = wh.Unscoped().Where(fmt.Sprintf("%s IS NOT NULL", column))
+	}
+	if len(params.KeywordColumns) > 0 && len(params.Keyword) > 0 {
+		var segs []string
+		var args []interface{}
+		for _, c := range params.KeywordColumns {
+			segs = append(segs, fmt.Sprintf("%s %s ?", c, ilike))
+			kw := wildcardReg.ReplaceAllString(params.Keyword, `\$0`)
+			args = append(args, fmt.Sprintf("%%%s%%", kw))
+		}
+		wh = wh.Where(strings.Join(segs, " OR "), args...)
+	}
+
+	for _, cond := range params.SQLConditions {
+		wh = wh.Where(strings.Replace(cond.Query, " ILIKE ", " "+ilike+" ", -1), cond.Args...)
+	}
+	return wh, nil
+}
+
+// Sum returns the sum of the column of the field of the records matching the search params
+func (op *DataOperatorBuilder) Sum(ctx *web.EventContext, params *presets.SearchParams, field string) (sum float64, err error) {
+	db := op.dbFrom(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(params.Model); err != nil {
+		return
+	}
+	f := stmt.Schema.LookUpField(field)
+	if f == nil {
+		return 0, errors.Errorf("field %s not found", field)
+	}
+	wh, err := op.searchWhere(db, params)
+	if err != nil {
+		return
+	}
+	err = wh.Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", stmt.Quote(f.DBName))).Scan(&sum).Error
+	return
+}
+
+// OrphanCondition matches the records whose column doesn't reference the primary key of a record which isn't deleted
+func (op *DataOperatorBuilder) OrphanCondition(model interface{}, column string, ctx *web.EventContext) (cond *presets.SQLCondition, err error) {
+	db := op.dbFrom(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(model); err != nil {
+		return
+	}
+	pf := stmt.Schema.PrioritizedPrimaryField
+	if pf == nil {
+		return nil, errors.Errorf("%s has no primary key", stmt.Schema.Name)
+	}
+	return &presets.SQLCondition{
+		Query: fmt.Sprintf("%s NOT IN (?)", column),
+		Args:  []interface{}{db.Session(&gorm.Session{NewDB: true}).Model(model).Select(pf.DBName)},
+	}, nil
+}
+
+func (op *DataOperatorBuilder) primarySluggerWhere(db *gorm.DB, obj interface{}, id string) *gorm.DB {
+	wh := db.Model(obj)
 
 	if id == "" {
 		return wh
@@ -126,7 +218,7 @@ func (op *DataOperatorBuilder) primarySluggerWhere(obj interface{}, id string) *
 }
 
 func (op *DataOperatorBuilder) Fetch(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error) {
-	err = op.primarySluggerWhere(obj, id).First(obj).Error
+	err = op.primarySluggerWhere(op.dbFrom(ctx), obj, id).First(obj).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, presets.ErrRecordNotFound
@@ -138,26 +230,199 @@ func (op *DataOperatorBuilder) Fetch(obj interface{}, id string, ctx *web.EventC
 }
 
 func (op *DataOperatorBuilder) Save(obj interface{}, id string, ctx *web.EventContext) (err error) {
+	if names := presets.AssociationsToReplace(ctx); len(names) > 0 {
+		return op.Transaction(ctx, func(ctx *web.EventContext) error {
+			return op.saveAndReplaceAssociations(obj, id, names, ctx)
+		})
+	}
+	return op.save(op.dbFrom(ctx), obj, id, ctx)
+}
+
+// saveAndReplaceAssociations saves obj without the associations of names, then replaces them with the ones set to obj
+func (op *DataOperatorBuilder) saveAndReplaceAssociations(obj interface{}, id string, names []string, ctx *web.EventContext) error {
+	db := op.dbFrom(ctx)
+	if err := op.save(db.Omit(names...).Session(&gorm.Session{}), obj, id, ctx); err != nil {
+		return err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(obj))
+	for _, name := range names {
+		if err := db.Model(obj).Association(name).Replace(rv.FieldByName(name).Interface()); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// LoadAssociation loads the associated records of the field of obj
+func (op *DataOperatorBuilder) LoadAssociation(obj interface{}, field string, ctx *web.EventContext) (err error) {
+	rv := reflect.Indirect(reflect.ValueOf(obj)).FieldByName(field)
+	if !rv.IsValid() {
+		return errors.Errorf("field %s not found", field)
+	}
+	return errors.WithStack(op.dbFrom(ctx).Model(obj).Association(field).Find(rv.Addr().Interface()))
+}
+
+func (op *DataOperatorBuilder) save(db *gorm.DB, obj interface{}, id string, ctx *web.EventContext) (err error) {
 	if id == "" {
-		err = op.db.Create(obj).Error
+		err = db.Create(obj).Error
 		return
 	}
-	err = op.saveOrUpdate(obj, id)
+	if ctx != nil && ctx.R != nil {
+		if ev, ok := presets.ExpectedVersionFromContext(ctx.R.Context(), obj); ok {
+			return op.updateVersioned(db, obj, id, ev)
+		}
+	}
+	err = op.saveOrUpdate(db, obj, id)
 	return
 }
 
-func (op *DataOperatorBuilder) saveOrUpdate(obj interface{}, id string) (err error) {
+// updateVersioned only updates the record if its version column still has the expected value
+func (op *DataOperatorBuilder) updateVersioned(db *gorm.DB, obj interface{}, id string, ev *presets.ExpectedVersion) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(obj); err != nil {
+		return err
+	}
+	column := db.NamingStrategy.ColumnName("", ev.Field)
+	if f := stmt.Schema.LookUpField(ev.Field); f != nil {
+		column = f.DBName
+	}
+
+	result := op.primarySluggerWhere(db, obj, id).
+		Where(fmt.Sprintf("%s = ?", stmt.Quote(column)), ev.Value).
+		Select("*").
+		Updates(obj)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return presets.ErrVersionConflict
+	}
+	return nil
+}
+
+// saveOrUpdate returns presets.ErrRecordNotFound if nothing is saved, like when the record
+// belongs to another tenant, which is out of the scope of the queries but conflicts with the upsert.
+func (op *DataOperatorBuilder) saveOrUpdate(db *gorm.DB, obj interface{}, id string) (err error) {
 	var count int64
-	if op.primarySluggerWhere(obj, id).Count(&count).Error != nil {
+	if op.primarySluggerWhere(db, obj, id).Count(&count).Error != nil {
 		return
 	}
 	if count > 0 {
-		return op.primarySluggerWhere(obj, id).Select("*").Updates(obj).Error
+		result := op.primarySluggerWhere(db, obj, id).Select("*").Updates(obj)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		// some databases don't count the rows which are not changed
+		if err = op.primarySluggerWhere(db, obj, id).Count(&count).Error; err != nil {
+			return
+		}
+		if count == 0 {
+			return presets.ErrRecordNotFound
+		}
+		return nil
+	}
+	result := op.primarySluggerWhere(db, obj, id).Save(obj)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return presets.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// IsUnique checks no other record than obj has the value in the column of the field,
+// the soft deleted records are checked too, since they can be restored.
+func (op *DataOperatorBuilder) IsUnique(obj interface{}, field string, value interface{}, ctx *web.EventContext) (unique bool, err error) {
+	db := op.dbFrom(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(obj); err != nil {
+		return false, errors.WithStack(err)
+	}
+	f := stmt.Schema.LookUpField(field)
+	if f == nil {
+		return false, errors.Errorf("field %s not found in %s", field, stmt.Schema.Name)
+	}
+
+	wh := db.Unscoped().Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Where(clause.Eq{Column: clause.Column{Name: f.DBName}, Value: value})
+	// obj itself is excluded when it's updated
+	rv := reflect.Indirect(reflect.ValueOf(obj))
+	var others []clause.Expression
+	for _, pf := range stmt.Schema.PrimaryFields {
+		if v, zero := pf.ValueOf(db.Statement.Context, rv); !zero {
+			others = append(others, clause.Neq{Column: clause.Column{Name: pf.DBName}, Value: v})
+		}
+	}
+	if len(others) > 0 {
+		wh = wh.Where(clause.Or(others...))
+	}
+
+	var count int64
+	if err = wh.Count(&count).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+	return count == 0, nil
+}
+
+// Trashable reports whether the model has a gorm.DeletedAt field
+func (op *DataOperatorBuilder) Trashable(model interface{}) bool {
+	_, err := deletedAtColumn(op.db, model)
+	return err == nil
+}
+
+// FetchTrashed loads the soft deleted record
+func (op *DataOperatorBuilder) FetchTrashed(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error) {
+	if _, err = op.fetchTrashed(op.dbFrom(ctx), obj, id); err != nil {
+		return
+	}
+	return obj, nil
+}
+
+// Restore clears the deleted at column of the soft deleted record
+func (op *DataOperatorBuilder) Restore(obj interface{}, id string, ctx *web.EventContext) (err error) {
+	db := op.dbFrom(ctx)
+	column, err := op.fetchTrashed(db, obj, id)
+	if err != nil {
+		return
+	}
+	err = op.primarySluggerWhere(db.Unscoped(), obj, id).Update(column, nil).Error
+	return
+}
+
+// Purge deletes the soft deleted record permanently
+func (op *DataOperatorBuilder) Purge(obj interface{}, id string, ctx *web.EventContext) (err error) {
+	db := op.dbFrom(ctx)
+	if _, err = op.fetchTrashed(db, obj, id); err != nil {
+		return
+	}
+	err = op.primarySluggerWhere(db.Unscoped(), obj, id).Delete(obj).Error
+	return
+}
+
+func (op *DataOperatorBuilder) fetchTrashed(db *gorm.DB, obj interface{}, id string) (column string, err error) {
+	column, err = deletedAtColumn(db, obj)
+	if err != nil {
+		return
+	}
+	err = op.primarySluggerWhere(db.Unscoped(), obj, id).Where(fmt.Sprintf("%s IS NOT NULL", column)).First(obj).Error
+	if err == gorm.ErrRecordNotFound {
+		err = presets.ErrRecordNotFound
+	}
+	return
+}
+
+func deletedAtColumn(db *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", err
+	}
+	for _, f := range stmt.Schema.Fields {
+		if f.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			return f.DBName, nil
+		}
 	}
-	return op.primarySluggerWhere(obj, id).Save(obj).Error
+	return "", fmt.Errorf("%s has no gorm.DeletedAt field", stmt.Schema.Name)
 }
 
 func (op *DataOperatorBuilder) Delete(obj interface{}, id string, ctx *web.EventContext) (err error) {
-	err = op.primarySluggerWhere(obj, id).Delete(obj).Error
+	err = op.primarySluggerWhere(op.dbFrom(ctx), obj, id).Delete(obj).Error
 	return
 }
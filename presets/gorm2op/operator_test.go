@@ -0,0 +1,435 @@
+package gorm2op
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type uniqueUser struct {
+	gorm.Model
+	Email string
+}
+
+type uniqueVersionedUser struct {
+	ID      uint   `gorm:"primarykey"`
+	Version string `gorm:"primarykey"`
+	Email   string
+}
+
+func TestIsUnique(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&uniqueUser{}, &uniqueVersionedUser{}); err != nil {
+		t.Fatal(err)
+	}
+	a := &uniqueUser{Email: "a@example.com"}
+	b := &uniqueUser{Email: "b@example.com"}
+	deleted := &uniqueUser{Email: "deleted@example.com"}
+	for _, u := range []*uniqueUser{a, b, deleted} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	op := DataOperator(db)
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil)}
+	for _, c := range []struct {
+		obj    *uniqueUser
+		email  string
+		unique bool
+	}{
+		{&uniqueUser{}, "a@example.com", false},
+		{&uniqueUser{}, "c@example.com", true},
+		{&uniqueUser{}, "deleted@example.com", false},
+		{a, "a@example.com", true},
+		{a, "b@example.com", false},
+	} {
+		unique, err := op.IsUnique(c.obj, "Email", c.email, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if unique != c.unique {
+			t.Errorf("IsUnique(%d, %s) = %v, want %v", c.obj.ID, c.email, unique, c.unique)
+		}
+	}
+
+	if err := db.Create(&uniqueVersionedUser{ID: 1, Version: "v1", Email: "a@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	unique, err := op.IsUnique(&uniqueVersionedUser{ID: 1, Version: "v2"}, "Email", "a@example.com", ctx)
+	if err != nil || unique {
+		t.Errorf("the other version has the email, got %v, %v", unique, err)
+	}
+	unique, err = op.IsUnique(&uniqueVersionedUser{ID: 1, Version: "v1"}, "Email", "a@example.com", ctx)
+	if err != nil || !unique {
+		t.Errorf("the record itself is excluded, got %v, %v", unique, err)
+	}
+
+	if _, err := op.IsUnique(&uniqueUser{}, "Missing", "x", ctx); err == nil {
+		t.Error("expected an error for the unknown field")
+	}
+}
+
+type assocTag struct {
+	ID   uint
+	Name string
+}
+
+type assocPost struct {
+	ID    uint
+	Title string
+	Tags  []*assocTag `gorm:"many2many:assoc_post_tags"`
+}
+
+func TestAssociations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&assocPost{}, &assocTag{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"go", "sql", "web"} {
+		if err := db.Create(&assocTag{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	op := DataOperator(db)
+	pb := presets.New().DataOperator(op)
+	mb := pb.Model(&assocPost{})
+	pb.Model(&assocTag{})
+	eb := mb.Editing("Title", "Tags")
+
+	save := func(id string, form url.Values) *assocPost {
+		t.Helper()
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		r.MultipartForm = &multipart.Form{Value: form}
+		ctx := &web.EventContext{R: r}
+		obj, vErr := eb.FetchAndUnmarshal(id, false, ctx)
+		if vErr.HaveErrors() {
+			t.Fatal(vErr.Error())
+		}
+		if err := op.Save(obj, id, ctx); err != nil {
+			t.Fatal(err)
+		}
+		return obj.(*assocPost)
+	}
+	tagNames := func(id uint) (names []string) {
+		t.Helper()
+		post := &assocPost{ID: id}
+		if err := op.LoadAssociation(post, "Tags", nil); err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range post.Tags {
+			names = append(names, tag.Name)
+		}
+		return
+	}
+
+	post := save("", url.Values{"Title": {"a"}, "Tags": {"1", "2"}, "presets_association_picker_Tags": {"1"}})
+	if got := tagNames(post.ID); strings.Join(got, ",") != "go,sql" {
+		t.Errorf("got tags %v after creating", got)
+	}
+
+	id := fmt.Sprint(post.ID)
+	save(id, url.Values{"Title": {"b"}, "Tags": {"3"}, "presets_association_picker_Tags": {"1"}})
+	if got := tagNames(post.ID); strings.Join(got, ",") != "web" {
+		t.Errorf("got tags %v after replacing", got)
+	}
+
+	save(id, url.Values{"Title": {"c"}})
+	if got := tagNames(post.ID); strings.Join(got, ",") != "web" {
+		t.Errorf("the associations are kept if the picker isn't in the form, got %v", got)
+	}
+
+	save(id, url.Values{"Title": {"d"}, "presets_association_picker_Tags": {"1"}})
+	if got := tagNames(post.ID); len(got) != 0 {
+		t.Errorf("got tags %v after clearing", got)
+	}
+	var saved assocPost
+	if err := db.First(&saved, post.ID).Error; err != nil || saved.Title != "d" {
+		t.Errorf("the record is saved with the associations, got %q, %v", saved.Title, err)
+	}
+	var count int64
+	db.Model(&assocTag{}).Count(&count)
+	if count != 3 {
+		t.Errorf("the tags themselves are not changed, got %d", count)
+	}
+}
+
+type dupRule struct {
+	ID          uint
+	PromotionID uint
+	Rule        string
+}
+
+type dupPromotion struct {
+	ID    uint
+	Code  string
+	Rules []*dupRule  `gorm:"foreignKey:PromotionID"`
+	Tags  []*assocTag `gorm:"many2many:dup_promotion_tags"`
+}
+
+func TestDuplicate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&dupPromotion{}, &dupRule{}, &assocTag{}); err != nil {
+		t.Fatal(err)
+	}
+	origin := &dupPromotion{
+		Code:  "SUMMER",
+		Rules: []*dupRule{{Rule: "min 100"}, {Rule: "first order"}},
+		Tags:  []*assocTag{{Name: "sale"}},
+	}
+	if err := db.Create(origin).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	op := DataOperator(db)
+	pb := presets.New().DataOperator(op)
+	mb := pb.Model(&dupPromotion{})
+	pb.Model(&assocTag{})
+	mb.Duplicate().Associations("Rules", "Tags").ResetFunc(func(obj interface{}, ctx *web.EventContext) error {
+		obj.(*dupPromotion).Code += "-COPY"
+		return nil
+	})
+
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil)}
+	obj, err := mb.Duplicate().Copy(fmt.Sprint(origin.ID), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := op.Save(obj, "", ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	copied := obj.(*dupPromotion)
+	if copied.ID == 0 || copied.ID == origin.ID || copied.Code != "SUMMER-COPY" {
+		t.Fatalf("got copy %d %q", copied.ID, copied.Code)
+	}
+	for _, id := range []uint{origin.ID, copied.ID} {
+		var rules []*dupRule
+		if err := db.Where("promotion_id = ?", id).Find(&rules).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(rules) != 2 {
+			t.Errorf("promotion %d has %d rules", id, len(rules))
+		}
+		p := &dupPromotion{ID: id}
+		if err := op.LoadAssociation(p, "Tags", nil); err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Tags) != 1 || p.Tags[0].ID != origin.Tags[0].ID {
+			t.Errorf("promotion %d has tags %v", id, p.Tags)
+		}
+	}
+	var count int64
+	db.Model(&assocTag{}).Count(&count)
+	if count != 1 {
+		t.Errorf("the tags of many2many are shared, got %d", count)
+	}
+}
+
+type versionedNote struct {
+	ID      uint
+	Body    string
+	Version int
+}
+
+func TestSaveExpectedVersion(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&versionedNote{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&versionedNote{ID: 1, Body: "a", Version: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	op := DataOperator(db)
+	save := func(obj *versionedNote, expected int) error {
+		r := httptest.NewRequest("POST", "/", nil)
+		r = r.WithContext(presets.WithExpectedVersion(r.Context(), obj, &presets.ExpectedVersion{Field: "Version", Value: expected}))
+		return op.Save(obj, "1", &web.EventContext{R: r})
+	}
+
+	if err := save(&versionedNote{ID: 1, Body: "b", Version: 2}, 1); err != nil {
+		t.Fatal(err)
+	}
+	// saved by someone else since version 1 was fetched
+	if err := save(&versionedNote{ID: 1, Body: "c", Version: 2}, 1); !errors.Is(err, presets.ErrVersionConflict) {
+		t.Fatalf("expected the version conflict, got %v", err)
+	}
+	var note versionedNote
+	if err := db.First(&note, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if note.Body != "b" || note.Version != 2 {
+		t.Errorf("the conflicting save must not update the record, got %+v", note)
+	}
+
+	// the expected version of another record isn't checked
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(presets.WithExpectedVersion(r.Context(), &versionedNote{ID: 1}, &presets.ExpectedVersion{Field: "Version", Value: 1}))
+	if err := op.Save(&versionedNote{ID: 1, Body: "d", Version: 3}, "1", &web.EventContext{R: r}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type trashNote struct {
+	gorm.Model
+	Body string
+}
+
+func TestTrash(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&trashNote{}, &versionedNote{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"a", "b"} {
+		if err := db.Create(&trashNote{Body: body}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	op := DataOperator(db)
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil)}
+	search := func(trashed bool) (bodies []string) {
+		result, err := op.Search(ctx, &presets.SearchParams{Model: &trashNote{}, Trashed: trashed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range result.Nodes.([]*trashNote) {
+			bodies = append(bodies, n.Body)
+		}
+		return
+	}
+
+	if column, err := deletedAtColumn(db, &trashNote{}); err != nil || column != "deleted_at" {
+		t.Errorf("deletedAtColumn = %q, %v", column, err)
+	}
+	if _, err := deletedAtColumn(db, &versionedNote{}); err == nil {
+		t.Error("expected an error for the model without gorm.DeletedAt")
+	}
+	if !op.Trashable(&trashNote{}) || op.Trashable(&versionedNote{}) {
+		t.Error("only the model with gorm.DeletedAt is trashable")
+	}
+	if _, err := op.Search(ctx, &presets.SearchParams{Model: &versionedNote{}, Trashed: true}); err == nil {
+		t.Error("expected an error for the trash of the model without gorm.DeletedAt")
+	}
+
+	if err := op.Delete(&trashNote{}, "1", ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(search(false), search(true)); got != "[b] [a]" {
+		t.Errorf("the soft deleted records are only listed in the trash, got %s", got)
+	}
+
+	if _, err := op.FetchTrashed(&trashNote{}, "2", ctx); !errors.Is(err, presets.ErrRecordNotFound) {
+		t.Errorf("the records not deleted can't be fetched from the trash, got %v", err)
+	}
+	if err := op.Restore(&trashNote{}, "2", ctx); !errors.Is(err, presets.ErrRecordNotFound) {
+		t.Errorf("the records not deleted can't be restored, got %v", err)
+	}
+	obj, err := op.FetchTrashed(&trashNote{}, "1", ctx)
+	if err != nil || obj.(*trashNote).Body != "a" {
+		t.Fatalf("FetchTrashed = %v, %v", obj, err)
+	}
+
+	restored := &trashNote{}
+	if err := op.Restore(restored, "1", ctx); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Body != "a" {
+		t.Errorf("the restored record is loaded, got %+v", restored)
+	}
+	if got := fmt.Sprint(search(false), search(true)); got != "[a b] []" {
+		t.Errorf("the restored record is listed, got %s", got)
+	}
+
+	if err := op.Purge(&trashNote{}, "2", ctx); !errors.Is(err, presets.ErrRecordNotFound) {
+		t.Errorf("the records not deleted can't be purged, got %v", err)
+	}
+	if err := op.Delete(&trashNote{}, "2", ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := op.Purge(&trashNote{}, "2", ctx); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.Unscoped().Model(&trashNote{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("the purged record is deleted permanently, got %d, %v", count, err)
+	}
+}
+
+type treeNote struct {
+	gorm.Model
+	ParentID uint
+	Body     string
+}
+
+func TestOrphanCondition(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&treeNote{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []*treeNote{{Body: "root"}, {Body: "trashed"}, {ParentID: 1, Body: "child"}, {ParentID: 2, Body: "orphan"}, {ParentID: 99, Body: "lost"}} {
+		if err := db.Create(n).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	op := DataOperator(db)
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil)}
+	if err := op.Delete(&treeNote{}, "2", ctx); err != nil {
+		t.Fatal(err)
+	}
+	cond, err := op.OrphanCondition(&treeNote{}, "parent_id", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := op.Search(ctx, &presets.SearchParams{Model: &treeNote{}, SQLConditions: []*presets.SQLCondition{
+		{Query: "parent_id <> ?", Args: []interface{}{0}},
+		cond,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies []string
+	for _, n := range result.Nodes.([]*treeNote) {
+		bodies = append(bodies, n.Body)
+	}
+	if got := fmt.Sprint(bodies); got != "[orphan lost]" {
+		t.Errorf("the records whose parent is deleted or trashed are orphans, got %s", got)
+	}
+}
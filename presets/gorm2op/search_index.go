@@ -0,0 +1,273 @@
+package gorm2op
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"gorm.io/gorm"
+)
+
+const SearchDocumentsTableDefault = "presets_search_documents"
+
+var (
+	identifierReg = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	searchTermReg = regexp.MustCompile(`\S+`)
+)
+
+// searchIndex keeps the documents in a table with the columns model, record_id and body,
+// the record_id is the primary key of the record as text.
+type searchIndex struct {
+	db    *gorm.DB
+	table string
+}
+
+func (idx *searchIndex) dbFrom(ctx *web.EventContext) *gorm.DB {
+	return txOr(ctx, idx.db)
+}
+
+// primaryColumn returns the quoted primary key column of the model's table
+func (idx *searchIndex) primaryColumn(db *gorm.DB, model any) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", err
+	}
+	pf := stmt.Schema.PrioritizedPrimaryField
+	if pf == nil {
+		return "", errors.Errorf("search index: %s has no single primary key", stmt.Schema.Name)
+	}
+	return stmt.Quote(stmt.Schema.Table) + "." + stmt.Quote(pf.DBName), nil
+}
+
+func (idx *searchIndex) recordID(db *gorm.DB, obj any) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(obj); err != nil {
+		return "", err
+	}
+	pf := stmt.Schema.PrioritizedPrimaryField
+	if pf == nil {
+		return "", errors.Errorf("search index: %s has no single primary key", stmt.Schema.Name)
+	}
+	v, zero := pf.ValueOf(context.Background(), reflect.Indirect(reflect.ValueOf(obj)))
+	if zero {
+		return "", errors.Errorf("search index: %s has no primary key value", stmt.Schema.Name)
+	}
+	return fmt.Sprint(v), nil
+}
+
+func (idx *searchIndex) recordIDs(db *gorm.DB, objs []any) ([]string, error) {
+	ids := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		id, err := idx.recordID(db, obj)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (idx *searchIndex) Index(ctx *web.EventContext, name string, obj any, content string) error {
+	db := idx.dbFrom(ctx)
+	id, err := idx.recordID(db, obj)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE model = ? AND record_id = ?", idx.table), name, id).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("INSERT INTO %s (model, record_id, body) VALUES (?, ?, ?)", idx.table), name, id, content).Error
+	})
+}
+
+func (idx *searchIndex) Remove(ctx *web.EventContext, name string, obj any) error {
+	db := idx.dbFrom(ctx)
+	id, err := idx.recordID(db, obj)
+	if err != nil {
+		return err
+	}
+	return db.Exec(fmt.Sprintf("DELETE FROM %s WHERE model = ? AND record_id = ?", idx.table), name, id).Error
+}
+
+// snippets runs the query which selects the record_id and the snippet of the records,
+// and returns the snippets in the order of ids.
+func (idx *searchIndex) snippets(db *gorm.DB, ids []string, query string, args ...any) ([]string, error) {
+	rows, err := db.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	m := make(map[string]string, len(ids))
+	for rows.Next() {
+		var id, snippet string
+		if err := rows.Scan(&id, &snippet); err != nil {
+			return nil, err
+		}
+		m[id] = snippet
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	r := make([]string, len(ids))
+	for i, id := range ids {
+		r[i] = m[id]
+	}
+	return r, nil
+}
+
+type PostgresSearchIndexBuilder struct {
+	searchIndex
+	config string
+}
+
+var _ presets.SearchIndex = (*PostgresSearchIndexBuilder)(nil)
+
+// PostgresSearchIndex indexes the documents with tsvector, config is the text search configuration
+// like "english" or "simple" which defaults to "simple".
+func PostgresSearchIndex(db *gorm.DB, config string) (r *PostgresSearchIndexBuilder) {
+	if config == "" {
+		config = "simple"
+	}
+	if !identifierReg.MatchString(config) {
+		panic(fmt.Sprintf("invalid text search config %q", config))
+	}
+	return &PostgresSearchIndexBuilder{
+		searchIndex: searchIndex{db: db, table: SearchDocumentsTableDefault},
+		config:      config,
+	}
+}
+
+func (b *PostgresSearchIndexBuilder) Table(v string) (r *PostgresSearchIndexBuilder) {
+	if !identifierReg.MatchString(v) {
+		panic(fmt.Sprintf("invalid table name %q", v))
+	}
+	b.table = v
+	return b
+}
+
+// AutoMigrate creates the documents table with the generated tsvector column and its GIN index
+func (b *PostgresSearchIndexBuilder) AutoMigrate() error {
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	model TEXT NOT NULL,
+	record_id TEXT NOT NULL,
+	body TEXT NOT NULL DEFAULT '',
+	document tsvector GENERATED ALWAYS AS (to_tsvector('%s'::regconfig, body)) STORED,
+	PRIMARY KEY (model, record_id)
+)`, b.table, b.config)).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_document_idx ON %s USING GIN (document)", b.table, b.table)).Error
+	})
+}
+
+func (b *PostgresSearchIndexBuilder) Match(ctx *web.EventContext, model any, name string, keyword string) (cond *presets.SQLCondition, rank *presets.SQLCondition, err error) {
+	column, err := b.primaryColumn(b.dbFrom(ctx), model)
+	if err != nil {
+		return nil, nil, err
+	}
+	cond = &presets.SQLCondition{
+		Query: fmt.Sprintf("CAST(%s AS TEXT) IN (SELECT record_id FROM %s WHERE model = ? AND document @@ websearch_to_tsquery(CAST(? AS regconfig), ?))", column, b.table),
+		Args:  []any{name, b.config, keyword},
+	}
+	rank = &presets.SQLCondition{
+		Query: fmt.Sprintf("(SELECT ts_rank(d.document, websearch_to_tsquery(CAST(? AS regconfig), ?)) FROM %s d WHERE d.model = ? AND d.record_id = CAST(%s AS TEXT))", b.table, column),
+		Args:  []any{b.config, keyword, name},
+	}
+	return
+}
+
+func (b *PostgresSearchIndexBuilder) Snippets(ctx *web.EventContext, name string, keyword string, objs []any) ([]string, error) {
+	db := b.dbFrom(ctx)
+	ids, err := b.recordIDs(db, objs)
+	if err != nil {
+		return nil, err
+	}
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=20, MinWords=8", presets.SearchIndexHighlightStart, presets.SearchIndexHighlightEnd)
+	return b.snippets(db, ids,
+		fmt.Sprintf("SELECT record_id, ts_headline(CAST(? AS regconfig), body, websearch_to_tsquery(CAST(? AS regconfig), ?), ?) FROM %s WHERE model = ? AND record_id IN ?", b.table),
+		b.config, b.config, keyword, options, name, ids,
+	)
+}
+
+type SQLiteSearchIndexBuilder struct {
+	searchIndex
+}
+
+var _ presets.SearchIndex = (*SQLiteSearchIndexBuilder)(nil)
+
+// SQLiteSearchIndex indexes the documents with the FTS5 extension,
+// github.com/mattn/go-sqlite3 requires the build tag sqlite_fts5 to enable it.
+func SQLiteSearchIndex(db *gorm.DB) (r *SQLiteSearchIndexBuilder) {
+	return &SQLiteSearchIndexBuilder{
+		searchIndex: searchIndex{db: db, table: SearchDocumentsTableDefault},
+	}
+}
+
+func (b *SQLiteSearchIndexBuilder) Table(v string) (r *SQLiteSearchIndexBuilder) {
+	if !identifierReg.MatchString(v) {
+		panic(fmt.Sprintf("invalid table name %q", v))
+	}
+	b.table = v
+	return b
+}
+
+// AutoMigrate creates the FTS5 virtual table of the documents
+func (b *SQLiteSearchIndexBuilder) AutoMigrate() error {
+	return b.db.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(model UNINDEXED, record_id UNINDEXED, body)", b.table)).Error
+}
+
+// ftsQuery quotes every term of the keyword so that the FTS5 syntax in it is matched literally,
+// the terms are matched as prefixes.
+func ftsQuery(keyword string) string {
+	var terms []string
+	for _, t := range searchTermReg.FindAllString(keyword, -1) {
+		terms = append(terms, `"`+strings.ReplaceAll(t, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+func (b *SQLiteSearchIndexBuilder) Match(ctx *web.EventContext, model any, name string, keyword string) (cond *presets.SQLCondition, rank *presets.SQLCondition, err error) {
+	column, err := b.primaryColumn(b.dbFrom(ctx), model)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := ftsQuery(keyword)
+	if query == "" {
+		return &presets.SQLCondition{Query: "1 = 1"}, nil, nil
+	}
+	cond = &presets.SQLCondition{
+		Query: fmt.Sprintf("CAST(%s AS TEXT) IN (SELECT record_id FROM %s WHERE %s MATCH ? AND model = ?)", column, b.table, b.table),
+		Args:  []any{query, name},
+	}
+	// bm25 is negative, the better the match is, the lower it is
+	rank = &presets.SQLCondition{
+		Query: fmt.Sprintf("(SELECT -bm25(%s) FROM %s WHERE %s MATCH ? AND model = ? AND record_id = CAST(%s AS TEXT))", b.table, b.table, b.table, column),
+		Args:  []any{query, name},
+	}
+	return
+}
+
+func (b *SQLiteSearchIndexBuilder) Snippets(ctx *web.EventContext, name string, keyword string, objs []any) ([]string, error) {
+	query := ftsQuery(keyword)
+	if query == "" {
+		return make([]string, len(objs)), nil
+	}
+	db := b.dbFrom(ctx)
+	ids, err := b.recordIDs(db, objs)
+	if err != nil {
+		return nil, err
+	}
+	return b.snippets(db, ids,
+		fmt.Sprintf("SELECT record_id, snippet(%s, 2, ?, ?, '…', 12) FROM %s WHERE %s MATCH ? AND model = ? AND record_id IN ?", b.table, b.table, b.table),
+		presets.SearchIndexHighlightStart, presets.SearchIndexHighlightEnd, query, name, ids,
+	)
+}
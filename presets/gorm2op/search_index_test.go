@@ -0,0 +1,97 @@
+package gorm2op
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/web/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type searchIndexArticle struct {
+	ID    uint
+	Title string
+}
+
+func TestSQLiteSearchIndex(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&searchIndexArticle{}); err != nil {
+		t.Fatal(err)
+	}
+	idx := SQLiteSearchIndex(db)
+	if err := idx.AutoMigrate(); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			t.Skip("FTS5 requires the build tag sqlite_fts5")
+		}
+		t.Fatal(err)
+	}
+
+	ctx := &web.EventContext{R: httptest.NewRequest("GET", "/articles", nil)}
+	articles := []*searchIndexArticle{
+		{ID: 1, Title: "Cooking with garlic"},
+		{ID: 2, Title: "Garlic bread, garlic soup and garlic oil"},
+		{ID: 3, Title: "Gardening"},
+	}
+	for _, a := range articles {
+		if err := db.Create(a).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := idx.Index(ctx, "articles", a, a.Title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// updating replaces the document
+	if err := idx.Index(ctx, "articles", articles[0], "Cooking with onion"); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(keyword string) []*searchIndexArticle {
+		cond, rank, err := idx.Match(ctx, &searchIndexArticle{}, "articles", keyword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := DataOperator(db).Search(ctx, &presets.SearchParams{
+			Model:         &searchIndexArticle{},
+			Keyword:       keyword,
+			SQLConditions: []*presets.SQLCondition{cond},
+			RankBy:        rank,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Nodes.([]*searchIndexArticle)
+	}
+
+	if got := search("garlic"); len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("unexpected result %+v", got)
+	}
+	// the FTS5 syntax in the keyword is matched literally
+	if got := search(`(soup"`); len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("unexpected result %+v", got)
+	}
+	got := search("gar")
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 3 {
+		t.Fatalf("unexpected result %+v", got)
+	}
+
+	snippets, err := idx.Snippets(ctx, "articles", "soup", []any{articles[1], articles[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Garlic bread, garlic \x02soup\x03 and garlic oil"; snippets[0] != want || snippets[1] != "" {
+		t.Fatalf("unexpected snippets %q", snippets)
+	}
+
+	if err := idx.Remove(ctx, "articles", articles[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("garlic"); len(got) != 0 {
+		t.Fatalf("unexpected result %+v", got)
+	}
+}
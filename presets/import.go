@@ -0,0 +1,418 @@
+package presets
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/i18n"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+const (
+	ParamImportDryRun = "import_dry_run"
+	paramImportFile   = "ImportFile"
+
+	importResultPortalName = "presets_ImportResultPortalName"
+	importMaxRowsDefault   = 5000
+)
+
+type ImportBuilder struct {
+	mb              *ModelBuilder
+	columns         map[string]string
+	maxRows         int
+	allOrNothing    bool
+	transactionFunc func(ctx *web.EventContext, f func(ctx *web.EventContext) error) error
+	dialogWidth     string
+}
+
+// Import enables importing records from a csv file, every row goes through the field setters,
+// the validator and the saver of the creating form as if it was submitted from the editing drawer.
+func (mb *ModelBuilder) Import() (r *ImportBuilder) {
+	if mb.importing == nil {
+		mb.importing = &ImportBuilder{
+			mb:           mb,
+			columns:      make(map[string]string),
+			maxRows:      importMaxRowsDefault,
+			allOrNothing: true,
+			dialogWidth:  "900",
+		}
+	}
+	return mb.importing
+}
+
+// Column maps the csv header to the editing field, if not set, the header
+// is matched with the field name and the field label case-insensitively.
+func (b *ImportBuilder) Column(header string, field string) (r *ImportBuilder) {
+	b.columns[normalizeImportHeader(header)] = field
+	return b
+}
+
+func (b *ImportBuilder) MaxRows(v int) (r *ImportBuilder) {
+	b.maxRows = v
+	return b
+}
+
+// AllOrNothing makes the import save nothing if any row is invalid or fails to save,
+// its default value is true.
+func (b *ImportBuilder) AllOrNothing(v bool) (r *ImportBuilder) {
+	b.allOrNothing = v
+	return b
+}
+
+// TransactionFunc is used to save the rows atomically when AllOrNothing is on,
+// if not set, the data operator will be used if it implements Transactor.
+func (b *ImportBuilder) TransactionFunc(v func(ctx *web.EventContext, f func(ctx *web.EventContext) error) error) (r *ImportBuilder) {
+	b.transactionFunc = v
+	return b
+}
+
+func (b *ImportBuilder) DialogWidth(v string) (r *ImportBuilder) {
+	b.dialogWidth = v
+	return b
+}
+
+type ImportRowResult struct {
+	// Line is the line number in the csv file, the header is line 1
+	Line   int
+	Errors web.ValidationErrors
+	obj    interface{}
+}
+
+type ImportResult struct {
+	Rows           []*ImportRowResult
+	IgnoredColumns []string
+	Imported       int
+	DryRun         bool
+}
+
+func (r *ImportResult) InvalidRows() (rows []*ImportRowResult) {
+	for _, row := range r.Rows {
+		if row.Errors.HaveErrors() {
+			rows = append(rows, row)
+		}
+	}
+	return
+}
+
+func (r *ImportResult) ImportedModels() (models []any) {
+	if r.DryRun || r.Imported == 0 {
+		return nil
+	}
+	for _, row := range r.Rows {
+		if !row.Errors.HaveErrors() {
+			models = append(models, row.obj)
+		}
+	}
+	return
+}
+
+func normalizeImportHeader(v string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(v, "\xEF\xBB\xBF")))
+}
+
+func (b *ImportBuilder) editingBuilder() *EditingBuilder {
+	if b.mb.creating != nil {
+		return b.mb.creating
+	}
+	return b.mb.editing
+}
+
+// resolveColumns returns the field name of every csv column, empty if the column is ignored.
+func (b *ImportBuilder) resolveColumns(ctx *web.EventContext, header []string) (fields []string, ignored []string) {
+	eb := b.editingBuilder()
+	candidates := make(map[string]string)
+	for _, f := range eb.fields {
+		candidates[normalizeImportHeader(f.name)] = f.name
+		candidates[normalizeImportHeader(eb.getLabel(f.NameLabel))] = f.name
+		candidates[normalizeImportHeader(i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, eb.getLabel(f.NameLabel)))] = f.name
+	}
+	for k, v := range b.columns {
+		candidates[k] = v
+	}
+
+	fields = make([]string, len(header))
+	for i, col := range header {
+		name, ok := candidates[normalizeImportHeader(col)]
+		if !ok || eb.GetField(name) == nil {
+			ignored = append(ignored, col)
+			continue
+		}
+		fields[i] = name
+	}
+	return
+}
+
+func (b *ImportBuilder) transaction(ctx *web.EventContext, f func(ctx *web.EventContext) error) error {
+	if b.transactionFunc != nil {
+		return b.transactionFunc(ctx, f)
+	}
+	if t, ok := b.mb.p.dataOperator.(Transactor); ok {
+		return t.Transaction(ctx, f)
+	}
+	return errors.New("all or nothing import requires a TransactionFunc or a data operator implements Transactor")
+}
+
+// rowContext makes an EventContext whose form only contains the values of the row
+func rowContext(ctx *web.EventContext, values url.Values) *web.EventContext {
+	r := ctx.R.WithContext(context.WithValue(ctx.R.Context(), theDeleteIndexBuilderKey, &ModifiedIndexesBuilder{}))
+	r.Form = values
+	r.PostForm = values
+	r.MultipartForm = &multipart.Form{Value: values}
+	return &web.EventContext{R: r, W: ctx.W, Injector: ctx.Injector}
+}
+
+// Run imports the csv rows, if dryRun is true, the rows will only be validated.
+func (b *ImportBuilder) Run(ctx *web.EventContext, reader io.Reader, dryRun bool) (result *ImportResult, err error) {
+	msgr := MustGetMessages(ctx.R)
+	eb := b.editingBuilder()
+
+	cr := csv.NewReader(reader)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New(msgr.ImportNoRows)
+		}
+		return nil, err
+	}
+
+	result = &ImportResult{DryRun: dryRun}
+	var fields []string
+	fields, result.IgnoredColumns = b.resolveColumns(ctx, header)
+
+	line := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		if b.maxRows > 0 && len(result.Rows) >= b.maxRows {
+			return nil, errors.New(msgr.ImportTooManyRows(b.maxRows))
+		}
+
+		values := url.Values{}
+		for i, v := range record {
+			if i < len(fields) && fields[i] != "" {
+				values.Set(fields[i], v)
+			}
+		}
+		rowCtx := rowContext(ctx, values)
+		row := &ImportRowResult{Line: line, obj: b.mb.NewModel()}
+		result.Rows = append(result.Rows, row)
+
+		if vErr := eb.RunSetterFunc(rowCtx, true, row.obj); vErr.HaveErrors() {
+			row.Errors = vErr
+			continue
+		}
+		if b.mb.Info().Verifier().Do(PermCreate).ObjectOn(row.obj).WithReq(ctx.R).IsAllowed() != nil {
+			row.Errors.GlobalError(perm.PermissionDenied.Error())
+			continue
+		}
+		row.Errors = eb.Validate(row.obj, rowCtx)
+	}
+
+	if len(result.Rows) == 0 {
+		return nil, errors.New(msgr.ImportNoRows)
+	}
+
+	invalid := len(result.InvalidRows()) > 0
+	if dryRun || (invalid && b.allOrNothing) {
+		return result, nil
+	}
+
+	save := func(ctx *web.EventContext) error {
+		for _, row := range result.Rows {
+			if row.Errors.HaveErrors() {
+				continue
+			}
+			if err := eb.Saver(row.obj, "", ctx); err != nil {
+				row.Errors.GlobalError(err.Error())
+				if b.allOrNothing {
+					return err
+				}
+				continue
+			}
+			result.Imported++
+		}
+		return nil
+	}
+
+	if !b.allOrNothing {
+		_ = save(ctx)
+		return result, nil
+	}
+	if err := b.transaction(ctx, save); err != nil {
+		result.Imported = 0
+		// the error is not caused by any row, e.g. failed to begin the transaction
+		if len(result.InvalidRows()) == 0 {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (b *ImportBuilder) importable(ctx *web.EventContext) bool {
+	verifier := b.mb.Info().Verifier()
+	return verifier.Do(PermImport).WithReq(ctx.R).IsAllowed() == nil &&
+		verifier.Do(PermCreate).WithReq(ctx.R).IsAllowed() == nil
+}
+
+func (b *ImportBuilder) openImportDialog(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if !b.importable(ctx) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return
+	}
+	b.mb.p.dialog(ctx, &r, b.importPanel(ctx, nil), b.dialogWidth)
+	return
+}
+
+func (b *ImportBuilder) doImport(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if !b.importable(ctx) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return
+	}
+	msgr := MustGetMessages(ctx.R)
+
+	var fh *multipart.FileHeader
+	if ctx.R.MultipartForm != nil && len(ctx.R.MultipartForm.File[paramImportFile]) > 0 {
+		fh = ctx.R.MultipartForm.File[paramImportFile][0]
+	}
+	if fh == nil {
+		ShowMessage(&r, msgr.ImportFileRequired, ColorWarning)
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return r, err
+	}
+	defer f.Close()
+
+	dryRun := ctx.R.FormValue(ParamImportDryRun) == "true"
+	result, err := b.Run(ctx, f, dryRun)
+	if err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	if result.Imported > 0 {
+		r.Emit(b.mb.NotifModelsCreated(), PayloadModelsCreated{Models: result.ImportedModels()})
+		if len(result.InvalidRows()) == 0 {
+			ShowMessage(&r, msgr.ImportSuccessfully(result.Imported), "")
+			web.AppendRunScripts(&r, CloseDialogVarScript)
+			return r, nil
+		}
+	}
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: importResultPortalName,
+		Body: b.resultPanel(ctx, result),
+	})
+	return r, nil
+}
+
+func (b *ImportBuilder) importPanel(ctx *web.EventContext, result *ImportResult) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+	doImport := func(dryRun bool) string {
+		return web.Plaid().
+			EventFunc(actions.DoImport).
+			URL(b.mb.Info().ListingHref()).
+			Query(ParamImportDryRun, fmt.Sprint(dryRun)).
+			Go()
+	}
+	return VCard(
+		VCardTitle(h.Text(msgr.ImportTitle(b.mb.Info().LabelName(ctx, false)))),
+		VCardText(
+			VFileInput().
+				Label(msgr.ImportFile).
+				Attr("accept", ".csv,text/csv").
+				PrependIcon("mdi-file-delimited-outline").
+				Attr(web.VField(paramImportFile, nil)...),
+			web.Portal(b.resultPanel(ctx, result)).Name(importResultPortalName),
+		),
+		VCardActions(
+			VSpacer(),
+			VBtn(msgr.Cancel).Variant(VariantFlat).Class("ml-2").Attr("@click", CloseDialogVarScript),
+			VBtn(msgr.ImportDryRun).Variant(VariantTonal).Color(ColorPrimary).
+				Attr(":disabled", "isFetching").
+				Attr("@click", doImport(true)),
+			VBtn(msgr.Import).Variant(VariantFlat).Color(ColorPrimary).
+				Attr(":disabled", "isFetching").
+				Attr(":loading", "isFetching").
+				Attr("@click", doImport(false)),
+		),
+	)
+}
+
+func (b *ImportBuilder) resultPanel(ctx *web.EventContext, result *ImportResult) h.HTMLComponent {
+	if result == nil {
+		return nil
+	}
+	msgr := MustGetMessages(ctx.R)
+	eb := b.editingBuilder()
+
+	invalidRows := result.InvalidRows()
+	var alerts []h.HTMLComponent
+	if len(result.IgnoredColumns) > 0 {
+		alerts = append(alerts, VAlert(h.Text(msgr.ImportIgnoredColumns(strings.Join(result.IgnoredColumns, ", ")))).
+			Type(ColorWarning).Density(DensityCompact).Class("mb-2"))
+	}
+	switch {
+	case len(invalidRows) == 0:
+		alerts = append(alerts, VAlert(h.Text(msgr.ImportValidRows(len(result.Rows), len(result.Rows)))).
+			Type(ColorSuccess).Density(DensityCompact).Class("mb-2"))
+	case result.DryRun || result.Imported > 0:
+		alerts = append(alerts, VAlert(h.Text(msgr.ImportValidRows(len(result.Rows)-len(invalidRows), len(result.Rows)))).
+			Type(ColorError).Density(DensityCompact).Class("mb-2"))
+	default:
+		alerts = append(alerts, VAlert(h.Text(msgr.ImportNothingImported)).
+			Type(ColorError).Density(DensityCompact).Class("mb-2"))
+	}
+	if result.Imported > 0 {
+		alerts = append(alerts, VAlert(h.Text(msgr.ImportSuccessfully(result.Imported))).
+			Type(ColorInfo).Density(DensityCompact).Class("mb-2"))
+	}
+	if len(invalidRows) == 0 {
+		return h.Div(alerts...)
+	}
+
+	var trs []h.HTMLComponent
+	for _, row := range invalidRows {
+		var msgs []h.HTMLComponent
+		for _, e := range row.Errors.GetGlobalErrors() {
+			msgs = append(msgs, h.Div(h.Text(e)))
+		}
+		for _, f := range eb.fields {
+			for _, e := range row.Errors.GetFieldErrors(f.name) {
+				label := i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, eb.getLabel(f.NameLabel))
+				msgs = append(msgs, h.Div(h.Text(fmt.Sprintf("%s%s %s", label, msgr.Colon, e))))
+			}
+		}
+		trs = append(trs, h.Tr(
+			h.Td(h.Text(fmt.Sprint(row.Line))),
+			h.Td(msgs...).Class("text-error"),
+		))
+	}
+	return h.Div(
+		h.Components(alerts...),
+		VTable(
+			h.Thead(h.Tr(
+				h.Th(msgr.ImportLine),
+				h.Th(msgr.ImportErrors),
+			)),
+			h.Tbody(trs...),
+		).Density(DensityCompact).Height(360).FixedHeader(true),
+	)
+}
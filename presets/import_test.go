@@ -0,0 +1,79 @@
+package presets
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImportTestBuilder(saved *[]string) *ImportBuilder {
+	mb := New().Model(&foo{})
+	eb := mb.Editing("Version")
+	eb.ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+		if obj.(*foo).Version == "" {
+			err.FieldError("Version", "Version is required")
+		}
+		return
+	})
+	eb.SaveFunc(func(obj interface{}, id string, ctx *web.EventContext) (err error) {
+		if obj.(*foo).Version == "broken" {
+			return errors.New("cannot save")
+		}
+		*saved = append(*saved, obj.(*foo).Version)
+		return nil
+	})
+	return mb.Import().TransactionFunc(func(ctx *web.EventContext, f func(ctx *web.EventContext) error) error {
+		before := len(*saved)
+		err := f(ctx)
+		if err != nil {
+			*saved = (*saved)[:before]
+		}
+		return err
+	})
+}
+
+func TestImportRun(t *testing.T) {
+	var saved []string
+	ib := newImportTestBuilder(&saved)
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil)}
+
+	csvData := "\xEF\xBB\xBFversion,Unknown\nv1,x\n,y\nv3,z\n"
+	result, err := ib.Run(ctx, strings.NewReader(csvData), true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Unknown"}, result.IgnoredColumns)
+	require.Len(t, result.Rows, 3)
+	invalid := result.InvalidRows()
+	require.Len(t, invalid, 1)
+	assert.Equal(t, 3, invalid[0].Line)
+	assert.Equal(t, []string{"Version is required"}, invalid[0].Errors.GetFieldErrors("Version"))
+	assert.Empty(t, saved)
+
+	// all or nothing: nothing is saved if any row is invalid
+	result, err = ib.Run(ctx, strings.NewReader(csvData), false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Imported)
+	assert.Empty(t, saved)
+
+	// a failed save rolls back the rows saved before it
+	result, err = ib.Run(ctx, strings.NewReader("Version\nv1\nbroken\n"), false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Imported)
+	assert.Empty(t, saved)
+	assert.Equal(t, []string{"cannot save"}, result.Rows[1].Errors.GetGlobalErrors())
+
+	ib.AllOrNothing(false)
+	result, err = ib.Run(ctx, strings.NewReader(csvData), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, []string{"v1", "v3"}, saved)
+	assert.Len(t, result.ImportedModels(), 2)
+
+	ib.MaxRows(1)
+	_, err = ib.Run(ctx, strings.NewReader(csvData), true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	vx "github.com/qor5/x/v3/ui/vuetifyx"
+	h "github.com/theplant/htmlgo"
+)
+
+// InlineEditFields makes the cells of the fields editable in the listing, the editor is the component
+// of the editing field, and the record is saved with its setter, the editing validator and saver.
+func (b *ListingBuilder) InlineEditFields(vs ...string) (r *ListingBuilder) {
+	b.inlineEditFields = vs
+	return b
+}
+
+func (c *ListingCompo) inlineEditAllowed(evCtx *web.EventContext, obj any, field string) bool {
+	if !slices.Contains(c.lb.inlineEditFields, field) || c.inTrash(evCtx) {
+		return false
+	}
+	return c.lb.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn("f_"+field).WithReq(evCtx.R).IsAllowed() == nil &&
+		c.lb.mb.Info().FieldWritable(evCtx.R, field)
+}
+
+func (c *ListingCompo) cellPortalName(id, field string) string {
+	return fmt.Sprintf("%s_cell_%s_%s", c.CompoID(), id, field)
+}
+
+// cellComponentFunc puts the content of every cell into a portal when inline editing is on,
+// so that a row can be re-rendered without reloading the listing.
+func (c *ListingCompo) cellComponentFunc(ctx context.Context, f *FieldBuilder) vx.CellComponentFunc {
+	in := c.lb.cellComponentFunc(f)
+	if len(c.lb.inlineEditFields) == 0 {
+		return in
+	}
+	return func(obj interface{}, fieldName string, evCtx *web.EventContext) h.HTMLComponent {
+		comp := in(obj, fieldName, evCtx)
+		content, ok := cellContent(comp)
+		if !ok {
+			return comp
+		}
+		return h.Td(
+			web.Portal(c.cellView(ctx, evCtx, obj, fieldName, content)).Name(c.cellPortalName(ObjectID(obj), fieldName)),
+		)
+	}
+}
+
+// cellContent turns the td of the field's ComponentFunc into a div with the same attributes and children,
+// which is the content of the portal in the td rendered by the listing.
+func cellContent(comp h.HTMLComponent) (h.HTMLComponent, bool) {
+	td, ok := comp.(*h.HTMLTagBuilder)
+	if !ok {
+		return nil, false
+	}
+	return td.Tag("div"), true
+}
+
+func (c *ListingCompo) cellView(ctx context.Context, evCtx *web.EventContext, obj any, field string, content h.HTMLComponent) h.HTMLComponent {
+	if !c.inlineEditAllowed(evCtx, obj, field) {
+		return content
+	}
+	return h.Div(content).Class("presets-inline-edit-cell").Style("cursor: text; min-height: 24px;").
+		Attr("@click.stop", stateful.PostAction(ctx, c, c.EditCell, CellRequest{ID: ObjectID(obj), Field: field}).Go())
+}
+
+func (c *ListingCompo) cellEditor(ctx context.Context, obj any, req CellRequest, vErr *web.ValidationErrors) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	eb := c.lb.mb.editing
+	return web.Scope(
+		h.Div(
+			h.Div(
+				eb.fieldToComponentWithFormValueKey(c.lb.mb.Info(), obj, "", evCtx, req.Field, true, vErr),
+			).Class("flex-grow-1"),
+			VBtn("").Icon("mdi-check").Size(SizeSmall).Variant(VariantText).Color(ColorPrimary).
+				Attr("@click", stateful.PostAction(ctx, c, c.SaveCell, req).Go()),
+			VBtn("").Icon("mdi-close").Size(SizeSmall).Variant(VariantText).
+				Attr("@click", stateful.PostAction(ctx, c, c.ReloadRow, RowRequest{ID: req.ID}).Go()),
+		).Class("d-flex align-center ga-1").Style("min-width: 200px;").Attr("@click.stop", true),
+	).VSlot("{ form }")
+}
+
+type CellRequest struct {
+	ID    string `json:"id"`
+	Field string `json:"field"`
+}
+
+type RowRequest struct {
+	ID string `json:"id"`
+}
+
+func (c *ListingCompo) EditCell(ctx context.Context, req CellRequest) (r web.EventResponse, err error) {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	obj, err := c.lb.mb.editing.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		return r, err
+	}
+	if !c.inlineEditAllowed(evCtx, obj, req.Field) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: c.cellPortalName(req.ID, req.Field),
+		Body: c.cellEditor(ctx, obj, req, &web.ValidationErrors{}),
+	})
+	return r, nil
+}
+
+func (c *ListingCompo) SaveCell(ctx context.Context, req CellRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	eb := c.lb.mb.editing
+	obj, err := eb.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		return r, err
+	}
+	if !c.inlineEditAllowed(evCtx, obj, req.Field) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+
+	vErr := eb.FieldsBuilder.Only(req.Field).Unmarshal(obj, c.lb.mb.Info(), false, evCtx)
+	if !vErr.HaveErrors() {
+		vErr = eb.Validate(obj, evCtx)
+	}
+	if vErr.HaveErrors() {
+		// errors of the other fields can't be shown in the cell
+		msg := vErr.GetGlobalError()
+		for _, f := range eb.fields {
+			if errs := vErr.GetFieldErrors(f.name); msg == "" && f.name != req.Field && len(errs) > 0 {
+				msg = errs[0]
+			}
+		}
+		if msg != "" {
+			ShowMessage(&r, msg, ColorWarning)
+		}
+		r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+			Name: c.cellPortalName(req.ID, req.Field),
+			Body: c.cellEditor(ctx, obj, req, &vErr),
+		})
+		return r, nil
+	}
+
+	if err := c.lb.mb.checkVersion(evCtx, obj); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+	if err := eb.Saver(obj, req.ID, evCtx); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	ShowMessage(&r, msgr.SuccessfullyUpdated, "")
+	r.Emit(c.lb.mb.NotifRowUpdated(), PayloadRowUpdated{Id: req.ID})
+	return r, nil
+}
+
+// ReloadRow re-renders the visible cells of the row
+func (c *ListingCompo) ReloadRow(ctx context.Context, req RowRequest) (r web.EventResponse, err error) {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	obj, err := c.lb.mb.editing.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return r, nil
+		}
+		return r, err
+	}
+
+	_, columns, err := c.getColumns(ctx)
+	if err != nil {
+		return r, err
+	}
+	for _, col := range columns {
+		if !col.Visible {
+			continue
+		}
+		content, ok := cellContent(c.lb.cellComponentFunc(c.lb.getFieldOrDefault(col.Name))(obj, col.Name, evCtx))
+		if !ok {
+			continue
+		}
+		r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+			Name: c.cellPortalName(req.ID, col.Name),
+			Body: c.cellView(ctx, evCtx, obj, col.Name, content),
+		})
+	}
+	return r, nil
+}
@@ -0,0 +1,101 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+func TestCellContent(t *testing.T) {
+	td := h.Td(h.Span("a > b"), h.Text("c")).Class("text-right").Attr("@click", "x > 0 && go()")
+	content, ok := cellContent(td)
+	require.True(t, ok)
+	assert.Equal(t, "\n<div @click='x > 0 && go()' class='text-right'>\n<span>a &gt; b</span>\nc</div>\n", h.MustString(content, context.Background()))
+
+	_, ok = cellContent(h.Text("a"))
+	assert.False(t, ok)
+}
+
+type inlineProduct struct {
+	ID   uint
+	Name string
+}
+
+func TestInlineEdit(t *testing.T) {
+	op := &memoryTestOperator[inlineProduct]{records: []*inlineProduct{{ID: 1, Name: "Tea"}}}
+	pb := New().DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("viewer").WhoAre(perm.Denied).ToDo(PermUpdate).On(perm.Anything),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&inlineProduct{})
+	mb.Listing("ID", "Name").InlineEditFields("Name")
+	mb.Editing("Name").ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+		if obj.(*inlineProduct).Name == "" {
+			err.FieldError("Name", "name is required")
+		}
+		return
+	})
+
+	request := func(role string, values url.Values) (*ListingCompo, context.Context) {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("role", role)
+		evCtx := rowContext(&web.EventContext{R: r, W: httptest.NewRecorder()}, values)
+		return &ListingCompo{lb: mb.Listing(), ID: "inline-products"}, web.WrapEventContext(context.Background(), evCtx)
+	}
+	req := CellRequest{ID: "1", Field: "Name"}
+
+	// the editor stays open with the error of the field
+	c, ctx := request("", url.Values{"Name": {""}})
+	r, err := c.SaveCell(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, r.UpdatePortals, 1)
+	assert.Equal(t, c.cellPortalName("1", "Name"), r.UpdatePortals[0].Name)
+	assert.Contains(t, h.MustString(r.UpdatePortals[0].Body, ctx), "name is required")
+	assert.Equal(t, "Tea", op.records[0].Name)
+
+	c, ctx = request("viewer", url.Values{"Name": {"Green Tea"}})
+	r, err = c.EditCell(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, r.UpdatePortals)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	r, err = c.SaveCell(ctx, req)
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	assert.Equal(t, "Tea", op.records[0].Name)
+
+	c, ctx = request("", url.Values{"Name": {"Green Tea"}})
+	r, err = c.SaveCell(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "Green Tea", op.records[0].Name)
+	assert.Contains(t, r.RunScript, Messages_en_US.SuccessfullyUpdated)
+
+	// the row is re-rendered cell by cell, the editable cells are clickable again
+	r, err = c.ReloadRow(ctx, RowRequest{ID: "1"})
+	require.NoError(t, err)
+	bodies := map[string]string{}
+	for _, p := range r.UpdatePortals {
+		bodies[p.Name] = h.MustString(p.Body, ctx)
+	}
+	assert.Contains(t, bodies[c.cellPortalName("1", "ID")], ">1</div>")
+	assert.NotContains(t, bodies[c.cellPortalName("1", "ID")], "presets-inline-edit-cell")
+	assert.Contains(t, bodies[c.cellPortalName("1", "Name")], ">Green Tea</div>")
+	assert.Contains(t, bodies[c.cellPortalName("1", "Name")], "presets-inline-edit-cell")
+
+	c, ctx = request("viewer", nil)
+	r, err = c.ReloadRow(ctx, RowRequest{ID: "1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, r.UpdatePortals)
+	for _, p := range r.UpdatePortals {
+		assert.NotContains(t, h.MustString(p.Body, ctx), "presets-inline-edit-cell")
+	}
+}
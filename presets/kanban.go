@@ -0,0 +1,206 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+const KanbanPerColumnDefault = 50
+
+type KanbanColumn struct {
+	Value string
+	Label string
+}
+
+type KanbanColumnsFunc func(ctx *web.EventContext) []*KanbanColumn
+
+type KanbanBuilder struct {
+	field       string
+	dbColumn    string
+	columnsFunc KanbanColumnsFunc
+	cardFunc    ObjectComponentFunc
+	perColumn   int64
+}
+
+// Kanban adds a board layout to the listing, the records are grouped into columns by the field,
+// and dragging a card to another column updates the field with the editing validator and saver.
+func (b *ListingBuilder) Kanban(field string) (r *KanbanBuilder) {
+	if b.kanban != nil && b.kanban.field == field {
+		return b.kanban
+	}
+	b.kanban = &KanbanBuilder{
+		field:     field,
+		dbColumn:  strcase.ToSnake(field),
+		perColumn: KanbanPerColumnDefault,
+	}
+	return b.kanban
+}
+
+// Columns sets the values of the field listed as columns in order
+func (b *KanbanBuilder) Columns(vs ...*KanbanColumn) (r *KanbanBuilder) {
+	b.columnsFunc = func(_ *web.EventContext) []*KanbanColumn {
+		return vs
+	}
+	return b
+}
+
+func (b *KanbanBuilder) ColumnsFunc(v KanbanColumnsFunc) (r *KanbanBuilder) {
+	b.columnsFunc = v
+	return b
+}
+
+// CardFunc renders the body of a card, the first listing field is rendered by default
+func (b *KanbanBuilder) CardFunc(v ObjectComponentFunc) (r *KanbanBuilder) {
+	b.cardFunc = v
+	return b
+}
+
+// DBColumn sets the column which is used to search the records of a kanban column, default is the snake case of the field
+func (b *KanbanBuilder) DBColumn(v string) (r *KanbanBuilder) {
+	b.dbColumn = v
+	return b
+}
+
+// PerColumn sets the max number of cards in a column
+func (b *KanbanBuilder) PerColumn(v int64) (r *KanbanBuilder) {
+	b.perColumn = v
+	return b
+}
+
+func (b *KanbanBuilder) columns(evCtx *web.EventContext) []*KanbanColumn {
+	if b.columnsFunc == nil {
+		panic(fmt.Sprintf("kanban columns of field %s are not set", b.field))
+	}
+	return b.columnsFunc(evCtx)
+}
+
+func (c *ListingCompo) kanbanMoveAllowed(evCtx *web.EventContext, obj any) bool {
+	return c.lb.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn("f_"+c.lb.kanban.field).WithReq(evCtx.R).IsAllowed() == nil &&
+		c.lb.mb.Info().FieldWritable(evCtx.R, c.lb.kanban.field)
+}
+
+func (c *ListingCompo) kanbanBoard(ctx context.Context, searchParams *SearchParams) h.HTMLComponent {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	kb := c.lb.kanban
+
+	var columns []h.HTMLComponent
+	for _, col := range kb.columns(evCtx) {
+		params := *searchParams
+		params.Model = c.lb.mb.NewModel()
+		params.SQLConditions = append(slices.Clone(searchParams.SQLConditions), &SQLCondition{
+			Query: fmt.Sprintf("%s = ?", kb.dbColumn),
+			Args:  []interface{}{col.Value},
+		})
+		params.Page = 1
+		params.PerPage = kb.perColumn
+		params.RelayPagination = nil
+		params.RelayPaginateRequest = nil
+
+		result, err := c.lb.Searcher(evCtx, &params)
+		if err != nil {
+			panic(errors.Wrap(err, "searcher error"))
+		}
+
+		var cards []h.HTMLComponent
+		reflectutils.ForEach(result.Nodes, func(obj interface{}) {
+			cards = append(cards, c.kanbanCard(evCtx, obj))
+		})
+		if len(cards) == 0 {
+			cards = append(cards, h.Div(h.Text(msgr.ListingNoRecordToShow)).Class("text-caption text-grey text-center py-4"))
+		}
+
+		columns = append(columns, VSheet(
+			h.Div(
+				h.Span(col.Label).Class("text-subtitle-2"),
+				VChip(h.Text(fmt.Sprint(result.PageInfo.TotalCount))).Size(SizeXSmall).Class("ml-2"),
+			).Class("d-flex align-center px-3 py-2"),
+			h.Div(cards...).Class("d-flex flex-column ga-2 px-2 pb-2").Style("min-height: 80px;"),
+		).Color("grey-lighten-4").Rounded(true).MinWidth(280).Width(280).Class("flex-shrink-0").
+			Attr("@dragover.prevent", true).
+			Attr("@drop.prevent", fmt.Sprintf(`const id = $event.dataTransfer.getData("text/plain"); if (id) { %s }`,
+				stateful.PostAction(ctx, c, c.MoveKanbanCard, KanbanMoveRequest{Value: col.Value},
+					stateful.WithAppendFix(`v.request.id = id;`),
+				).Go(),
+			)))
+	}
+	return h.Div(columns...).Class("presets-kanban d-flex ga-3 overflow-x-auto pb-2")
+}
+
+func (c *ListingCompo) kanbanCard(evCtx *web.EventContext, obj any) h.HTMLComponent {
+	id := ObjectID(obj)
+	card := VCard(
+		VCardText(c.kanbanCardBody(evCtx, obj)),
+	).Variant(VariantFlat).Class("cursor-pointer").Attr("@click", c.openRecordEvent(id))
+	if c.kanbanMoveAllowed(evCtx, obj) {
+		card.Attr("draggable", "true").
+			Attr("@dragstart", fmt.Sprintf(`$event.dataTransfer.setData("text/plain", %s)`, h.JSONString(id)))
+	}
+	return card
+}
+
+func (c *ListingCompo) kanbanCardBody(evCtx *web.EventContext, obj any) h.HTMLComponent {
+	if c.lb.kanban.cardFunc != nil {
+		return c.lb.kanban.cardFunc(obj, evCtx)
+	}
+	return c.recordSummary(evCtx, obj, c.lb.kanban.field)
+}
+
+type KanbanMoveRequest struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// MoveKanbanCard sets the field of the record to the value of the column which the card is dropped to
+func (c *ListingCompo) MoveKanbanCard(ctx context.Context, req KanbanMoveRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if c.lb.kanban == nil {
+		return r, errors.New("kanban is not enabled")
+	}
+	field := c.lb.kanban.field
+
+	eb := c.lb.mb.editing
+	obj, err := eb.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		return r, err
+	}
+	if !c.kanbanMoveAllowed(evCtx, obj) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+
+	if !slices.ContainsFunc(c.lb.kanban.columns(evCtx), func(col *KanbanColumn) bool { return col.Value == req.Value }) {
+		return r, errors.Errorf("%q is not a kanban column", req.Value)
+	}
+
+	current, err := reflectutils.Get(obj, field)
+	if err != nil {
+		return r, err
+	}
+	if fmt.Sprint(current) == req.Value {
+		return r, nil
+	}
+	// the value is set like it's posted by the editing form, so that the setter of the field converts it
+	if eb.GetField(field) != nil {
+		rowCtx := rowContext(evCtx, url.Values{field: {req.Value}})
+		if vErr := eb.FieldsBuilder.Only(field).Unmarshal(obj, c.lb.mb.Info(), false, rowCtx); vErr.HaveErrors() {
+			ShowMessage(&r, msgr.ListingMoveInvalid, ColorWarning)
+			return r, nil
+		}
+	} else if err := reflectutils.Set(obj, field, req.Value); err != nil {
+		return r, err
+	}
+
+	c.saveMovedRecord(&r, evCtx, obj, req.ID)
+	return r, nil
+}
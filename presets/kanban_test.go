@@ -0,0 +1,58 @@
+package presets
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type kanbanTask struct {
+	ID     uint
+	Title  string
+	Status string
+}
+
+func TestMoveKanbanCard(t *testing.T) {
+	op := &memoryTestOperator[kanbanTask]{records: []*kanbanTask{{ID: 1, Title: "Write docs", Status: "todo"}}}
+	mb := New().DataOperator(op).Model(&kanbanTask{})
+	mb.Editing("Title", "Status").ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+		if obj.(*kanbanTask).Status == "archived" {
+			err.FieldError("Status", "can't be archived")
+		}
+		return
+	})
+	var setterCalls int
+	mb.Editing().Field("Status").SetterFunc(func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+		setterCalls++
+		obj.(*kanbanTask).Status = ctx.R.FormValue(field.FormKey)
+		return nil
+	})
+	mb.Listing().Kanban("Status").Columns(
+		&KanbanColumn{Value: "todo", Label: "To Do"},
+		&KanbanColumn{Value: "done", Label: "Done"},
+		&KanbanColumn{Value: "archived", Label: "Archived"},
+	)
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing()}
+
+	_, err := c.MoveKanbanCard(ctx, KanbanMoveRequest{ID: "1", Value: "done"})
+	require.NoError(t, err)
+	assert.Equal(t, "done", op.records[0].Status)
+	assert.Equal(t, "Write docs", op.records[0].Title)
+	assert.Equal(t, 1, setterCalls, "the value is set by the setter of the field")
+
+	// only the values of the columns can be set
+	_, err = c.MoveKanbanCard(ctx, KanbanMoveRequest{ID: "1", Value: "hacked"})
+	require.Error(t, err)
+	assert.Equal(t, "done", op.records[0].Status)
+
+	_, err = c.MoveKanbanCard(ctx, KanbanMoveRequest{ID: "1", Value: "archived"})
+	require.NoError(t, err)
+	assert.Equal(t, "done", op.records[0].Status)
+}
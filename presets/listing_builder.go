@@ -79,6 +79,20 @@ type ListingBuilder struct {
 	dialogHeight      string
 	keywordSearchOff  bool
 	columnsProcessor  ColumnsProcessor
+	exporting         *ExportBuilder
+	viewStore         ListingViewStore
+	trash             bool
+	inlineEditFields  []string
+	kanban            *KanbanBuilder
+	calendar          *CalendarBuilder
+	tree              *TreeBuilder
+	sortable          *SortableBuilder
+	searchIndex       SearchIndex
+	searchIndexFields []string
+	// searchIndexWrapped is set once the editing SaveFunc and DeleteFunc are wrapped to update the index
+	searchIndexWrapped bool
+
+	advancedFilterFieldsFunc AdvancedFilterFieldsFunc
 
 	FieldsBuilder
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -55,6 +56,7 @@ type ListingCompo struct {
 	Popup              bool             `json:"popup"`
 	LongStyleSearchBox bool             `json:"long_style_search_box"`
 	SelectedIds        []string         `json:"selected_ids" query:",omitempty"`
+	SelectAllMatching  bool             `json:"select_all_matching"`
 	Keyword            string           `json:"keyword" query:",omitempty"`
 	OrderBys           []ColOrderBy     `json:"order_bys" query:",omitempty"`
 	After              *string          `json:"after" query:",omitempty"`
@@ -63,6 +65,12 @@ type ListingCompo struct {
 	PerPage            int64            `json:"per_page" query:",omitempty;cookie"`
 	DisplayColumns     []*DisplayColumn `json:"display_columns" query:",omitempty;cookie"`
 	ActiveFilterTab    string           `json:"active_filter_tab" query:",omitempty"`
+	ActiveView         string           `json:"active_view" query:",omitempty"`
+	Trash              bool             `json:"trash" query:",omitempty"`
+	Layout             string           `json:"layout" query:",omitempty"`
+	CalendarRange      string           `json:"calendar_range" query:",omitempty"`
+	CalendarDate       string           `json:"calendar_date" query:",omitempty"`
+	TreeExpanded       []string         `json:"tree_expanded" query:",omitempty"`
 	FilterQuery        string           `json:"filter_query" query:";method:bare,f_"`
 
 	OnMounted string `json:"on_mounted"`
@@ -129,14 +137,17 @@ func (c *ListingCompo) MarshalHTML(ctx context.Context) (r []byte, err error) {
 			locals.dialog = false;
 			locals.document = el.ownerDocument;
 			locals.selected_ids = %s || [];
+			locals.select_all_matching = %t;
 			let orig = locals.%s;
 			locals.%s = function() {
 				let v = orig();
 				v.compo.selected_ids = this.selected_ids;
+				v.compo.select_all_matching = this.select_all_matching;
 				return v
 			}
 		}`,
 			h.JSONString(c.SelectedIds),
+			c.SelectAllMatching,
 			stateful.LocalsKeyNewAction,
 			stateful.LocalsKeyNewAction,
 		)),
@@ -150,6 +161,13 @@ func (c *ListingCompo) MarshalHTML(ctx context.Context) (r []byte, err error) {
 				c.lb.mb.NotifModelsDeleted(), fmt.Sprintf(`%s%s`, ListingCompo_JsPreFixWhenNotifModelsDeleted, stateful.ReloadAction(ctx, c, nil).Go()),
 			)
 		}),
+		h.Iff(!c.lb.disableModelListeners && len(c.lb.inlineEditFields) > 0, func() h.HTMLComponent {
+			return web.Listen(
+				c.lb.mb.NotifRowUpdated(), stateful.PostAction(ctx, c, c.ReloadRow, RowRequest{},
+					stateful.WithAppendFix(`v.request.id = payload.id;`),
+				).Go(),
+			)
+		}),
 		// the dialog is handled internally so that it can make good use of locals
 		web.Portal().Name(c.actionDialogPortalName()),
 		// user should locate it self
@@ -175,13 +193,23 @@ func (c *ListingCompo) MarshalHTML(ctx context.Context) (r []byte, err error) {
 }
 
 func (c *ListingCompo) tabsFilter(ctx context.Context) h.HTMLComponent {
-	if c.lb.filterTabsFunc == nil {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	trashAllowed := c.trashAllowed(evCtx)
+	if c.lb.filterTabsFunc == nil && c.lb.viewStore == nil && !trashAllowed {
 		return nil
 	}
-	evCtx, _ := c.MustGetEventContext(ctx)
 
 	activeIndex := -1
-	fts := c.lb.filterTabsFunc(evCtx)
+	var fts []*FilterTab
+	if c.lb.filterTabsFunc != nil {
+		fts = c.lb.filterTabsFunc(evCtx)
+	} else if trashAllowed {
+		// a tab to leave the trash
+		fts = []*FilterTab{{ID: "all", Label: msgr.ListingTrashAll}}
+		if !c.Trash && c.ActiveView == "" {
+			activeIndex = 0
+		}
+	}
 	tabs := VTabs().Class("mb-2").ShowArrows(true).Color(ColorPrimary).Density(DensityCompact)
 	for i, ft := range fts {
 		if ft.ID == "" {
@@ -198,7 +226,9 @@ func (c *ListingCompo) tabsFilter(ctx context.Context) h.HTMLComponent {
 					target.Page = 0
 					target.After, target.Before = nil, nil
 					target.ActiveFilterTab = ft.ID
+					target.ActiveView = ""
 					target.FilterQuery = encodedQuery
+					target.Trash = false
 				}).ThenScript(ListingCompo_JsScrollToTop).Go()).
 				Children(
 					h.Iff(ft.AdvancedLabel != nil, func() h.HTMLComponent {
@@ -209,7 +239,27 @@ func (c *ListingCompo) tabsFilter(ctx context.Context) h.HTMLComponent {
 				),
 		)
 	}
-	return tabs.ModelValue(activeIndex)
+	views := c.listViews(evCtx)
+	for i, view := range views {
+		if c.ActiveFilterTab == "" && c.matchView(view) {
+			activeIndex = len(fts) + i
+		}
+		tabs.AppendChildren(c.viewTab(ctx, view))
+	}
+	if trashAllowed {
+		if c.Trash {
+			activeIndex = len(fts) + len(views)
+		}
+		tabs.AppendChildren(c.trashTab(ctx))
+	}
+	saveViewButton := c.saveViewButton(ctx)
+	if saveViewButton == nil {
+		return tabs.ModelValue(activeIndex)
+	}
+	return h.Div(
+		tabs.ModelValue(activeIndex),
+		saveViewButton,
+	).Class("d-flex")
 }
 
 func (c *ListingCompo) textFieldSearchID() string {
@@ -273,6 +323,10 @@ func (c *ListingCompo) filterSearch(ctx context.Context, fd vx.FilterData) h.HTM
 			invisibleKeys[item.Key] = true
 		}
 	}
+	// the advanced filter is not managed by the filter component, keep it as the invisible ones
+	if c.lb.advancedFilterFieldsFunc != nil {
+		invisibleKeys[ParamAdvancedFilter] = true
+	}
 	if len(invisibleKeys) > 0 {
 		if c.FilterQuery != "" {
 			qs, err := url.ParseQuery(c.FilterQuery)
@@ -370,14 +424,24 @@ func (c *ListingCompo) toolbarSearch(ctx context.Context) h.HTMLComponent {
 	return VToolbar().Flat(true).Color("surface").AutoHeight(true).Class("pa-2").Class("filter-comp-wrap").Children(
 		textFieldSearch,
 		filterSearch,
+		c.advancedFilterButton(ctx),
+		c.layoutSwitcher(ctx),
 	)
 }
 
 func (c *ListingCompo) defaultCellWrapperFunc(cell h.MutableAttrHTMLComponent, id string, _ any, _ string) h.HTMLComponent {
-	if c.lb.mb.hasDetailing && !c.lb.mb.detailing.drawer {
-		cell.SetAttr("@click", web.Plaid().PushStateURL(c.lb.mb.Info().DetailingHref(id)).Go())
+	if c.Trash {
 		return cell
 	}
+	cell.SetAttr("@click", c.openRecordEvent(id))
+	return cell
+}
+
+// openRecordEvent opens the detailing page, the detailing drawer or the editing drawer of the record
+func (c *ListingCompo) openRecordEvent(id string) string {
+	if c.lb.mb.hasDetailing && !c.lb.mb.detailing.drawer {
+		return web.Plaid().PushStateURL(c.lb.mb.Info().DetailingHref(id)).Go()
+	}
 
 	event := actions.Edit
 	if c.lb.mb.hasDetailing {
@@ -391,8 +455,7 @@ func (c *ListingCompo) defaultCellWrapperFunc(cell h.MutableAttrHTMLComponent, i
 		onClick.Query(ParamParentID, c.ParentID)
 	}
 	onClick.Query(ParamVarCurrentActive, c.VarCurrentActive())
-	cell.SetAttr("@click", onClick.Go())
-	return cell
+	return onClick.Go()
 }
 
 func (c *ListingCompo) getOrderBys(colOrderBys []ColOrderBy, orderableFieldMap map[string]bool) []relay.OrderBy {
@@ -407,6 +470,9 @@ func (c *ListingCompo) getOrderBys(colOrderBys []ColOrderBy, orderableFieldMap m
 	}
 
 	if len(orderBys) == 0 {
+		if c.lb.sortable != nil {
+			return c.lb.sortable.orderBys(false)
+		}
 		if len(c.lb.defaultOrderBys) > 0 {
 			return c.lb.defaultOrderBys
 		}
@@ -419,15 +485,24 @@ func (c *ListingCompo) getOrderBys(colOrderBys []ColOrderBy, orderableFieldMap m
 
 func (c *ListingCompo) processFilter(evCtx *web.EventContext) (h.HTMLComponent, []*SQLCondition) {
 	var filterScript h.HTMLComponent
+	var conds []*SQLCondition
 	if c.lb.filterDataFunc != nil {
 		fd := c.lb.filterDataFunc(evCtx)
 		cond, args, vErr := fd.SetByQueryString(evCtx, c.FilterQuery)
 		if vErr.HaveErrors() && len(vErr.GetGlobalErrors()) > 0 {
 			filterScript = web.RunScript(fmt.Sprintf(`(el)=>{%s}`, ShowSnackbarScript(strings.Join(vErr.GetGlobalErrors(), ";"), "error")))
 		}
-		return filterScript, []*SQLCondition{{Query: cond, Args: args}}
+		conds = append(conds, &SQLCondition{Query: cond, Args: args})
 	}
-	return nil, nil
+	advancedCond, err := c.advancedFilterCondition(evCtx)
+	if err != nil {
+		filterScript = web.RunScript(fmt.Sprintf(`(el)=>{%s}`, ShowSnackbarScript(c.advancedFilterError(evCtx, err).Error(), "error")))
+		// nothing is listed rather than all the records
+		conds = append(conds, &SQLCondition{Query: "1 = 0"})
+	} else if advancedCond != nil {
+		conds = append(conds, advancedCond)
+	}
+	return filterScript, conds
 }
 
 func (c *ListingCompo) prepareRelayPaginateRequest(orderBys []relay.OrderBy, perPage int) *relay.PaginateRequest[any] {
@@ -456,8 +531,10 @@ func (c *ListingCompo) cellWrapperFunc(evCtx *web.EventContext) func(cell h.Muta
 	}
 }
 
-func (c *ListingCompo) rowWrapperFunc(evCtx *web.EventContext) func(row h.MutableAttrHTMLComponent, id string, obj any, _ string) h.HTMLComponent {
+func (c *ListingCompo) rowWrapperFunc(ctx context.Context) func(row h.MutableAttrHTMLComponent, id string, obj any, _ string) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
 	return func(row h.MutableAttrHTMLComponent, id string, obj any, _ string) h.HTMLComponent {
+		c.sortableRow(ctx, row, id, obj)
 		if c.lb.rowProcessor != nil {
 			compo, err := c.lb.rowProcessor(evCtx, row, id, obj)
 			if err != nil {
@@ -548,37 +625,42 @@ func (c *ListingCompo) setupBulkActions(ctx context.Context, dataTable *vx.DataT
 		return
 	}
 
-	_, msgr := c.MustGetEventContext(ctx)
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if c.inTrash(evCtx) {
+		return
+	}
 
 	syncQuery := ""
 	if stateful.IsSyncQuery(ctx) {
 		syncQuery = web.Plaid().PushState(true).MergeQuery(true).Query("selected_ids", web.Var(`selected_ids`)).RunPushState()
 	}
 	dataTable.SelectedIds(c.SelectedIds).
-		OnSelectionChanged(fmt.Sprintf(`function(selected_ids) { locals.selected_ids = selected_ids; %s }`, syncQuery)).
+		OnSelectionChanged(fmt.Sprintf(`function(selected_ids) { locals.selected_ids = selected_ids; locals.select_all_matching = false; %s }`, syncQuery)).
 		SelectedCountLabel(msgr.ListingSelectedCountNotice).
 		ClearSelectionLabel(msgr.ListingClearSelection)
 }
 
-func (c *ListingCompo) setupColumns(dataTable *vx.DataTableBuilder, columns []*Column) {
+// setupColumns adds the visible columns, the search snippets are shown under the first one
+func (c *ListingCompo) setupColumns(ctx context.Context, dataTable *vx.DataTableBuilder, columns []*Column, snippets map[string]h.HTMLComponent) {
 	for _, col := range columns {
 		if !col.Visible {
 			continue
 		}
 		// fill in empty compFunc and setter func with default
 		f := c.lb.getFieldOrDefault(col.Name)
-		dataTable.Column(col.Name).Title(col.Label).CellComponentFunc(c.lb.cellComponentFunc(f))
+		compFunc := c.cellComponentFunc(ctx, f)
+		if len(snippets) > 0 {
+			compFunc = withSnippet(compFunc, snippets)
+			snippets = nil
+		}
+		dataTable.Column(col.Name).Title(col.Label).CellComponentFunc(compFunc)
 	}
 }
 
-func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
-	if c.lb.Searcher == nil {
-		panic(errors.New("function Searcher is not set"))
-	}
-
-	evCtx, _ := c.MustGetEventContext(ctx)
-
-	searchParams := &SearchParams{
+// buildSearchParams collects the keyword, filter conditions, order bys and pagination
+// currently active in the listing into SearchParams.
+func (c *ListingCompo) buildSearchParams(evCtx *web.EventContext) (searchParams *SearchParams, colOrderBys []ColOrderBy, orderableFieldMap map[string]bool, filterScript h.HTMLComponent) {
+	searchParams = &SearchParams{
 		Model:         c.lb.mb.NewModel(),
 		PageURL:       evCtx.R.URL,
 		SQLConditions: c.lb.conditions,
@@ -589,7 +671,7 @@ func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
 		searchParams.Keyword = c.Keyword
 	}
 
-	colOrderBys := lo.Map(c.OrderBys, func(ob ColOrderBy, _ int) ColOrderBy {
+	colOrderBys = lo.Map(c.OrderBys, func(ob ColOrderBy, _ int) ColOrderBy {
 		ob.OrderBy = strings.ToUpper(ob.OrderBy)
 		if ob.OrderBy != OrderByASC && ob.OrderBy != OrderByDESC {
 			ob.OrderBy = OrderByDESC
@@ -597,7 +679,7 @@ func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
 		return ob
 	})
 
-	orderableFieldMap := make(map[string]bool)
+	orderableFieldMap = make(map[string]bool)
 	for _, v := range c.lb.orderableFields {
 		orderableFieldMap[v.FieldName] = true
 	}
@@ -615,12 +697,46 @@ func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
 
 	filterScript, filterConds := c.processFilter(evCtx)
 	searchParams.SQLConditions = append(searchParams.SQLConditions, filterConds...)
+	searchParams.Trashed = c.inTrash(evCtx)
 
 	if c.lb.relayPagination != nil {
 		searchParams.RelayPagination = c.lb.relayPagination
 		searchParams.RelayPaginateRequest = c.prepareRelayPaginateRequest(searchParams.OrderBys, int(searchParams.PerPage))
 	}
 
+	if err := c.applySearchIndex(evCtx, searchParams); err != nil {
+		panic(errors.Wrap(err, "search index error"))
+	}
+	return
+}
+
+func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
+	if c.lb.Searcher == nil {
+		panic(errors.New("function Searcher is not set"))
+	}
+
+	evCtx, _ := c.MustGetEventContext(ctx)
+
+	searchParams, colOrderBys, orderableFieldMap, filterScript := c.buildSearchParams(evCtx)
+
+	switch c.layout(evCtx) {
+	case ListingLayoutKanban:
+		return h.Components(
+			filterScript,
+			c.kanbanBoard(ctx, searchParams),
+		)
+	case ListingLayoutCalendar:
+		return h.Components(
+			filterScript,
+			c.calendarView(ctx, searchParams),
+		)
+	case ListingLayoutTree:
+		return h.Components(
+			filterScript,
+			c.treeView(ctx, searchParams),
+		)
+	}
+
 	searchResult, err := c.lb.Searcher(evCtx, searchParams)
 	if err != nil {
 		panic(errors.Wrap(err, "searcher error"))
@@ -631,15 +747,20 @@ func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
 		panic(errors.Wrap(err, "get columns error"))
 	}
 
+	snippets, err := c.searchSnippets(evCtx, searchParams.Keyword, searchResult.Nodes)
+	if err != nil {
+		panic(errors.Wrap(err, "search snippets error"))
+	}
+
 	dataTable := vx.DataTable(searchResult.Nodes).Hover(true).HoverClass("cursor-pointer").
 		HeadCellWrapperFunc(c.headCellWrapperFunc(ctx, columns, colOrderBys, orderableFieldMap)).
-		RowWrapperFunc(c.rowWrapperFunc(evCtx)).
+		RowWrapperFunc(c.rowWrapperFunc(ctx)).
 		RowMenuHead(btnConfigColumns).
-		RowMenuItemFuncs(c.lb.RowMenu().listingItemFuncs(evCtx)...).
+		RowMenuItemFuncs(c.rowMenuItemFuncs(ctx)...).
 		CellWrapperFunc(c.cellWrapperFunc(evCtx))
 
 	c.setupBulkActions(ctx, dataTable)
-	c.setupColumns(dataTable, columns)
+	c.setupColumns(ctx, dataTable, columns, snippets)
 
 	if c.lb.tableProcessor != nil {
 		dataTable, err = c.lb.tableProcessor(evCtx, dataTable)
@@ -650,11 +771,39 @@ func (c *ListingCompo) dataTable(ctx context.Context) h.HTMLComponent {
 
 	return h.Components(
 		filterScript,
+		c.selectAllMatchingBanner(ctx, searchResult),
 		dataTable,
 		c.buildDataTableAdditions(ctx, searchParams, searchResult),
 	)
 }
 
+// selectAllMatchingBanner offers to select all the records matching the listing for the bulk actions
+// once all the records on the page are selected
+func (c *ListingCompo) selectAllMatchingBanner(ctx context.Context, searchResult *SearchResult) h.HTMLComponent {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	nodes := reflect.ValueOf(searchResult.Nodes)
+	if len(c.lb.bulkActions) == 0 || c.inTrash(evCtx) || nodes.Kind() != reflect.Slice {
+		return nil
+	}
+	onPage, total := nodes.Len(), searchResult.PageInfo.TotalCount
+	if onPage == 0 || total <= onPage {
+		return nil
+	}
+	return VAlert(
+		h.Div(
+			h.Text(msgr.ListingAllOnPageSelected(onPage)),
+			VBtn(msgr.ListingSelectAllMatching(total)).Variant(VariantText).Color(ColorPrimary).Size(SizeSmall).
+				Attr("@click", "locals.select_all_matching = true"),
+		).Class("d-flex align-center ga-2").Attr("v-if", "!locals.select_all_matching"),
+		h.Div(
+			h.Text(msgr.ListingAllMatchingSelected(total)),
+			VBtn(msgr.ListingSelectOnlyThisPage).Variant(VariantText).Color(ColorPrimary).Size(SizeSmall).
+				Attr("@click", "locals.select_all_matching = false"),
+		).Class("d-flex align-center ga-2").Attr("v-else", true),
+	).Type("info").Variant(VariantTonal).Density(DensityCompact).Class("mb-2").
+		Attr("v-if", fmt.Sprintf("locals.selected_ids && locals.selected_ids.length >= %d", onPage))
+}
+
 func (c *ListingCompo) buildDataTableAdditions(ctx context.Context, searchParams *SearchParams, searchResult *SearchResult) h.HTMLComponent {
 	if searchResult.PageInfo.TotalCount <= 0 && searchResult.PageInfo.StartCursor == nil {
 		_, msgr := c.MustGetEventContext(ctx)
@@ -765,7 +914,8 @@ func (c *ListingCompo) getColumns(ctx context.Context) (btnConfigure h.HTMLCompo
 
 	var availableColumns []*DisplayColumn
 	for _, f := range c.lb.fields {
-		if c.lb.mb.Info().Verifier().Do(PermList).SnakeOn("f_"+f.name).WithReq(evCtx.R).IsAllowed() != nil {
+		if c.lb.mb.Info().Verifier().Do(PermList).SnakeOn("f_"+f.name).WithReq(evCtx.R).IsAllowed() != nil ||
+			!c.lb.mb.Info().FieldReadable(evCtx.R, f.name) {
 			continue
 		}
 		availableColumns = append(availableColumns, &DisplayColumn{
@@ -898,7 +1048,12 @@ func (c *ListingCompo) actionsComponent(ctx context.Context) (r h.HTMLComponent)
 
 	var buttons []h.HTMLComponent
 
-	for _, ba := range c.lb.bulkActions {
+	bulkActions := c.lb.bulkActions
+	if c.inTrash(evCtx) {
+		// the bulk actions work on the records which are not deleted
+		bulkActions = nil
+	}
+	for _, ba := range bulkActions {
 		if c.lb.mb.Info().Verifier().SnakeDo(permBulkActions, ba.name).WithReq(evCtx.R).IsAllowed() != nil {
 			continue
 		}
@@ -936,6 +1091,21 @@ func (c *ListingCompo) actionsComponent(ctx context.Context) (r h.HTMLComponent)
 		)
 	}
 
+	if btnExport := c.exportButton(evCtx, msgr); btnExport != nil {
+		buttons = append(buttons, btnExport)
+	}
+
+	if ib := c.lb.mb.importing; ib != nil && ib.importable(evCtx) {
+		buttons = append(buttons, VBtn(msgr.Import).
+			Color(ColorSecondary).Variant(VariantFlat).Class("ml-2").
+			PrependIcon("mdi-upload").
+			Attr("@click", web.Plaid().
+				EventFunc(actions.OpenImportDialog).
+				URL(c.lb.mb.Info().ListingHref()).
+				Go()),
+		)
+	}
+
 	buttonNew := func() h.HTMLComponent {
 		if c.lb.mb.Info().Verifier().Do(PermCreate).WithReq(evCtx.R).IsAllowed() != nil {
 			return nil
@@ -973,6 +1143,37 @@ func (c *ListingCompo) actionsComponent(ctx context.Context) (r h.HTMLComponent)
 	return h.Div(buttons...)
 }
 
+func (c *ListingCompo) exportButton(evCtx *web.EventContext, msgr *Messages) h.HTMLComponent {
+	eb := c.lb.exporting
+	if eb == nil || len(eb.formats) == 0 {
+		return nil
+	}
+	if c.lb.mb.Info().Verifier().Do(PermExport).WithReq(evCtx.R).IsAllowed() != nil {
+		return nil
+	}
+
+	if len(eb.formats) == 1 {
+		return VBtn(msgr.Export).
+			Color(ColorSecondary).Variant(VariantFlat).Class("ml-2").
+			PrependIcon("mdi-download").
+			Href(eb.href(c, eb.formats[0]))
+	}
+
+	return VMenu().Children(
+		web.Slot().Name("activator").Scope("{ props }").Children(
+			VBtn(msgr.Export).
+				Color(ColorSecondary).Variant(VariantFlat).Class("ml-2").
+				PrependIcon("mdi-download").
+				Attr("v-bind", "props"),
+		),
+		VList(lo.Map(eb.formats, func(format ExportFormat, _ int) h.HTMLComponent {
+			return VListItem(
+				VListItemTitle(h.Text(msgr.ExportAs(strings.ToUpper(string(format))))),
+			).Href(eb.href(c, format))
+		})...).Density(DensityCompact),
+	)
+}
+
 func (c *ListingCompo) bulkPanel(ctx context.Context, bulk *BulkActionBuilder, selectedIds []string, actionableIds []string) h.HTMLComponent {
 	evCtx, msgr := c.MustGetEventContext(ctx)
 
@@ -984,7 +1185,16 @@ func (c *ListingCompo) bulkPanel(ctx context.Context, bulk *BulkActionBuilder, s
 	}
 
 	var alertCompo h.HTMLComponent
-	if len(actionableIds) < len(selectedIds) {
+	if c.SelectAllMatching {
+		total, err := c.countMatching(evCtx)
+		if err != nil {
+			if errCompo == nil {
+				errCompo = VAlert(h.Text(err.Error())).Border("left").Type("error").Elevation(2)
+			}
+		} else {
+			alertCompo = VAlert(h.Text(msgr.ListingAllMatchingSelected(total))).Type("info").Variant(VariantTonal)
+		}
+	} else if len(actionableIds) < len(selectedIds) {
 		unactionables := lo.Without(selectedIds, actionableIds...)
 		if len(unactionables) > 0 {
 			var notice string
@@ -1046,7 +1256,7 @@ func (c *ListingCompo) fetchBulkAction(ctx context.Context, name string) (*BulkA
 		return nil, err
 	}
 
-	if len(c.SelectedIds) == 0 {
+	if len(c.SelectedIds) == 0 && !c.SelectAllMatching {
 		return nil, errors.New(msgr.BulkActionNoRecordsSelected)
 	}
 
@@ -1066,6 +1276,13 @@ func (c *ListingCompo) OpenBulkActionDialog(ctx context.Context, req OpenBulkAct
 		return r, nil
 	}
 
+	if c.SelectAllMatching {
+		// the selected ids processor is applied to the batches when the action runs
+		evCtx.WithContextValue(ctxKeyBulkActionSearchParams{}, c.matchingSearchParams(evCtx))
+		c.dialog(&r, c.bulkPanel(ctx, bulk, c.SelectedIds, c.SelectedIds), bulk.dialogWidth)
+		return r, nil
+	}
+
 	actionableIds := c.SelectedIds
 	if bulk.selectedIdsProcessorFunc != nil {
 		actionableIds, err = bulk.selectedIdsProcessorFunc(c.SelectedIds, evCtx)
@@ -1100,11 +1317,29 @@ func (c *ListingCompo) DoBulkAction(ctx context.Context, req DoBulkActionRequest
 		return r, nil
 	}
 
+	if c.SelectAllMatching {
+		if err = c.doMatchingBulkAction(ctx, bulk, &r); err != nil || evCtx.Flash != nil {
+			if evCtx.Flash == nil {
+				evCtx.Flash = toValidationErrors(err)
+			}
+			r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+				Name: c.actionDialogContentPortalName(),
+				Body: c.bulkPanel(ctx, bulk, c.SelectedIds, c.SelectedIds),
+			})
+		}
+		return r, nil
+	}
+
 	actionableIds := c.SelectedIds
 	if bulk.selectedIdsProcessorFunc != nil {
 		actionableIds, err = bulk.selectedIdsProcessorFunc(c.SelectedIds, evCtx)
 	}
 
+	var undo UndoFunc
+	if err == nil && bulk.undoFunc != nil {
+		undo, err = bulk.undoFunc(actionableIds, evCtx)
+	}
+
 	if err == nil {
 		err = bulk.updateFunc(actionableIds, evCtx, &r)
 	}
@@ -1120,6 +1355,9 @@ func (c *ListingCompo) DoBulkAction(ctx context.Context, req DoBulkActionRequest
 		return r, nil
 	}
 
+	if undo != nil {
+		c.lb.mb.p.ShowUndoMessage(&r, evCtx, MustGetMessages(evCtx.R).SuccessfullyUpdated, undo)
+	}
 	web.AppendRunScripts(&r, c.closeActionDialog())
 	return r, nil
 }
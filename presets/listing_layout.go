@@ -0,0 +1,125 @@
+package presets
+
+import (
+	"context"
+	"slices"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+const (
+	ListingLayoutTable    = ""
+	ListingLayoutKanban   = "kanban"
+	ListingLayoutCalendar = "calendar"
+	ListingLayoutTree     = "tree"
+)
+
+type listingLayout struct {
+	name  string
+	icon  string
+	label string
+}
+
+func (c *ListingCompo) layouts(evCtx *web.EventContext) (r []*listingLayout) {
+	msgr := MustGetMessages(evCtx.R)
+	r = append(r, &listingLayout{name: ListingLayoutTable, icon: "mdi-table", label: msgr.ListingLayoutTable})
+	if c.lb.kanban != nil {
+		r = append(r, &listingLayout{name: ListingLayoutKanban, icon: "mdi-view-column-outline", label: msgr.ListingLayoutKanban})
+	}
+	if c.lb.calendar != nil {
+		r = append(r, &listingLayout{name: ListingLayoutCalendar, icon: "mdi-calendar-month-outline", label: msgr.ListingLayoutCalendar})
+	}
+	if c.lb.tree != nil {
+		r = append(r, &listingLayout{name: ListingLayoutTree, icon: "mdi-file-tree-outline", label: msgr.ListingLayoutTree})
+	}
+	return r
+}
+
+// layout returns the layout in use, the trash is always listed in the table
+func (c *ListingCompo) layout(evCtx *web.EventContext) string {
+	if c.Layout == ListingLayoutTable || c.inTrash(evCtx) {
+		return ListingLayoutTable
+	}
+	for _, l := range c.layouts(evCtx) {
+		if l.name == c.Layout {
+			return l.name
+		}
+	}
+	return ListingLayoutTable
+}
+
+func (c *ListingCompo) layoutSwitcher(ctx context.Context) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	layouts := c.layouts(evCtx)
+	if len(layouts) <= 1 || c.inTrash(evCtx) {
+		return nil
+	}
+
+	current := c.layout(evCtx)
+	var btns []h.HTMLComponent
+	for _, l := range layouts {
+		btns = append(btns, VBtn("").Icon(l.icon).Size(SizeSmall).Value(l.name).Attr("title", l.label).
+			Attr("@click", stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+				target.Layout = l.name
+				target.Page = 0
+				target.After, target.Before = nil, nil
+				target.SelectedIds = nil
+			}).Go()))
+	}
+	return h.Components(
+		VSpacer(),
+		VBtnToggle(btns...).ModelValue(current).Mandatory(true).Density(DensityCompact).
+			Variant(VariantOutlined).Divided(true),
+	)
+}
+
+// recordSummary renders the first listing field of the record except the excluded ones,
+// it is the default content of the kanban cards and the calendar entries.
+func (c *ListingCompo) recordSummary(evCtx *web.EventContext, obj any, excludes ...string) h.HTMLComponent {
+	for _, f := range c.lb.fields {
+		if slices.Contains(excludes, f.name) || !c.lb.mb.Info().FieldReadable(evCtx.R, f.name) {
+			continue
+		}
+		content, ok := cellContent(c.lb.cellComponentFunc(f)(obj, f.name, evCtx))
+		if !ok {
+			break
+		}
+		return content
+	}
+	return h.Text(ObjectID(obj))
+}
+
+// saveMovedRecord saves the record which is moved on the kanban or the calendar with the editing validator and saver
+func (c *ListingCompo) saveMovedRecord(r *web.EventResponse, evCtx *web.EventContext, obj any, id string) {
+	msgr := MustGetMessages(evCtx.R)
+	eb := c.lb.mb.editing
+	if vErr := eb.Validate(obj, evCtx); vErr.HaveErrors() {
+		msg := vErr.GetGlobalError()
+		if msg == "" {
+			msg = msgr.ListingMoveInvalid
+			for _, f := range eb.fields {
+				if errs := vErr.GetFieldErrors(f.name); len(errs) > 0 {
+					msg = errs[0]
+					break
+				}
+			}
+		}
+		ShowMessage(r, msg, ColorWarning)
+		return
+	}
+
+	if err := c.lb.mb.checkVersion(evCtx, obj); err != nil {
+		ShowMessage(r, err.Error(), ColorError)
+		return
+	}
+	if err := eb.Saver(obj, id, evCtx); err != nil {
+		ShowMessage(r, err.Error(), ColorError)
+		return
+	}
+
+	ShowMessage(r, msgr.SuccessfullyUpdated, "")
+	r.Emit(c.lb.mb.NotifModelsUpdated(), PayloadModelsUpdated{Ids: []string{id}, Models: map[string]any{id: obj}})
+}
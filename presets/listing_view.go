@@ -0,0 +1,265 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/samber/lo"
+	h "github.com/theplant/htmlgo"
+)
+
+// ListingView is a named snapshot of the listing state, like "My pending orders"
+type ListingView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// SharedRole is the role the view is shared with, empty means the view is private
+	SharedRole string `json:"shared_role"`
+	// Editable reports whether the current user is allowed to update or delete the view
+	Editable bool `json:"editable"`
+
+	Keyword        string           `json:"keyword"`
+	FilterQuery    string           `json:"filter_query"`
+	OrderBys       []ColOrderBy     `json:"order_bys"`
+	DisplayColumns []*DisplayColumn `json:"display_columns"`
+	PerPage        int64            `json:"per_page"`
+}
+
+// ListingViewStore persists the saved listing views, modelName is the uri name of the model
+type ListingViewStore interface {
+	// ListViews returns the views of the model which are visible to the current user
+	ListViews(ctx *web.EventContext, modelName string) ([]*ListingView, error)
+	// ShareableRoles returns the roles which the current user can share views with
+	ShareableRoles(ctx *web.EventContext) ([]string, error)
+	// SaveView creates the view if its ID is empty, otherwise updates it
+	SaveView(ctx *web.EventContext, modelName string, view *ListingView) error
+	DeleteView(ctx *web.EventContext, modelName string, id string) error
+}
+
+// ViewStore enables saved views, they are shown next to the filter tabs
+func (b *ListingBuilder) ViewStore(v ListingViewStore) (r *ListingBuilder) {
+	b.viewStore = v
+	return b
+}
+
+const (
+	paramViewName       = "ListingViewName"
+	paramViewSharedRole = "ListingViewSharedRole"
+)
+
+func (c *ListingCompo) listViews(evCtx *web.EventContext) []*ListingView {
+	if c.lb.viewStore == nil {
+		return nil
+	}
+	views, err := c.lb.viewStore.ListViews(evCtx, c.lb.mb.Info().URIName())
+	if err != nil {
+		// the listing is still rendered without the views
+		c.lb.mb.p.logger.Error(fmt.Sprintf("list views of %s failed: %v", c.lb.mb.uriName, err))
+		return nil
+	}
+	return views
+}
+
+func (c *ListingCompo) matchView(view *ListingView) bool {
+	return c.ActiveView == view.ID &&
+		c.Keyword == view.Keyword &&
+		c.FilterQuery == view.FilterQuery &&
+		slices.Equal(c.OrderBys, view.OrderBys)
+}
+
+func (c *ListingCompo) viewTab(ctx context.Context, view *ListingView) h.HTMLComponent {
+	icon := "mdi-bookmark-outline"
+	if view.SharedRole != "" {
+		icon = "mdi-bookmark-multiple-outline"
+	}
+	return VTab(
+		VIcon(icon).Size(SizeSmall).Class("mr-1"),
+		h.Text(view.Name),
+	).Attr("@click", stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+		target.Page = 0
+		target.After, target.Before = nil, nil
+		target.ActiveFilterTab = ""
+		target.ActiveView = view.ID
+		target.Trash = false
+		target.Keyword = view.Keyword
+		target.FilterQuery = view.FilterQuery
+		target.OrderBys = view.OrderBys
+		if len(view.DisplayColumns) > 0 {
+			target.DisplayColumns = view.DisplayColumns
+		}
+		if view.PerPage > 0 {
+			target.PerPage = view.PerPage
+		}
+	}).ThenScript(ListingCompo_JsScrollToTop).Go())
+}
+
+func (c *ListingCompo) saveViewButton(ctx context.Context) h.HTMLComponent {
+	if c.lb.viewStore == nil {
+		return nil
+	}
+	_, msgr := c.MustGetEventContext(ctx)
+	return VBtn("").Icon("mdi-bookmark-plus-outline").
+		Variant(VariantText).Size(SizeSmall).Class("ml-2 align-self-center").
+		Attr("title", msgr.ListingViewSave).
+		Attr("@click", stateful.PostAction(ctx, c, c.OpenSaveViewDialog, struct{}{}).Go())
+}
+
+func (c *ListingCompo) activeEditableView(evCtx *web.EventContext) *ListingView {
+	if c.ActiveView == "" {
+		return nil
+	}
+	view, _ := lo.Find(c.listViews(evCtx), func(v *ListingView) bool {
+		return v.ID == c.ActiveView && v.Editable
+	})
+	return view
+}
+
+func (c *ListingCompo) saveViewPanel(ctx context.Context, current *ListingView) h.HTMLComponent {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+
+	roles, err := c.lb.viewStore.ShareableRoles(evCtx)
+	if err != nil {
+		// the view can still be saved as a private one
+		c.lb.mb.p.logger.Error(fmt.Sprintf("shareable roles of %s failed: %v", c.lb.mb.uriName, err))
+	}
+
+	var errCompo h.HTMLComponent
+	if vErr, ok := evCtx.Flash.(*web.ValidationErrors); ok {
+		if gErr := vErr.GetGlobalError(); gErr != "" {
+			errCompo = VAlert(h.Text(gErr)).Border("left").Type("error").Elevation(2)
+		}
+	}
+
+	var name, sharedRole string
+	if current != nil {
+		name, sharedRole = current.Name, current.SharedRole
+	}
+	shareItems := []map[string]string{{"title": msgr.ListingViewPrivate, "value": ""}}
+	for _, role := range roles {
+		shareItems = append(shareItems, map[string]string{"title": role, "value": role})
+	}
+
+	save := func(asNew bool) string {
+		return stateful.PostAction(ctx, c, c.SaveView, SaveViewRequest{AsNew: asNew}).Go()
+	}
+	return VCard(
+		VCardTitle(h.Text(msgr.ListingViewSave)),
+		VCardText(
+			errCompo,
+			VTextField().Label(msgr.ListingViewName).Variant(FieldVariantUnderlined).
+				Attr(web.VField(paramViewName, name)...),
+			VSelect().Label(msgr.ListingViewShareWith).Variant(FieldVariantUnderlined).
+				Items(shareItems).ItemTitle("title").ItemValue("value").
+				Attr(web.VField(paramViewSharedRole, sharedRole)...),
+		),
+		VCardActions(
+			h.Iff(current != nil, func() h.HTMLComponent {
+				return VBtn(msgr.Delete).Variant(VariantText).Color(ColorError).
+					Attr("@click", stateful.PostAction(ctx, c, c.DeleteView, struct{}{}).Go())
+			}),
+			VSpacer(),
+			VBtn(msgr.Cancel).Variant(VariantFlat).Class("ml-2").Attr("@click", c.closeActionDialog()),
+			h.Iff(current != nil, func() h.HTMLComponent {
+				return VBtn(msgr.ListingViewSaveAsNew).Variant(VariantTonal).Color(ColorPrimary).
+					Attr("@click", save(true))
+			}),
+			VBtn(msgr.Save).Color(ColorPrimary).Variant(VariantFlat).Theme(ThemeDark).
+				Attr("@click", save(false)),
+		),
+	)
+}
+
+func (c *ListingCompo) OpenSaveViewDialog(ctx context.Context, _ struct{}) (r web.EventResponse, err error) {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	if c.lb.viewStore == nil {
+		return r, errors.New("view store is not set")
+	}
+	c.dialog(&r, c.saveViewPanel(ctx, c.activeEditableView(evCtx)), "500")
+	return r, nil
+}
+
+type SaveViewRequest struct {
+	AsNew bool `json:"as_new"`
+}
+
+func (c *ListingCompo) SaveView(ctx context.Context, req SaveViewRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if c.lb.viewStore == nil {
+		return r, errors.New("view store is not set")
+	}
+
+	current := c.activeEditableView(evCtx)
+	view := &ListingView{}
+	if current != nil && !req.AsNew {
+		view.ID = current.ID
+	}
+	view.Name = evCtx.R.FormValue(paramViewName)
+	view.SharedRole = evCtx.R.FormValue(paramViewSharedRole)
+	view.Keyword = c.Keyword
+	view.FilterQuery = c.FilterQuery
+	view.OrderBys = c.OrderBys
+	view.DisplayColumns = c.DisplayColumns
+	view.PerPage = c.PerPage
+
+	if view.Name == "" {
+		err = errors.New(msgr.ListingViewNameRequired)
+	}
+	if err == nil && view.SharedRole != "" {
+		var roles []string
+		roles, err = c.lb.viewStore.ShareableRoles(evCtx)
+		if err == nil && !slices.Contains(roles, view.SharedRole) {
+			err = errors.New(msgr.ListingViewCannotShare)
+		}
+	}
+	if err == nil {
+		err = c.lb.viewStore.SaveView(evCtx, c.lb.mb.Info().URIName(), view)
+	}
+	if err != nil {
+		evCtx.Flash = toValidationErrors(err)
+		r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+			Name: c.actionDialogContentPortalName(),
+			Body: c.saveViewPanel(ctx, current),
+		})
+		return r, nil
+	}
+
+	ShowMessage(&r, msgr.SuccessfullyUpdated, "")
+	web.AppendRunScripts(&r,
+		c.closeActionDialog(),
+		stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+			target.ActiveFilterTab = ""
+			target.ActiveView = view.ID
+		}).Go(),
+	)
+	return r, nil
+}
+
+func (c *ListingCompo) DeleteView(ctx context.Context, _ struct{}) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if c.lb.viewStore == nil {
+		return r, errors.New("view store is not set")
+	}
+
+	current := c.activeEditableView(evCtx)
+	if current == nil {
+		ShowMessage(&r, msgr.ListingViewNotFound, ColorError)
+		return r, nil
+	}
+	if err := c.lb.viewStore.DeleteView(evCtx, c.lb.mb.Info().URIName(), current.ID); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	ShowMessage(&r, msgr.ListingViewDeleted, "")
+	web.AppendRunScripts(&r,
+		c.closeActionDialog(),
+		stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+			target.ActiveView = ""
+		}).Go(),
+	)
+	return r, nil
+}
@@ -0,0 +1,49 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	h "github.com/theplant/htmlgo"
+)
+
+type failingViewStore struct{}
+
+var errViewStore = errors.New("view store is down")
+
+func (failingViewStore) ListViews(ctx *web.EventContext, modelName string) ([]*ListingView, error) {
+	return nil, errViewStore
+}
+
+func (failingViewStore) ShareableRoles(ctx *web.EventContext) ([]string, error) {
+	return nil, errViewStore
+}
+
+func (failingViewStore) SaveView(ctx *web.EventContext, modelName string, view *ListingView) error {
+	return errViewStore
+}
+
+func (failingViewStore) DeleteView(ctx *web.EventContext, modelName string, id string) error {
+	return errViewStore
+}
+
+func TestListingViewStoreError(t *testing.T) {
+	mb := New().Model(&foo{})
+	mb.Listing().ViewStore(failingViewStore{})
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("GET", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing(), ActiveView: "1"}
+
+	assert.Empty(t, c.listViews(evCtx))
+	assert.Nil(t, c.activeEditableView(evCtx))
+	assert.NotPanics(t, func() {
+		h.MustString(c.tabsFilter(ctx), ctx)
+	})
+	body := h.MustString(c.saveViewPanel(ctx, nil), ctx)
+	assert.Contains(t, body, Messages_en_US.ListingViewPrivate)
+}
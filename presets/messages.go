@@ -1,6 +1,7 @@
 package presets
 
 import (
+	"fmt"
 	"math"
 	"strings"
 	"time"
@@ -92,6 +93,122 @@ type Messages struct {
 	HumanizeTimeLongWhile string
 
 	LeaveBeforeUnsubmit string
+
+	Export           string
+	ExportAsTemplate string
+
+	Import                       string
+	ImportFile                   string
+	ImportDryRun                 string
+	ImportFileRequired           string
+	ImportNoRows                 string
+	ImportNothingImported        string
+	ImportLine                   string
+	ImportErrors                 string
+	ImportTitleTemplate          string
+	ImportTooManyRowsTemplate    string
+	ImportSuccessfullyTemplate   string
+	ImportIgnoredColumnsTemplate string
+	ImportValidRowsTemplate      string
+
+	ListingViewSave         string
+	ListingViewSaveAsNew    string
+	ListingViewName         string
+	ListingViewShareWith    string
+	ListingViewPrivate      string
+	ListingViewNameRequired string
+	ListingViewCannotShare  string
+	ListingViewNotFound     string
+	ListingViewDeleted      string
+
+	VersionConflictTitle        string
+	VersionConflictNotice       string
+	VersionConflictField        string
+	VersionConflictLatest       string
+	VersionConflictYours        string
+	VersionConflictDiscardYours string
+	VersionConflictKeepYours    string
+
+	ListingTrash                  string
+	ListingTrashAll               string
+	ListingTrashRestore           string
+	ListingTrashPurge             string
+	ListingTrashPurgeConfirmation string
+	ListingTrashRestored          string
+	ListingTrashPurged            string
+
+	ListingLayoutTable  string
+	ListingLayoutKanban string
+	ListingMoveInvalid  string
+
+	ListingLayoutCalendar string
+	ListingCalendarMonth  string
+	ListingCalendarWeek   string
+	ListingCalendarDay    string
+	ListingCalendarToday  string
+
+	AdvancedFilter                   string
+	AdvancedFilterAnd                string
+	AdvancedFilterOr                 string
+	AdvancedFilterAddCondition       string
+	AdvancedFilterAddGroup           string
+	AdvancedFilterField              string
+	AdvancedFilterOperator           string
+	AdvancedFilterValue              string
+	AdvancedFilterInvalid            string
+	AdvancedFilterNotEquals          string
+	AdvancedFilterNotContains        string
+	AdvancedFilterStartsWith         string
+	AdvancedFilterGreaterThanOrEqual string
+	AdvancedFilterLessThanOrEqual    string
+	AdvancedFilterIsTrue             string
+	AdvancedFilterIsFalse            string
+	AdvancedFilterIsEmpty            string
+	AdvancedFilterIsNotEmpty         string
+
+	Undo                string
+	Undone              string
+	UndoExpired         string
+	SuccessfullyDeleted string
+
+	DraftFound    string
+	DraftRestore  string
+	DraftDiscard  string
+	DraftNotFound string
+
+	GlobalSearch            string
+	GlobalSearchPlaceholder string
+	GlobalSearchNoResults   string
+	GlobalSearchTimedOut    string
+
+	ListingAllOnPageSelectedTemplate   string
+	ListingSelectAllMatchingTemplate   string
+	ListingAllMatchingSelectedTemplate string
+	ListingSelectOnlyThisPage          string
+	BulkActionProgressTemplate         string
+	BulkActionRunningInBackground      string
+	BulkActionNotFound                 string
+
+	ValidationRequired          string
+	ValidationMinLengthTemplate string
+	ValidationMaxLengthTemplate string
+	ValidationMinTemplate       string
+	ValidationMaxTemplate       string
+	ValidationInvalidFormat     string
+	ValidationInvalidEmail      string
+	ValidationNotUnique         string
+
+	WizardBack       string
+	WizardNext       string
+	WizardDraftSaved string
+
+	AssociationPickerLoadMore string
+
+	ListingLayoutTree         string
+	ListingTreeMoveToRoot     string
+	ListingTreeMoveIntoItself string
+
+	Duplicate string
 }
 
 func (msgr *Messages) CreatingObjectTitle(modelName string) string {
@@ -119,6 +236,76 @@ func (msgr *Messages) BulkActionSelectedIdsProcessNotice(ids string) string {
 		Replace(msgr.BulkActionSelectedIdsProcessNoticeTemplate)
 }
 
+func (msgr *Messages) ListingAllOnPageSelected(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.ListingAllOnPageSelectedTemplate)
+}
+
+func (msgr *Messages) ListingSelectAllMatching(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.ListingSelectAllMatchingTemplate)
+}
+
+func (msgr *Messages) ListingAllMatchingSelected(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.ListingAllMatchingSelectedTemplate)
+}
+
+func (msgr *Messages) BulkActionProgress(done, total int) string {
+	return strings.NewReplacer("{done}", fmt.Sprint(done), "{total}", fmt.Sprint(total)).
+		Replace(msgr.BulkActionProgressTemplate)
+}
+
+func (msgr *Messages) ValidationMinLength(min string) string {
+	return strings.NewReplacer("{min}", min).
+		Replace(msgr.ValidationMinLengthTemplate)
+}
+
+func (msgr *Messages) ValidationMaxLength(max string) string {
+	return strings.NewReplacer("{max}", max).
+		Replace(msgr.ValidationMaxLengthTemplate)
+}
+
+func (msgr *Messages) ValidationMin(min string) string {
+	return strings.NewReplacer("{min}", min).
+		Replace(msgr.ValidationMinTemplate)
+}
+
+func (msgr *Messages) ValidationMax(max string) string {
+	return strings.NewReplacer("{max}", max).
+		Replace(msgr.ValidationMaxTemplate)
+}
+
+func (msgr *Messages) ExportAs(format string) string {
+	return strings.NewReplacer("{format}", format).
+		Replace(msgr.ExportAsTemplate)
+}
+
+func (msgr *Messages) ImportTitle(modelName string) string {
+	return strings.NewReplacer("{modelName}", modelName).
+		Replace(msgr.ImportTitleTemplate)
+}
+
+func (msgr *Messages) ImportTooManyRows(max int) string {
+	return strings.NewReplacer("{max}", fmt.Sprint(max)).
+		Replace(msgr.ImportTooManyRowsTemplate)
+}
+
+func (msgr *Messages) ImportSuccessfully(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.ImportSuccessfullyTemplate)
+}
+
+func (msgr *Messages) ImportIgnoredColumns(columns string) string {
+	return strings.NewReplacer("{columns}", columns).
+		Replace(msgr.ImportIgnoredColumnsTemplate)
+}
+
+func (msgr *Messages) ImportValidRows(valid, total int) string {
+	return strings.NewReplacer("{valid}", fmt.Sprint(valid), "{total}", fmt.Sprint(total)).
+		Replace(msgr.ImportValidRowsTemplate)
+}
+
 func (msgr *Messages) FilterBy(filter string) string {
 	return strings.NewReplacer("{filter}", filter).
 		Replace(msgr.FilterByTemplate)
@@ -232,6 +419,122 @@ var Messages_en_US = &Messages{
 	HumanizeTimeLongWhile: "a long while %s",
 
 	LeaveBeforeUnsubmit: "If you leave before submitting the form, you will lose all the unsaved input.",
+
+	Export:           "Export",
+	ExportAsTemplate: "Export as {format}",
+
+	Import:                       "Import",
+	ImportFile:                   "CSV File",
+	ImportDryRun:                 "Validate",
+	ImportFileRequired:           "Please choose a file to import",
+	ImportNoRows:                 "No rows found in the file",
+	ImportNothingImported:        "Some rows are invalid, nothing has been imported",
+	ImportLine:                   "Line",
+	ImportErrors:                 "Errors",
+	ImportTitleTemplate:          "Import {modelName}",
+	ImportTooManyRowsTemplate:    "The file has more than {max} rows",
+	ImportSuccessfullyTemplate:   "{count} rows imported successfully",
+	ImportIgnoredColumnsTemplate: "Ignored columns: {columns}",
+	ImportValidRowsTemplate:      "{valid} of {total} rows are valid",
+
+	ListingViewSave:         "Save View",
+	ListingViewSaveAsNew:    "Save as New",
+	ListingViewName:         "View Name",
+	ListingViewShareWith:    "Share With",
+	ListingViewPrivate:      "Only me",
+	ListingViewNameRequired: "View name is required",
+	ListingViewCannotShare:  "You cannot share the view with this role",
+	ListingViewNotFound:     "The view does not exist or you cannot edit it",
+	ListingViewDeleted:      "View deleted",
+
+	VersionConflictTitle:        "Edit Conflict",
+	VersionConflictNotice:       "This record has been modified by someone else since you opened it. Your changes are kept in the form, saving again will overwrite the latest version.",
+	VersionConflictField:        "Field",
+	VersionConflictLatest:       "Latest Version",
+	VersionConflictYours:        "Your Changes",
+	VersionConflictDiscardYours: "Discard My Changes",
+	VersionConflictKeepYours:    "Keep My Changes",
+
+	ListingTrash:                  "Trash",
+	ListingTrashAll:               "All",
+	ListingTrashRestore:           "Restore",
+	ListingTrashPurge:             "Delete Permanently",
+	ListingTrashPurgeConfirmation: "Are you sure you want to delete this object permanently? This cannot be undone.",
+	ListingTrashRestored:          "Successfully Restored",
+	ListingTrashPurged:            "Successfully Deleted Permanently",
+
+	ListingLayoutTable:  "Table",
+	ListingLayoutKanban: "Board",
+	ListingMoveInvalid:  "The record can't be moved here",
+
+	ListingLayoutCalendar: "Calendar",
+	ListingCalendarMonth:  "Month",
+	ListingCalendarWeek:   "Week",
+	ListingCalendarDay:    "Day",
+	ListingCalendarToday:  "Today",
+
+	AdvancedFilter:                   "Advanced Filter",
+	AdvancedFilterAnd:                "AND",
+	AdvancedFilterOr:                 "OR",
+	AdvancedFilterAddCondition:       "Add Condition",
+	AdvancedFilterAddGroup:           "Add Group",
+	AdvancedFilterField:              "Field",
+	AdvancedFilterOperator:           "Operator",
+	AdvancedFilterValue:              "Value",
+	AdvancedFilterInvalid:            "Invalid advanced filter",
+	AdvancedFilterNotEquals:          "is not equal to",
+	AdvancedFilterNotContains:        "does not contain",
+	AdvancedFilterStartsWith:         "starts with",
+	AdvancedFilterGreaterThanOrEqual: "is greater than or equal to",
+	AdvancedFilterLessThanOrEqual:    "is less than or equal to",
+	AdvancedFilterIsTrue:             "is true",
+	AdvancedFilterIsFalse:            "is false",
+	AdvancedFilterIsEmpty:            "is empty",
+	AdvancedFilterIsNotEmpty:         "is not empty",
+
+	Undo:                "Undo",
+	Undone:              "Successfully Undone",
+	UndoExpired:         "The operation can no longer be undone",
+	SuccessfullyDeleted: "Successfully Deleted",
+
+	DraftFound:    "You have unsaved changes from your last session.",
+	DraftRestore:  "Restore",
+	DraftDiscard:  "Discard",
+	DraftNotFound: "The unsaved changes are no longer available",
+
+	GlobalSearch:            "Search",
+	GlobalSearchPlaceholder: "Search all records",
+	GlobalSearchNoResults:   "No results found",
+	GlobalSearchTimedOut:    "The search timed out",
+
+	ListingAllOnPageSelectedTemplate:   "All {count} records on this page are selected.",
+	ListingSelectAllMatchingTemplate:   "Select all {count} matching records",
+	ListingAllMatchingSelectedTemplate: "All {count} matching records are selected.",
+	ListingSelectOnlyThisPage:          "Select only this page",
+	BulkActionProgressTemplate:         "{done} of {total} records processed",
+	BulkActionRunningInBackground:      "The action keeps running in the background after this dialog is closed.",
+	BulkActionNotFound:                 "The bulk action is not running anymore",
+
+	ValidationRequired:          "This field is required",
+	ValidationMinLengthTemplate: "Must be at least {min} characters",
+	ValidationMaxLengthTemplate: "Must be at most {max} characters",
+	ValidationMinTemplate:       "Must be at least {min}",
+	ValidationMaxTemplate:       "Must be at most {max}",
+	ValidationInvalidFormat:     "Invalid format",
+	ValidationInvalidEmail:      "Invalid email address",
+	ValidationNotUnique:         "This value is already taken",
+
+	WizardBack:       "Back",
+	WizardNext:       "Next",
+	WizardDraftSaved: "Draft saved",
+
+	AssociationPickerLoadMore: "Load more",
+
+	ListingLayoutTree:         "Tree",
+	ListingTreeMoveToRoot:     "Drop here to move to the top level",
+	ListingTreeMoveIntoItself: "The record can't be moved under itself or its descendants",
+
+	Duplicate: "Duplicate",
 }
 
 var Messages_zh_CN = &Messages{
@@ -318,6 +621,122 @@ var Messages_zh_CN = &Messages{
 	HumanizeTimeLongWhile: "很久之%s",
 
 	LeaveBeforeUnsubmit: "如果您在提交表单之前离开，您将丢失所有未保存的输入。",
+
+	Export:           "导出",
+	ExportAsTemplate: "导出为{format}",
+
+	Import:                       "导入",
+	ImportFile:                   "CSV文件",
+	ImportDryRun:                 "校验",
+	ImportFileRequired:           "请选择要导入的文件",
+	ImportNoRows:                 "文件中没有数据",
+	ImportNothingImported:        "部分数据无效，未导入任何数据",
+	ImportLine:                   "行",
+	ImportErrors:                 "错误",
+	ImportTitleTemplate:          "导入{modelName}",
+	ImportTooManyRowsTemplate:    "文件超过{max}行",
+	ImportSuccessfullyTemplate:   "成功导入{count}行",
+	ImportIgnoredColumnsTemplate: "忽略的列：{columns}",
+	ImportValidRowsTemplate:      "{total}行中{valid}行有效",
+
+	ListingViewSave:         "保存视图",
+	ListingViewSaveAsNew:    "另存为新视图",
+	ListingViewName:         "视图名称",
+	ListingViewShareWith:    "共享给",
+	ListingViewPrivate:      "仅自己",
+	ListingViewNameRequired: "视图名称不能为空",
+	ListingViewCannotShare:  "您无法将视图共享给该角色",
+	ListingViewNotFound:     "视图不存在或您无权编辑",
+	ListingViewDeleted:      "视图已删除",
+
+	VersionConflictTitle:        "编辑冲突",
+	VersionConflictNotice:       "自您打开此记录后，它已被其他人修改。您的更改保留在表单中，再次保存将覆盖最新版本。",
+	VersionConflictField:        "字段",
+	VersionConflictLatest:       "最新版本",
+	VersionConflictYours:        "您的更改",
+	VersionConflictDiscardYours: "放弃我的更改",
+	VersionConflictKeepYours:    "保留我的更改",
+
+	ListingTrash:                  "回收站",
+	ListingTrashAll:               "全部",
+	ListingTrashRestore:           "恢复",
+	ListingTrashPurge:             "永久删除",
+	ListingTrashPurgeConfirmation: "你确定要永久删除这个对象吗？此操作无法撤销。",
+	ListingTrashRestored:          "成功恢复",
+	ListingTrashPurged:            "成功永久删除",
+
+	ListingLayoutTable:  "表格",
+	ListingLayoutKanban: "看板",
+	ListingMoveInvalid:  "无法将记录移动到此处",
+
+	ListingLayoutCalendar: "日历",
+	ListingCalendarMonth:  "月",
+	ListingCalendarWeek:   "周",
+	ListingCalendarDay:    "日",
+	ListingCalendarToday:  "今天",
+
+	AdvancedFilter:                   "高级筛选",
+	AdvancedFilterAnd:                "并且",
+	AdvancedFilterOr:                 "或者",
+	AdvancedFilterAddCondition:       "添加条件",
+	AdvancedFilterAddGroup:           "添加条件组",
+	AdvancedFilterField:              "字段",
+	AdvancedFilterOperator:           "运算符",
+	AdvancedFilterValue:              "值",
+	AdvancedFilterInvalid:            "高级筛选无效",
+	AdvancedFilterNotEquals:          "不等于",
+	AdvancedFilterNotContains:        "不包含",
+	AdvancedFilterStartsWith:         "开头是",
+	AdvancedFilterGreaterThanOrEqual: "大于等于",
+	AdvancedFilterLessThanOrEqual:    "小于等于",
+	AdvancedFilterIsTrue:             "为是",
+	AdvancedFilterIsFalse:            "为否",
+	AdvancedFilterIsEmpty:            "为空",
+	AdvancedFilterIsNotEmpty:         "不为空",
+
+	Undo:                "撤销",
+	Undone:              "已撤销",
+	UndoExpired:         "该操作已无法撤销",
+	SuccessfullyDeleted: "成功删除了",
+
+	DraftFound:    "您有上次未保存的更改。",
+	DraftRestore:  "恢复",
+	DraftDiscard:  "丢弃",
+	DraftNotFound: "未保存的更改已不存在",
+
+	GlobalSearch:            "搜索",
+	GlobalSearchPlaceholder: "搜索所有记录",
+	GlobalSearchNoResults:   "未找到结果",
+	GlobalSearchTimedOut:    "搜索超时",
+
+	ListingAllOnPageSelectedTemplate:   "已选中本页全部{count}条记录。",
+	ListingSelectAllMatchingTemplate:   "选择全部{count}条符合条件的记录",
+	ListingAllMatchingSelectedTemplate: "已选中全部{count}条符合条件的记录。",
+	ListingSelectOnlyThisPage:          "仅选择本页",
+	BulkActionProgressTemplate:         "已处理{done}/{total}条记录",
+	BulkActionRunningInBackground:      "关闭此对话框后，操作会在后台继续运行。",
+	BulkActionNotFound:                 "该批量操作已不在运行",
+
+	ValidationRequired:          "此项为必填项",
+	ValidationMinLengthTemplate: "至少需要 {min} 个字符",
+	ValidationMaxLengthTemplate: "最多 {max} 个字符",
+	ValidationMinTemplate:       "不能小于 {min}",
+	ValidationMaxTemplate:       "不能大于 {max}",
+	ValidationInvalidFormat:     "格式不正确",
+	ValidationInvalidEmail:      "邮箱地址不正确",
+	ValidationNotUnique:         "该值已被使用",
+
+	WizardBack:       "上一步",
+	WizardNext:       "下一步",
+	WizardDraftSaved: "草稿已保存",
+
+	AssociationPickerLoadMore: "加载更多",
+
+	ListingLayoutTree:         "树形",
+	ListingTreeMoveToRoot:     "拖放到此处以移动到顶层",
+	ListingTreeMoveIntoItself: "无法将记录移动到其自身或其子级下",
+
+	Duplicate: "复制",
 }
 
 var Messages_ja_JP = &Messages{
@@ -404,4 +823,120 @@ var Messages_ja_JP = &Messages{
 	HumanizeTimeLongWhile: "a long while %s",
 
 	LeaveBeforeUnsubmit: "フォームを送信する前に離れると、すべての未保存の入力が失われます。",
+
+	Export:           "エクスポート",
+	ExportAsTemplate: "{format}でエクスポート",
+
+	Import:                       "インポート",
+	ImportFile:                   "CSVファイル",
+	ImportDryRun:                 "検証",
+	ImportFileRequired:           "インポートするファイルを選択してください",
+	ImportNoRows:                 "ファイルにデータがありません",
+	ImportNothingImported:        "無効な行があるため、何もインポートされませんでした",
+	ImportLine:                   "行",
+	ImportErrors:                 "エラー",
+	ImportTitleTemplate:          "{modelName}をインポート",
+	ImportTooManyRowsTemplate:    "ファイルが{max}行を超えています",
+	ImportSuccessfullyTemplate:   "{count}行をインポートしました",
+	ImportIgnoredColumnsTemplate: "無視された列：{columns}",
+	ImportValidRowsTemplate:      "{total}行中{valid}行が有効です",
+
+	ListingViewSave:         "ビューを保存",
+	ListingViewSaveAsNew:    "新規ビューとして保存",
+	ListingViewName:         "ビュー名",
+	ListingViewShareWith:    "共有先",
+	ListingViewPrivate:      "自分のみ",
+	ListingViewNameRequired: "ビュー名は必須です",
+	ListingViewCannotShare:  "このロールにビューを共有することはできません",
+	ListingViewNotFound:     "ビューが存在しないか、編集する権限がありません",
+	ListingViewDeleted:      "ビューを削除しました",
+
+	VersionConflictTitle:        "編集の競合",
+	VersionConflictNotice:       "このレコードは開いた後に他のユーザーによって変更されました。変更内容はフォームに保持されており、再度保存すると最新バージョンが上書きされます。",
+	VersionConflictField:        "フィールド",
+	VersionConflictLatest:       "最新バージョン",
+	VersionConflictYours:        "あなたの変更",
+	VersionConflictDiscardYours: "変更を破棄",
+	VersionConflictKeepYours:    "変更を保持",
+
+	ListingTrash:                  "ゴミ箱",
+	ListingTrashAll:               "すべて",
+	ListingTrashRestore:           "復元",
+	ListingTrashPurge:             "完全に削除",
+	ListingTrashPurgeConfirmation: "このオブジェクトを完全に削除してもよろしいですか？この操作は元に戻せません。",
+	ListingTrashRestored:          "復元しました",
+	ListingTrashPurged:            "完全に削除しました",
+
+	ListingLayoutTable:  "テーブル",
+	ListingLayoutKanban: "ボード",
+	ListingMoveInvalid:  "このレコードをここに移動できません",
+
+	ListingLayoutCalendar: "カレンダー",
+	ListingCalendarMonth:  "月",
+	ListingCalendarWeek:   "週",
+	ListingCalendarDay:    "日",
+	ListingCalendarToday:  "今日",
+
+	AdvancedFilter:                   "詳細フィルター",
+	AdvancedFilterAnd:                "かつ",
+	AdvancedFilterOr:                 "または",
+	AdvancedFilterAddCondition:       "条件を追加",
+	AdvancedFilterAddGroup:           "グループを追加",
+	AdvancedFilterField:              "フィールド",
+	AdvancedFilterOperator:           "演算子",
+	AdvancedFilterValue:              "値",
+	AdvancedFilterInvalid:            "詳細フィルターが無効です",
+	AdvancedFilterNotEquals:          "等しくない",
+	AdvancedFilterNotContains:        "含まない",
+	AdvancedFilterStartsWith:         "で始まる",
+	AdvancedFilterGreaterThanOrEqual: "以上",
+	AdvancedFilterLessThanOrEqual:    "以下",
+	AdvancedFilterIsTrue:             "はい",
+	AdvancedFilterIsFalse:            "いいえ",
+	AdvancedFilterIsEmpty:            "空である",
+	AdvancedFilterIsNotEmpty:         "空ではない",
+
+	Undo:                "元に戻す",
+	Undone:              "元に戻しました",
+	UndoExpired:         "この操作は元に戻せなくなりました",
+	SuccessfullyDeleted: "削除に成功しました",
+
+	DraftFound:    "前回保存されていない変更があります。",
+	DraftRestore:  "復元",
+	DraftDiscard:  "破棄",
+	DraftNotFound: "保存されていない変更はもうありません",
+
+	GlobalSearch:            "検索",
+	GlobalSearchPlaceholder: "すべてのレコードを検索",
+	GlobalSearchNoResults:   "結果が見つかりません",
+	GlobalSearchTimedOut:    "検索がタイムアウトしました",
+
+	ListingAllOnPageSelectedTemplate:   "このページの{count}件のレコードがすべて選択されています。",
+	ListingSelectAllMatchingTemplate:   "条件に一致する{count}件のレコードをすべて選択",
+	ListingAllMatchingSelectedTemplate: "条件に一致する{count}件のレコードがすべて選択されています。",
+	ListingSelectOnlyThisPage:          "このページのみ選択",
+	BulkActionProgressTemplate:         "{total}件中{done}件のレコードを処理しました",
+	BulkActionRunningInBackground:      "このダイアログを閉じても、アクションはバックグラウンドで実行され続けます。",
+	BulkActionNotFound:                 "この一括操作は実行されていません",
+
+	ValidationRequired:          "この項目は必須です",
+	ValidationMinLengthTemplate: "{min} 文字以上で入力してください",
+	ValidationMaxLengthTemplate: "{max} 文字以内で入力してください",
+	ValidationMinTemplate:       "{min} 以上で入力してください",
+	ValidationMaxTemplate:       "{max} 以下で入力してください",
+	ValidationInvalidFormat:     "形式が正しくありません",
+	ValidationInvalidEmail:      "メールアドレスが正しくありません",
+	ValidationNotUnique:         "この値は既に使用されています",
+
+	WizardBack:       "戻る",
+	WizardNext:       "次へ",
+	WizardDraftSaved: "下書きを保存しました",
+
+	AssociationPickerLoadMore: "さらに読み込む",
+
+	ListingLayoutTree:         "ツリー",
+	ListingTreeMoveToRoot:     "ここにドロップして最上位に移動",
+	ListingTreeMoveIntoItself: "レコードをそれ自身またはその子孫の下に移動できません",
+
+	Duplicate: "複製",
 }
@@ -17,32 +17,36 @@ import (
 )
 
 type ModelBuilder struct {
-	p                   *Builder
-	model               any
-	primaryField        string
-	modelType           reflect.Type
-	menuGroupName       string
-	notInMenu           bool
-	menuIcon            string
-	menuItem            func(evCtx *web.EventContext, isSub bool) (h.HTMLComponent, error)
-	uriName             string
-	defaultURLQueryFunc func(*http.Request) url.Values
-	label               string
-	labelNameFunc       func(evCtx *web.EventContext, singular bool) string
-	fieldLabels         []string
-	placeholders        []string
-	listing             *ListingBuilder
-	detailing           *DetailingBuilder
-	editing             *EditingBuilder
-	creating            *EditingBuilder
-	writeFields         *FieldsBuilder
-	hasDetailing        bool
-	rightDrawerWidth    string
-	link                string
-	layoutConfig        *LayoutConfig
-	modelInfo           *ModelInfo
-	singleton           bool
-	plugins             []ModelPlugin
+	p                       *Builder
+	model                   any
+	primaryField            string
+	modelType               reflect.Type
+	menuGroupName           string
+	notInMenu               bool
+	menuIcon                string
+	menuItem                func(evCtx *web.EventContext, isSub bool) (h.HTMLComponent, error)
+	uriName                 string
+	defaultURLQueryFunc     func(*http.Request) url.Values
+	label                   string
+	labelNameFunc           func(evCtx *web.EventContext, singular bool) string
+	fieldLabels             []string
+	placeholders            []string
+	listing                 *ListingBuilder
+	detailing               *DetailingBuilder
+	editing                 *EditingBuilder
+	creating                *EditingBuilder
+	importing               *ImportBuilder
+	duplicating             *DuplicateBuilder
+	versionField            string
+	versionConflictDiffFunc VersionConflictDiffFunc
+	writeFields             *FieldsBuilder
+	hasDetailing            bool
+	rightDrawerWidth        string
+	link                    string
+	layoutConfig            *LayoutConfig
+	modelInfo               *ModelInfo
+	singleton               bool
+	plugins                 []ModelPlugin
 	web.EventsHub
 }
 
@@ -98,6 +102,14 @@ func (mb *ModelBuilder) registerDefaultEventFuncs() {
 	mb.RegisterEventFunc(actions.Edit, mb.editing.formEdit)
 	mb.RegisterEventFunc(actions.Update, mb.editing.defaultUpdate)
 	mb.RegisterEventFunc(actions.DoDelete, mb.editing.doDelete)
+	mb.RegisterEventFunc(actions.Undo, mb.undo)
+	mb.RegisterEventFunc(actions.SaveDraft, mb.saveDraft)
+	mb.RegisterEventFunc(actions.RestoreDraft, mb.restoreDraft)
+	mb.RegisterEventFunc(actions.DiscardDraft, mb.discardDraftEvent)
+	mb.RegisterEventFunc(actions.ReloadDependentField, mb.reloadDependentField)
+	mb.RegisterEventFunc(actions.WizardStep, mb.editing.wizardStep)
+	mb.RegisterEventFunc(actions.AssociationOptions, mb.associationOptions)
+	mb.RegisterEventFunc(actions.Duplicate, mb.duplicate)
 
 	mb.RegisterEventFunc(actions.Action, mb.detailing.openActionDialog)
 	mb.RegisterEventFunc(actions.DoAction, mb.detailing.doAction)
@@ -112,6 +124,11 @@ func (mb *ModelBuilder) registerDefaultEventFuncs() {
 	mb.RegisterEventFunc(actions.DeleteConfirmation, mb.listing.deleteConfirmation)
 	mb.RegisterEventFunc(actions.OpenListingDialog, mb.listing.openListingDialog)
 
+	if mb.importing != nil {
+		mb.RegisterEventFunc(actions.OpenImportDialog, mb.importing.openImportDialog)
+		mb.RegisterEventFunc(actions.DoImport, mb.importing.doImport)
+	}
+
 	// list editor
 	mb.RegisterEventFunc(actions.AddRowEvent, addListItemRow(mb))
 	mb.RegisterEventFunc(actions.RemoveRowEvent, removeListItemRow(mb))
@@ -190,6 +207,10 @@ func (b ModelInfo) DetailingHref(id string) string {
 	return fmt.Sprintf("%s/%s/%s", b.mb.p.prefix, b.mb.uriName, id)
 }
 
+func (b ModelInfo) ExportHref() string {
+	return fmt.Sprintf("%s/%s/export", b.mb.p.prefix, b.mb.uriName)
+}
+
 func (b ModelInfo) HasDetailing() bool {
 	return b.mb.hasDetailing
 }
@@ -229,6 +250,22 @@ func (b ModelInfo) Verifier() *perm.Verifier {
 	return v.SnakeOn(b.mb.uriName)
 }
 
+// FieldVerifier returns the verifier of the field resource presets:fields:<model>:<field>,
+// which controls the access of the field for all the records of the model.
+func (b ModelInfo) FieldVerifier(field string) *perm.Verifier {
+	return b.mb.p.verifier.Spawn().On(permFields).SnakeOn(b.mb.uriName, field)
+}
+
+// FieldReadable reports whether the field can be shown in the listing, detailing and editing
+func (b ModelInfo) FieldReadable(r *http.Request, field string) bool {
+	return b.FieldVerifier(field).Do(PermGet).WithReq(r).IsAllowed() == nil
+}
+
+// FieldWritable reports whether the field can be changed, the fields which are readable but not writable are shown read-only
+func (b ModelInfo) FieldWritable(r *http.Request, field string) bool {
+	return b.FieldVerifier(field).Do(PermUpdate).WithReq(r).IsAllowed() == nil
+}
+
 func (mb *ModelBuilder) URIName(v string) (r *ModelBuilder) {
 	mb.uriName = v
 	return mb
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/iancoleman/strcase"
 	"github.com/jinzhu/inflection"
@@ -38,6 +39,7 @@ type Builder struct {
 	layoutFunc                            func(in web.PageFunc, cfg *LayoutConfig) (out web.PageFunc)
 	detailLayoutFunc                      func(in web.PageFunc, cfg *LayoutConfig) (out web.PageFunc)
 	dataOperator                          DataOperator
+	restAPI                               *RESTAPIBuilder
 	messagesFunc                          MessagesFunc
 	homePageFunc                          web.PageFunc
 	notFoundFunc                          web.PageFunc
@@ -64,6 +66,13 @@ type Builder struct {
 	wrapHandlers                          map[string]func(in http.Handler) (out http.Handler)
 	plugins                               []Plugin
 	notFoundHandler                       http.Handler
+	undoWindow                            time.Duration
+	undoUserFunc                          UndoUserFunc
+	undos                                 *undoStore
+	bulkActionJobs                        *bulkActionJobs
+	globalSearchOff                       bool
+	globalSearchTimeout                   time.Duration
+	globalSearchPerModel                  int
 }
 
 type AssetFunc func(ctx *web.EventContext)
@@ -103,6 +112,10 @@ func New() *Builder {
 		menuTopItems:         make(map[string]ComponentFunc),
 		brandTitle:           "Admin",
 		rightDrawerWidth:     "600",
+		undos:                newUndoStore(),
+		bulkActionJobs:       newBulkActionJobs(),
+		globalSearchTimeout:  GlobalSearchTimeoutDefault,
+		globalSearchPerModel: GlobalSearchPerModelDefault,
 		verifier:             perm.NewVerifier(PermModule, nil),
 		homePageLayoutConfig: &LayoutConfig{},
 		notFoundPageLayoutConfig: &LayoutConfig{
@@ -112,6 +125,7 @@ func New() *Builder {
 	}
 	b.menuOrder = newMenuOrderBuilder(b)
 	b.GetWebBuilder().RegisterEventFunc(OpenConfirmDialog, b.openConfirmDialog)
+	b.GetWebBuilder().RegisterEventFunc(actions.GlobalSearch, b.globalSearchEvent)
 	b.layoutFunc = b.defaultLayout
 	b.detailLayoutFunc = b.defaultLayout
 	b.notFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -344,6 +358,10 @@ func (b *Builder) DataOperator(v DataOperator) (r *Builder) {
 	return b
 }
 
+func (b *Builder) GetDataOperator() DataOperator {
+	return b.dataOperator
+}
+
 func modelNames(ms []*ModelBuilder) (r []string) {
 	for _, m := range ms {
 		r = append(r, m.uriName)
@@ -937,11 +955,13 @@ func (b *Builder) defaultLayout(in web.PageFunc, cfg *LayoutConfig) (out web.Pag
 				Color(b.progressBarColor),
 			h.Template(
 				VSnackbar(
-					h.Text("{{vars.presetsMessage.message}}")).
+					h.Text("{{vars.presetsMessage.message}}"),
+					undoButton(),
+				).
 					Attr("v-model", "vars.presetsMessage.show").
 					Attr(":color", "vars.presetsMessage.color").
 					Attr("style", "bottom: 48px;").
-					Timeout(2000).
+					Attr(":timeout", "vars.presetsMessage.timeout || 2000").
 					Location(LocationBottom),
 			).Attr("v-if", "vars.presetsMessage"),
 			VLayout(
@@ -963,6 +983,7 @@ func (b *Builder) defaultLayout(in web.PageFunc, cfg *LayoutConfig) (out web.Pag
 					VLayout(
 						VMain(
 							toolbar,
+							h.Div(b.globalSearchBox(ctx)).Class("mx-4 mt-2"),
 							VCard(
 								menu,
 							).Class("menu-content mt-2 mb-4 ml-4 pr-4").Variant(VariantText),
@@ -997,7 +1018,7 @@ func (b *Builder) defaultLayout(in web.PageFunc, cfg *LayoutConfig) (out web.Pag
 					Attr("style", "height:100vh; padding-left: calc(var(--v-layout-left) + 16px); --v-layout-right: 16px"),
 			),
 		).Attr("id", "vt-app").Elevation(0).
-			Attr(web.VAssign("vars", fmt.Sprintf(`{presetsRightDrawer: false, presetsDialog: false, presetsListingDialog: false, 
+			Attr(web.VAssign("vars", fmt.Sprintf(`{presetsRightDrawer: false, presetsDialog: false, presetsListingDialog: false, presetsGlobalSearch: false,
 navDrawer: true,%s:{}
 }`, VarsPresetsDataChanged))...).Class(b.containerClassName)
 
@@ -1033,10 +1054,10 @@ func (b *Builder) PlainLayout(in web.PageFunc) (out web.PageFunc) {
 				Height(2).
 				Color(b.progressBarColor),
 			h.Template(
-				VSnackbar(h.Text("{{vars.presetsMessage.message}}")).
+				VSnackbar(h.Text("{{vars.presetsMessage.message}}"), undoButton()).
 					Attr("v-model", "vars.presetsMessage.show").
 					Attr(":color", "vars.presetsMessage.color").
-					Timeout(2000).
+					Attr(":timeout", "vars.presetsMessage.timeout || 2000").
 					Location(LocationTop),
 			).Attr("v-if", "vars.presetsMessage"),
 			VMain(
@@ -1202,6 +1223,11 @@ func (b *Builder) initMux() {
 			b.wrap(m, b.layoutFunc(inPageFunc, m.layoutConfig)),
 		)
 		log.Printf("mounted url: %s\n", routePath)
+		if m.listing.exporting != nil {
+			exportPath := info.ExportHref()
+			mux.Handle("GET "+exportPath, b.wrapHandler(m.listing.exporting))
+			log.Printf("mounted url: %s\n", exportPath)
+		}
 		if m.hasDetailing {
 			routePath = fmt.Sprintf("%s/%s/{id}", b.prefix, pluralUri)
 			mux.Handle(
@@ -1215,6 +1241,9 @@ func (b *Builder) initMux() {
 	// b.handler = mux
 	// Handle 404
 	b.handler = b.notFound(mux)
+	if b.restAPI != nil {
+		b.handler = b.restAPI.wrap(b.handler)
+	}
 }
 
 type responseWriterWrapper struct {
@@ -1314,11 +1343,24 @@ func (b *Builder) wrap(m *ModelBuilder, pf web.PageFunc) http.Handler {
 	return handlers
 }
 
+// wrapHandler applies the language detection and the wrap handlers to non-page handlers
+func (b *Builder) wrapHandler(in http.Handler) http.Handler {
+	handlers := b.GetI18n().EnsureLanguage(in)
+	for _, wrapHandler := range b.wrapHandlers {
+		handlers = wrapHandler(handlers)
+	}
+	return handlers
+}
+
 func (b *Builder) Build() {
 	mns := modelNames(b.models)
 	if len(lo.Uniq(mns)) != len(mns) {
 		panic(fmt.Sprintf("Duplicated model names registered %v", mns))
 	}
+	for _, mb := range b.models {
+		mb.checkUniqueValidators()
+		mb.listing.wrapSearchIndex()
+	}
 	b.initMux()
 }
 
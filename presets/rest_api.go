@@ -0,0 +1,707 @@
+package presets
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/samber/lo"
+	"github.com/sunfmin/reflectutils"
+	"github.com/theplant/relay"
+)
+
+const (
+	ParamAPIFirst   = "first"
+	ParamAPIAfter   = "after"
+	ParamAPILast    = "last"
+	ParamAPIBefore  = "before"
+	ParamAPIKeyword = "keyword"
+	// ParamAPIOrderBy is a comma separated list of orderable fields, prefix "-" for descending order
+	ParamAPIOrderBy = "order_by"
+	// ParamAPIFilterPrefix is the prefix of the filter params, e.g. "f_status=paid"
+	ParamAPIFilterPrefix = "f_"
+
+	apiPageCursorPrefix = "page:"
+	apiMaxBodySize      = 10 << 20
+)
+
+// RESTAPIBuilder serves a JSON REST API for every model, the requests go through
+// the same searcher, fetcher, setters, validator, saver, deleter and permissions as the admin UI.
+//
+//	GET    {prefix}/{uri}       list, paginated with relay cursors
+//	POST   {prefix}/{uri}       create
+//	GET    {prefix}/{uri}/{id}  get
+//	PUT    {prefix}/{uri}/{id}  update, the fields not in the body keep their values
+//	DELETE {prefix}/{uri}/{id}  delete
+//	GET    {prefix}/openapi.json
+type RESTAPIBuilder struct {
+	p       *Builder
+	prefix  string
+	title   string
+	version string
+	except  []*ModelBuilder
+}
+
+// RESTAPI enables the JSON REST API, it is mounted under "/api" of the presets prefix by default.
+func (b *Builder) RESTAPI() (r *RESTAPIBuilder) {
+	if b.restAPI == nil {
+		b.restAPI = &RESTAPIBuilder{
+			p:       b,
+			prefix:  "/api",
+			version: "1.0.0",
+		}
+	}
+	return b.restAPI
+}
+
+func (b *RESTAPIBuilder) Prefix(v string) (r *RESTAPIBuilder) {
+	b.prefix = "/" + strings.Trim(v, "/")
+	return b
+}
+
+func (b *RESTAPIBuilder) Title(v string) (r *RESTAPIBuilder) {
+	b.title = v
+	return b
+}
+
+func (b *RESTAPIBuilder) Version(v string) (r *RESTAPIBuilder) {
+	b.version = v
+	return b
+}
+
+// Except excludes the models from the API
+func (b *RESTAPIBuilder) Except(vs ...*ModelBuilder) (r *RESTAPIBuilder) {
+	b.except = append(b.except, vs...)
+	return b
+}
+
+func (b *RESTAPIBuilder) path() string {
+	return b.p.prefix + b.prefix
+}
+
+func (b *RESTAPIBuilder) models() []*ModelBuilder {
+	return lo.Filter(b.p.models, func(m *ModelBuilder, _ int) bool {
+		return !m.singleton && !lo.Contains(b.except, m)
+	})
+}
+
+// wrap serves the API requests, the others are passed to the next handler.
+// The API has its own mux so that the not found responses are in JSON.
+func (b *RESTAPIBuilder) wrap(next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	b.mount(mux)
+	mux.Handle(b.path()+"/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.writeJSON(w, http.StatusNotFound, newAPIError(http.StatusNotFound, errors.New("not found")))
+	}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == b.path() || strings.HasPrefix(r.URL.Path, b.path()+"/") {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (b *RESTAPIBuilder) mount(mux *http.ServeMux) {
+	openAPIPath := b.path() + "/openapi.json"
+	mux.Handle("GET "+openAPIPath, b.p.wrapHandler(http.HandlerFunc(b.serveOpenAPI)))
+	log.Printf("mounted url: %s\n", openAPIPath)
+
+	for _, m := range b.models() {
+		api := &modelAPI{api: b, mb: m}
+		base := b.path() + "/" + m.uriName
+		mux.Handle("GET "+base, b.p.wrapHandler(b.handlerFunc(api.list)))
+		mux.Handle("POST "+base, b.p.wrapHandler(b.handlerFunc(api.create)))
+		mux.Handle("GET "+base+"/{id}", b.p.wrapHandler(b.handlerFunc(api.get)))
+		mux.Handle("PUT "+base+"/{id}", b.p.wrapHandler(b.handlerFunc(api.update)))
+		mux.Handle("DELETE "+base+"/{id}", b.p.wrapHandler(b.handlerFunc(api.delete)))
+		log.Printf("mounted url: %s\n", base)
+	}
+}
+
+type apiError struct {
+	Status      int                 `json:"-"`
+	Error       string              `json:"error"`
+	FieldErrors map[string][]string `json:"fieldErrors,omitempty"`
+}
+
+func newAPIError(status int, err error) *apiError {
+	return &apiError{Status: status, Error: err.Error()}
+}
+
+func apiValidationError(vErr web.ValidationErrors, fields []*FieldBuilder) *apiError {
+	e := &apiError{
+		Status:      http.StatusUnprocessableEntity,
+		Error:       strings.Join(vErr.GetGlobalErrors(), "; "),
+		FieldErrors: make(map[string][]string),
+	}
+	for _, f := range fields {
+		if msgs := vErr.GetFieldErrors(f.name); len(msgs) > 0 {
+			e.FieldErrors[f.name] = msgs
+		}
+	}
+	if e.Error == "" {
+		e.Error = "validation failed"
+	}
+	return e
+}
+
+func apiFetchError(err error) *apiError {
+	if errors.Is(err, ErrRecordNotFound) {
+		return newAPIError(http.StatusNotFound, err)
+	}
+	return newAPIError(http.StatusInternalServerError, err)
+}
+
+func apiSaveError(err error) *apiError {
+	if errors.Is(err, ErrVersionConflict) {
+		return newAPIError(http.StatusConflict, err)
+	}
+	return newAPIError(http.StatusInternalServerError, err)
+}
+
+func apiPermissionDenied() *apiError {
+	return newAPIError(http.StatusForbidden, perm.PermissionDenied)
+}
+
+func (b *RESTAPIBuilder) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		b.p.logger.Error(fmt.Sprintf("write api response error: %v", err))
+	}
+}
+
+func (b *RESTAPIBuilder) handlerFunc(f func(ctx *web.EventContext) (status int, v any, e *apiError)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, v, e := f(&web.EventContext{R: r, W: w})
+		if e != nil {
+			b.writeJSON(w, e.Status, e)
+			return
+		}
+		if status == http.StatusNoContent {
+			w.WriteHeader(status)
+			return
+		}
+		b.writeJSON(w, status, v)
+	}
+}
+
+type modelAPI struct {
+	api *RESTAPIBuilder
+	mb  *ModelBuilder
+}
+
+type apiListResponse struct {
+	Nodes    []map[string]any `json:"nodes"`
+	PageInfo relay.PageInfo   `json:"pageInfo"`
+}
+
+func (a *modelAPI) verify(ctx *web.EventContext, do string, obj any) bool {
+	v := a.mb.Info().Verifier().Do(do)
+	if obj != nil {
+		v = v.ObjectOn(obj)
+	}
+	return v.WithReq(ctx.R).IsAllowed() == nil
+}
+
+func (a *modelAPI) list(ctx *web.EventContext) (int, any, *apiError) {
+	if !a.verify(ctx, PermList, nil) {
+		return 0, nil, apiPermissionDenied()
+	}
+	lb := a.mb.listing
+	if lb.Searcher == nil {
+		return 0, nil, newAPIError(http.StatusInternalServerError, errors.New("function Searcher is not set"))
+	}
+
+	qs := ctx.R.URL.Query()
+	c := &ListingCompo{lb: lb, Keyword: qs.Get(ParamAPIKeyword)}
+	filter := url.Values{}
+	for k, vs := range qs {
+		if strings.HasPrefix(k, ParamAPIFilterPrefix) {
+			filter[strings.TrimPrefix(k, ParamAPIFilterPrefix)] = vs
+		}
+	}
+	c.FilterQuery = filter.Encode()
+	for _, v := range strings.Split(qs.Get(ParamAPIOrderBy), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		ob := ColOrderBy{FieldName: v, OrderBy: OrderByASC}
+		if strings.HasPrefix(v, "-") {
+			ob = ColOrderBy{FieldName: v[1:], OrderBy: OrderByDESC}
+		}
+		c.OrderBys = append(c.OrderBys, ob)
+	}
+
+	var err error
+	c.PerPage, err = apiIntParam(qs, ParamAPIFirst)
+	if err == nil && qs.Get(ParamAPILast) != "" {
+		c.PerPage, err = apiIntParam(qs, ParamAPILast)
+	}
+	if err != nil {
+		return 0, nil, newAPIError(http.StatusBadRequest, err)
+	}
+	if after := qs.Get(ParamAPIAfter); after != "" {
+		c.After = &after
+	}
+	if before := qs.Get(ParamAPIBefore); before != "" {
+		c.Before = &before
+	}
+	if lb.relayPagination == nil {
+		// without relay pagination, the cursors are the page numbers
+		if c.Page, err = apiDecodePageCursor(cmp.Or(qs.Get(ParamAPIAfter), qs.Get(ParamAPIBefore))); err != nil {
+			return 0, nil, newAPIError(http.StatusBadRequest, err)
+		}
+		switch {
+		case c.After != nil:
+			c.Page++
+		case c.Before != nil:
+			c.Page--
+		}
+		c.After, c.Before = nil, nil
+		if c.Page < 1 {
+			return 0, nil, newAPIError(http.StatusBadRequest, errors.New("invalid cursor"))
+		}
+	}
+
+	searchParams, _, _, _ := c.buildSearchParams(ctx)
+	result, err := lb.Searcher(ctx, searchParams)
+	if err != nil {
+		return 0, nil, newAPIError(http.StatusInternalServerError, err)
+	}
+
+	resp := &apiListResponse{Nodes: []map[string]any{}, PageInfo: result.PageInfo}
+	nodes := reflect.ValueOf(result.Nodes)
+	for i := 0; nodes.IsValid() && i < nodes.Len(); i++ {
+		resp.Nodes = append(resp.Nodes, a.marshalObject(ctx, nodes.Index(i).Interface()))
+	}
+	if lb.relayPagination == nil && !lb.disablePagination {
+		resp.PageInfo = relay.PageInfo{
+			TotalCount:      result.PageInfo.TotalCount,
+			HasPreviousPage: searchParams.Page > 1,
+			HasNextPage:     int64(len(resp.Nodes)) >= searchParams.PerPage,
+		}
+		if len(resp.Nodes) > 0 {
+			resp.PageInfo.StartCursor = lo.ToPtr(apiEncodePageCursor(searchParams.Page))
+			resp.PageInfo.EndCursor = resp.PageInfo.StartCursor
+		}
+	}
+	return http.StatusOK, resp, nil
+}
+
+func apiIntParam(qs url.Values, name string) (int64, error) {
+	v := qs.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.Errorf("invalid %s: %q", name, v)
+	}
+	return n, nil
+}
+
+func apiEncodePageCursor(page int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprint(apiPageCursorPrefix, page)))
+}
+
+func apiDecodePageCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 1, nil
+	}
+	bs, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(bs), apiPageCursorPrefix) {
+		return 0, errors.New("invalid cursor")
+	}
+	return strconv.ParseInt(strings.TrimPrefix(string(bs), apiPageCursorPrefix), 10, 64)
+}
+
+func (a *modelAPI) get(ctx *web.EventContext) (int, any, *apiError) {
+	if !a.verify(ctx, PermGet, nil) {
+		return 0, nil, apiPermissionDenied()
+	}
+	obj, err := a.mb.editing.Fetcher(a.mb.NewModel(), ctx.R.PathValue("id"), ctx)
+	if err != nil {
+		return 0, nil, apiFetchError(err)
+	}
+	if !a.verify(ctx, PermGet, obj) {
+		return 0, nil, apiPermissionDenied()
+	}
+	return http.StatusOK, a.marshalObject(ctx, obj), nil
+}
+
+func (a *modelAPI) create(ctx *web.EventContext) (int, any, *apiError) {
+	if !a.verify(ctx, PermCreate, nil) {
+		return 0, nil, apiPermissionDenied()
+	}
+	eb := a.mb.editing
+	if a.mb.creating != nil {
+		eb = a.mb.creating
+	}
+	return a.save(ctx, eb, a.mb.NewModel(), "", PermCreate)
+}
+
+func (a *modelAPI) update(ctx *web.EventContext) (int, any, *apiError) {
+	if !a.verify(ctx, PermUpdate, nil) {
+		return 0, nil, apiPermissionDenied()
+	}
+	id := ctx.R.PathValue("id")
+	obj, err := a.mb.editing.Fetcher(a.mb.NewModel(), id, ctx)
+	if err != nil {
+		return 0, nil, apiFetchError(err)
+	}
+	return a.save(ctx, a.mb.editing, obj, id, PermUpdate)
+}
+
+func (a *modelAPI) save(ctx *web.EventContext, eb *EditingBuilder, obj any, id string, do string) (int, any, *apiError) {
+	var body map[string]any
+	dec := json.NewDecoder(io.LimitReader(ctx.R.Body, apiMaxBodySize))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return 0, nil, newAPIError(http.StatusBadRequest, errors.Wrap(err, "invalid json body"))
+	}
+
+	for k := range body {
+		if eb.GetField(k) == nil {
+			return 0, nil, newAPIError(http.StatusBadRequest, errors.Errorf("unknown field %q", k))
+		}
+	}
+	values := url.Values{}
+	flattenAPIValues(values, "", body)
+
+	rowCtx := rowContext(ctx, values)
+	if eb.Setter != nil {
+		eb.Setter(obj, rowCtx)
+	}
+	// the fields which are not in the body of an update keep the values of the fetched record
+	fb := &eb.FieldsBuilder
+	if id != "" {
+		var names []interface{}
+		for _, f := range eb.fields {
+			if _, ok := body[f.name]; ok {
+				names = append(names, f.name)
+			}
+		}
+		fb = fb.Only(names...)
+	}
+	if id == "" || len(body) > 0 {
+		if vErr := fb.Unmarshal(obj, a.mb.Info(), true, rowCtx); vErr.HaveErrors() {
+			return 0, nil, apiValidationError(vErr, eb.fields)
+		}
+	}
+	if !a.verify(ctx, do, obj) {
+		return 0, nil, apiPermissionDenied()
+	}
+	if vErr := eb.Validate(obj, rowCtx); vErr.HaveErrors() {
+		return 0, nil, apiValidationError(vErr, eb.fields)
+	}
+	if id != "" {
+		if err := a.mb.checkVersion(rowCtx, obj); err != nil {
+			return 0, nil, apiSaveError(err)
+		}
+	}
+	if err := eb.Saver(obj, id, rowCtx); err != nil {
+		return 0, nil, apiSaveError(err)
+	}
+
+	if id == "" {
+		return http.StatusCreated, a.marshalObject(ctx, obj), nil
+	}
+	return http.StatusOK, a.marshalObject(ctx, obj), nil
+}
+
+func (a *modelAPI) delete(ctx *web.EventContext) (int, any, *apiError) {
+	if !a.verify(ctx, PermDelete, nil) {
+		return 0, nil, apiPermissionDenied()
+	}
+	id := ctx.R.PathValue("id")
+	obj, err := a.mb.editing.Fetcher(a.mb.NewModel(), id, ctx)
+	if err != nil {
+		return 0, nil, apiFetchError(err)
+	}
+	if !a.verify(ctx, PermDelete, obj) {
+		return 0, nil, apiPermissionDenied()
+	}
+	if err := a.mb.editing.Deleter(obj, id, ctx); err != nil {
+		return 0, nil, newAPIError(http.StatusInternalServerError, err)
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// flattenAPIValues converts the json values to form values which the field setters read,
+// e.g. {"Addresses": [{"Street": "x"}]} => Addresses[0].Street=x
+func flattenAPIValues(values url.Values, key string, v any) {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, child := range vv {
+			if key != "" {
+				k = key + "." + k
+			}
+			flattenAPIValues(values, k, child)
+		}
+	case []any:
+		for i, child := range vv {
+			switch child.(type) {
+			case map[string]any, []any:
+				flattenAPIValues(values, fmt.Sprintf("%s[%d]", key, i), child)
+			default:
+				flattenAPIValues(values, key, child)
+			}
+		}
+	case nil:
+		values.Add(key, "")
+	default:
+		values.Add(key, fmt.Sprint(vv))
+	}
+}
+
+// readFields are the struct fields of the listing and the editing, which are exposed by the API
+func (a *modelAPI) readFields() (names []string) {
+	t := a.mb.modelType.Elem()
+	for _, fields := range [][]*FieldBuilder{a.mb.listing.fields, a.mb.editing.fields} {
+		for _, f := range fields {
+			if _, ok := t.FieldByName(f.name); ok && !lo.Contains(names, f.name) {
+				names = append(names, f.name)
+			}
+		}
+	}
+	return
+}
+
+// marshalObject omits the fields which the request is not allowed to read
+func (a *modelAPI) marshalObject(ctx *web.EventContext, obj any) map[string]any {
+	r := map[string]any{"id": ObjectID(obj)}
+	for _, name := range a.readFields() {
+		if !a.mb.Info().FieldReadable(ctx.R, name) {
+			continue
+		}
+		v, err := reflectutils.Get(obj, name)
+		if err != nil {
+			continue
+		}
+		r[name] = v
+	}
+	return r
+}
+
+func (b *RESTAPIBuilder) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	b.writeJSON(w, http.StatusOK, b.OpenAPI(r))
+}
+
+// OpenAPI returns the OpenAPI 3 document of the API, the schemas are derived from the
+// registered fields, and the models which the request is not allowed to list are omitted,
+// as well as the fields which it's not allowed to read or write.
+func (b *RESTAPIBuilder) OpenAPI(r *http.Request) map[string]any {
+	paths := map[string]any{}
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"error":       map[string]any{"type": "string"},
+				"fieldErrors": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "array", "items": map[string]any{"type": "string"}}},
+			},
+		},
+		"PageInfo": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"totalCount":      map[string]any{"type": "integer"},
+				"hasNextPage":     map[string]any{"type": "boolean"},
+				"hasPreviousPage": map[string]any{"type": "boolean"},
+				"startCursor":     map[string]any{"type": "string", "nullable": true},
+				"endCursor":       map[string]any{"type": "string", "nullable": true},
+			},
+		},
+	}
+	errorResponse := map[string]any{
+		"description": "error",
+		"content":     map[string]any{"application/json": map[string]any{"schema": schemaRef("Error")}},
+	}
+
+	for _, m := range b.models() {
+		if m.Info().Verifier().Do(PermList).WithReq(r).IsAllowed() != nil {
+			continue
+		}
+		api := &modelAPI{api: b, mb: m}
+		name := apiSchemaName(m)
+
+		props := map[string]any{"id": map[string]any{"type": "string", "readOnly": true}}
+		for _, f := range api.readFields() {
+			if !m.Info().FieldReadable(r, f) {
+				continue
+			}
+			sf, _ := m.modelType.Elem().FieldByName(f)
+			props[f] = openAPISchema(sf.Type, 0)
+		}
+		schemas[name] = map[string]any{"type": "object", "properties": props}
+
+		inputProps := map[string]any{}
+		eb := m.editing
+		if m.creating != nil {
+			eb = m.creating
+		}
+		for _, fields := range [][]*FieldBuilder{eb.fields, m.editing.fields} {
+			for _, f := range fields {
+				if !m.Info().FieldWritable(r, f.name) {
+					continue
+				}
+				if sf, ok := m.modelType.Elem().FieldByName(f.name); ok {
+					inputProps[f.name] = openAPISchema(sf.Type, 0)
+				} else {
+					inputProps[f.name] = map[string]any{}
+				}
+			}
+		}
+		schemas[name+"Input"] = map[string]any{"type": "object", "properties": inputProps}
+
+		jsonBody := func(schema any) map[string]any {
+			return map[string]any{"application/json": map[string]any{"schema": schema}}
+		}
+		objectResponse := map[string]any{"description": "ok", "content": jsonBody(schemaRef(name))}
+		idParam := []any{map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}}
+		queryParam := func(name, typ, desc string) map[string]any {
+			return map[string]any{"name": name, "in": "query", "description": desc, "schema": map[string]any{"type": typ}}
+		}
+		tags := []string{name}
+
+		base := b.path() + "/" + m.uriName
+		paths[base] = map[string]any{
+			"get": map[string]any{
+				"tags":        tags,
+				"operationId": "list" + name,
+				"parameters": []any{
+					queryParam(ParamAPIFirst, "integer", "page size, for forward pagination"),
+					queryParam(ParamAPIAfter, "string", "the endCursor of the previous page"),
+					queryParam(ParamAPILast, "integer", "page size, for backward pagination"),
+					queryParam(ParamAPIBefore, "string", "the startCursor of the next page"),
+					queryParam(ParamAPIKeyword, "string", "keyword search"),
+					queryParam(ParamAPIOrderBy, "string", `comma separated orderable fields, prefix "-" for descending order`),
+				},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "ok",
+						"content": jsonBody(map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"nodes":    map[string]any{"type": "array", "items": schemaRef(name)},
+								"pageInfo": schemaRef("PageInfo"),
+							},
+						}),
+					},
+					"default": errorResponse,
+				},
+			},
+			"post": map[string]any{
+				"tags":        tags,
+				"operationId": "create" + name,
+				"requestBody": map[string]any{"required": true, "content": jsonBody(schemaRef(name + "Input"))},
+				"responses":   map[string]any{"201": objectResponse, "default": errorResponse},
+			},
+		}
+		paths[base+"/{id}"] = map[string]any{
+			"parameters": idParam,
+			"get": map[string]any{
+				"tags":        tags,
+				"operationId": "get" + name,
+				"responses":   map[string]any{"200": objectResponse, "default": errorResponse},
+			},
+			"put": map[string]any{
+				"tags":        tags,
+				"operationId": "update" + name,
+				"requestBody": map[string]any{"required": true, "content": jsonBody(schemaRef(name + "Input"))},
+				"responses":   map[string]any{"200": objectResponse, "default": errorResponse},
+			},
+			"delete": map[string]any{
+				"tags":        tags,
+				"operationId": "delete" + name,
+				"responses":   map[string]any{"204": map[string]any{"description": "deleted"}, "default": errorResponse},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   cmp.Or(b.title, b.p.brandTitle),
+			"version": b.version,
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func apiSchemaName(m *ModelBuilder) string {
+	return m.modelType.Elem().Name()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func openAPISchema(t reflect.Type, depth int) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	var s map[string]any
+	switch {
+	case t == timeType:
+		s = map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = map[string]any{"type": "number"}
+	case t.Kind() == reflect.String:
+		s = map[string]any{"type": "string"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s = map[string]any{"type": "string", "format": "byte"}
+			break
+		}
+		s = map[string]any{"type": "array", "items": openAPISchema(t.Elem(), depth+1)}
+	case t.Kind() == reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": openAPISchema(t.Elem(), depth+1)}
+	case t.Kind() == reflect.Struct && depth < 3:
+		props := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			if sf.Anonymous {
+				if embedded, ok := openAPISchema(sf.Type, depth)["properties"].(map[string]any); ok {
+					for k, v := range embedded {
+						props[k] = v
+					}
+				}
+				continue
+			}
+			props[sf.Name] = openAPISchema(sf.Type, depth+1)
+		}
+		s = map[string]any{"type": "object", "properties": props}
+	default:
+		s = map[string]any{}
+	}
+	if nullable {
+		s["nullable"] = true
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package presets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiTestOperator struct {
+	records []*foo
+}
+
+func (op *apiTestOperator) Search(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+	var nodes []*foo
+	for _, r := range op.records {
+		if params.Keyword == "" || strings.Contains(r.Version, params.Keyword) {
+			nodes = append(nodes, r)
+		}
+	}
+	start := min(int((params.Page-1)*params.PerPage), len(nodes))
+	end := min(start+int(params.PerPage), len(nodes))
+	return &SearchResult{Nodes: nodes[start:end]}, nil
+}
+
+func (op *apiTestOperator) Fetch(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+	for _, r := range op.records {
+		if fmt.Sprint(r.ID) == id {
+			cp := *r
+			return &cp, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+func (op *apiTestOperator) Save(obj interface{}, id string, ctx *web.EventContext) error {
+	f := obj.(*foo)
+	if id == "" {
+		f.ID = uint(len(op.records) + 1)
+		op.records = append(op.records, f)
+		return nil
+	}
+	for i, r := range op.records {
+		if fmt.Sprint(r.ID) == id {
+			op.records[i] = f
+		}
+	}
+	return nil
+}
+
+func (op *apiTestOperator) Delete(obj interface{}, id string, ctx *web.EventContext) error {
+	for i, r := range op.records {
+		if fmt.Sprint(r.ID) == id {
+			op.records = append(op.records[:i], op.records[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func TestRESTAPI(t *testing.T) {
+	op := &apiTestOperator{}
+	b := New().URIPrefix("/admin").DataOperator(op)
+	mb := b.Model(&foo{})
+	mb.Listing("Version").PerPage(2)
+	mb.Editing("Version").ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+		if obj.(*foo).Version == "" {
+			err.FieldError("Version", "Version is required")
+		}
+		return
+	})
+	b.RESTAPI()
+
+	do := func(method, path, body string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		b.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var r map[string]any
+		if w.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &r), w.Body.String())
+		}
+		return w.Code, r
+	}
+
+	code, r := do(http.MethodPost, "/admin/api/foos", `{"Version": ""}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, []any{"Version is required"}, r["fieldErrors"].(map[string]any)["Version"])
+
+	code, _ = do(http.MethodPost, "/admin/api/foos", `{"Unknown": "x"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	for _, v := range []string{"a", "b", "c"} {
+		code, r = do(http.MethodPost, "/admin/api/foos", fmt.Sprintf(`{"Version": %q}`, v))
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, v, r["Version"])
+	}
+
+	code, r = do(http.MethodGet, "/admin/api/foos", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, r["nodes"], 2)
+	pageInfo := r["pageInfo"].(map[string]any)
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+
+	code, r = do(http.MethodGet, "/admin/api/foos?after="+pageInfo["endCursor"].(string), "")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, r["nodes"], 1)
+	assert.Equal(t, "c", r["nodes"].([]any)[0].(map[string]any)["Version"])
+	assert.Equal(t, false, r["pageInfo"].(map[string]any)["hasNextPage"])
+	assert.Equal(t, true, r["pageInfo"].(map[string]any)["hasPreviousPage"])
+
+	code, r = do(http.MethodPut, "/admin/api/foos/2", `{"Version": "b2"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "b2", r["Version"])
+	assert.Equal(t, "2_b2", r["id"])
+
+	code, r = do(http.MethodGet, "/admin/api/foos/2", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "b2", r["Version"])
+
+	code, _ = do(http.MethodDelete, "/admin/api/foos/2", "")
+	assert.Equal(t, http.StatusNoContent, code)
+	code, _ = do(http.MethodGet, "/admin/api/foos/2", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, r = do(http.MethodGet, "/admin/api/openapi.json", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, r["paths"], "/admin/api/foos/{id}")
+	props := r["components"].(map[string]any)["schemas"].(map[string]any)["foo"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string"}, props["Version"])
+}
+
+func TestRESTAPIUpdateTimeField(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	op := &apiTestOperator{records: []*foo{{Version: "a"}}}
+	op.records[0].ID = 1
+	op.records[0].CreatedAt = createdAt
+	b := New().URIPrefix("/admin").DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("guest").WhoAre(perm.Denied).ToDo(PermGet, PermUpdate).On(":presets:fields:foos:created_at:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := b.Model(&foo{})
+	mb.Editing("Version", "CreatedAt")
+	b.RESTAPI()
+
+	do := func(method, path, body, role string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("role", role)
+		b.ServeHTTP(w, r)
+		var res map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res), w.Body.String())
+		return w.Code, res
+	}
+
+	// the fields which are not in the body are kept
+	code, r := do(http.MethodPut, "/admin/api/foos/1", `{"Version": "b"}`, "admin")
+	require.Equal(t, http.StatusOK, code, r)
+	assert.Equal(t, "b", op.records[0].Version)
+	assert.True(t, createdAt.Equal(op.records[0].CreatedAt))
+
+	// the time is posted in RFC3339 like the OpenAPI schema
+	code, r = do(http.MethodPut, "/admin/api/foos/1", `{"CreatedAt": "2025-05-06T07:08:00Z"}`, "admin")
+	require.Equal(t, http.StatusOK, code, r)
+	assert.Equal(t, "b", op.records[0].Version)
+	assert.True(t, time.Date(2025, 5, 6, 7, 8, 0, 0, time.UTC).Equal(op.records[0].CreatedAt))
+
+	code, r = do(http.MethodGet, "/admin/api/openapi.json", "", "guest")
+	require.Equal(t, http.StatusOK, code)
+	schemas := r["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.NotContains(t, schemas["foo"].(map[string]any)["properties"], "CreatedAt")
+	assert.NotContains(t, schemas["fooInput"].(map[string]any)["properties"], "CreatedAt")
+	assert.Contains(t, schemas["fooInput"].(map[string]any)["properties"], "Version")
+}
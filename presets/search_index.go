@@ -0,0 +1,220 @@
+package presets
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	vx "github.com/qor5/x/v3/ui/vuetifyx"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// The matched words in the snippets returned by SearchIndex are wrapped by these markers,
+// they are replaced with <mark> after the snippets are escaped.
+const (
+	SearchIndexHighlightStart = "\x02"
+	SearchIndexHighlightEnd   = "\x03"
+)
+
+// SearchIndex is a full-text index which the listing searches the keyword with instead of
+// matching the KeywordColumns with ILIKE, the records are ranked by relevance.
+type SearchIndex interface {
+	// Match returns the condition which selects the records of the model matching the keyword,
+	// and the expression ranking them, the more relevant the record is, the higher the rank is.
+	Match(ctx *web.EventContext, model any, name string, keyword string) (cond *SQLCondition, rank *SQLCondition, err error)
+	// Snippets returns the highlighted snippets of the objs in the same order.
+	Snippets(ctx *web.EventContext, name string, keyword string, objs []any) ([]string, error)
+	// Index adds or updates the document of obj.
+	Index(ctx *web.EventContext, name string, obj any, content string) error
+	// Remove removes the document of obj.
+	Remove(ctx *web.EventContext, name string, obj any) error
+}
+
+// SearchIndex makes the listing search the keyword with the index, the values of the fields are the content
+// of the document which is updated when the record is saved and removed when it's deleted.
+// The fields must be given, since their values are shown in the snippets of the search results,
+// which are only shown to the users who can read all of them.
+// The editing SaveFunc and DeleteFunc are wrapped when the presets is built, so they can be set before or after it.
+// The records saved before the index is added can be indexed by Reindex.
+func (b *ListingBuilder) SearchIndex(idx SearchIndex, fields ...string) (r *ListingBuilder) {
+	if len(fields) == 0 {
+		panic(fmt.Sprintf("the fields of the search index of %s must be given", b.mb.modelType))
+	}
+	b.searchIndex = idx
+	b.searchIndexFields = fields
+	return b
+}
+
+// wrapSearchIndex wraps the editing SaveFunc and DeleteFunc to update the index, it's called when the presets is built
+func (b *ListingBuilder) wrapSearchIndex() {
+	if b.searchIndex == nil || b.searchIndexWrapped {
+		return
+	}
+	b.searchIndexWrapped = true
+
+	eb := b.mb.Editing()
+	eb.WrapSaveFunc(func(in SaveFunc) SaveFunc {
+		return func(obj interface{}, id string, ctx *web.EventContext) error {
+			if err := in(obj, id, ctx); err != nil {
+				return err
+			}
+			return b.indexObject(ctx, obj)
+		}
+	})
+	eb.WrapDeleteFunc(func(in DeleteFunc) DeleteFunc {
+		return func(obj interface{}, id string, ctx *web.EventContext) error {
+			// the primary key is needed to remove the document
+			fetched, fetchErr := eb.Fetcher(b.mb.NewModel(), id, ctx)
+			if err := in(obj, id, ctx); err != nil {
+				return err
+			}
+			if fetchErr != nil {
+				return nil
+			}
+			return b.searchIndex.Remove(ctx, b.searchIndexName(), fetched)
+		}
+	})
+}
+
+// Reindex indexes all the records listed by the Searcher, like the ones saved before the index is added
+func (b *ListingBuilder) Reindex(ctx *web.EventContext) error {
+	if b.searchIndex == nil {
+		return errors.New("search index is not set")
+	}
+	for page := int64(1); ; page++ {
+		result, err := b.Searcher(ctx, &SearchParams{
+			Model:         b.mb.NewModel(),
+			PageURL:       ctx.R.URL,
+			SQLConditions: b.conditions,
+			Page:          page,
+			PerPage:       PerPageMax,
+		})
+		if err != nil {
+			return err
+		}
+		var objs []any
+		reflectutils.ForEach(result.Nodes, func(obj interface{}) {
+			objs = append(objs, obj)
+		})
+		for _, obj := range objs {
+			if err := b.indexObject(ctx, obj); err != nil {
+				return err
+			}
+		}
+		if len(objs) < PerPageMax {
+			return nil
+		}
+	}
+}
+
+func (b *ListingBuilder) searchIndexName() string {
+	return b.mb.Info().URIName()
+}
+
+func (b *ListingBuilder) indexObject(ctx *web.EventContext, obj any) error {
+	if b.searchIndex == nil {
+		return nil
+	}
+	var values []string
+	for _, f := range b.searchIndexFields {
+		v, err := reflectutils.Get(obj, f)
+		if err != nil || v == nil {
+			continue
+		}
+		if s := strings.TrimSpace(indexText(v)); s != "" {
+			values = append(values, s)
+		}
+	}
+	return b.searchIndex.Index(ctx, b.searchIndexName(), obj, strings.Join(values, "\n"))
+}
+
+func indexText(v any) string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return ""
+	}
+	if rv.Kind() == reflect.String {
+		return rv.String()
+	}
+	if s, ok := rv.Interface().(interface{ String() string }); ok {
+		return s.String()
+	}
+	return ""
+}
+
+// applySearchIndex replaces the keyword columns with the condition of the index,
+// and ranks the records by relevance unless they are ordered by the user.
+func (c *ListingCompo) applySearchIndex(evCtx *web.EventContext, searchParams *SearchParams) error {
+	if c.lb.searchIndex == nil || searchParams.Keyword == "" {
+		return nil
+	}
+	cond, rank, err := c.lb.searchIndex.Match(evCtx, c.lb.mb.NewModel(), c.lb.searchIndexName(), searchParams.Keyword)
+	if err != nil {
+		return err
+	}
+	searchParams.KeywordColumns = nil
+	searchParams.SQLConditions = append(slices.Clone(searchParams.SQLConditions), cond)
+	if len(c.OrderBys) == 0 && c.lb.relayPagination == nil {
+		searchParams.RankBy = rank
+	}
+	return nil
+}
+
+// searchSnippets returns the highlighted snippets of the records keyed by their ids
+func (c *ListingCompo) searchSnippets(evCtx *web.EventContext, keyword string, nodes any) (map[string]h.HTMLComponent, error) {
+	if c.lb.searchIndex == nil || keyword == "" {
+		return nil, nil
+	}
+	// the snippet can be cut from any of the fields
+	for _, f := range c.lb.searchIndexFields {
+		if !c.lb.mb.Info().FieldReadable(evCtx.R, f) {
+			return nil, nil
+		}
+	}
+	var objs []any
+	reflectutils.ForEach(nodes, func(obj interface{}) {
+		objs = append(objs, obj)
+	})
+	if len(objs) == 0 {
+		return nil, nil
+	}
+	snippets, err := c.lb.searchIndex.Snippets(evCtx, c.lb.searchIndexName(), keyword, objs)
+	if err != nil {
+		return nil, err
+	}
+	r := map[string]h.HTMLComponent{}
+	for i, s := range snippets {
+		if s == "" || i >= len(objs) {
+			continue
+		}
+		r[ObjectID(objs[i])] = h.Div(h.RawHTML(highlightSnippet(s))).Class("presets-search-snippet text-caption text-medium-emphasis")
+	}
+	return r, nil
+}
+
+func highlightSnippet(s string) string {
+	return strings.NewReplacer(
+		SearchIndexHighlightStart, "<mark>",
+		SearchIndexHighlightEnd, "</mark>",
+	).Replace(html.EscapeString(s))
+}
+
+// withSnippet appends the snippet of the record to the cell
+func withSnippet(in vx.CellComponentFunc, snippets map[string]h.HTMLComponent) vx.CellComponentFunc {
+	return func(obj interface{}, fieldName string, ctx *web.EventContext) h.HTMLComponent {
+		comp := in(obj, fieldName, ctx)
+		snippet, ok := snippets[ObjectID(obj)]
+		if !ok {
+			return comp
+		}
+		if td, ok := comp.(*h.HTMLTagBuilder); ok {
+			return td.AppendChildren(snippet)
+		}
+		return comp
+	}
+}
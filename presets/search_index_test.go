@@ -0,0 +1,117 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSearchIndex struct {
+	documents map[string]string
+}
+
+func (idx *testSearchIndex) Match(ctx *web.EventContext, model any, name string, keyword string) (*SQLCondition, *SQLCondition, error) {
+	return &SQLCondition{Query: "id IN (SELECT record_id FROM documents WHERE body MATCH ?)", Args: []any{keyword}},
+		&SQLCondition{Query: "rank(?)", Args: []any{keyword}}, nil
+}
+
+func (idx *testSearchIndex) Snippets(ctx *web.EventContext, name string, keyword string, objs []any) ([]string, error) {
+	r := make([]string, len(objs))
+	for i, obj := range objs {
+		r[i] = idx.documents[fmt.Sprintf("%s:%d", name, obj.(*foo).ID)]
+	}
+	return r, nil
+}
+
+func (idx *testSearchIndex) Index(ctx *web.EventContext, name string, obj any, content string) error {
+	idx.documents[fmt.Sprintf("%s:%d", name, obj.(*foo).ID)] = content
+	return nil
+}
+
+func (idx *testSearchIndex) Remove(ctx *web.EventContext, name string, obj any) error {
+	delete(idx.documents, fmt.Sprintf("%s:%d", name, obj.(*foo).ID))
+	return nil
+}
+
+func TestListingSearchIndex(t *testing.T) {
+	op := &apiTestOperator{}
+	idx := &testSearchIndex{documents: map[string]string{}}
+	pb := New().DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("clerk").WhoAre(perm.Denied).ToDo(PermGet).On(":presets:fields:foos:version:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&foo{})
+	mb.Listing().SearchIndex(idx, "Version")
+	// the save func set after the index is still wrapped
+	var saved int
+	mb.Editing().SaveFunc(func(obj interface{}, id string, ctx *web.EventContext) error {
+		saved++
+		return op.Save(obj, id, ctx)
+	})
+	pb.Build()
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	eb := mb.Editing()
+	require.NoError(t, eb.Saver(&foo{Version: "v1 <b>"}, "", evCtx))
+	assert.Equal(t, 1, saved)
+	assert.Equal(t, map[string]string{"foos:1": "v1 <b>"}, idx.documents)
+
+	c := &ListingCompo{lb: mb.Listing(), Keyword: "v1"}
+	searchParams, _, _, _ := c.buildSearchParams(evCtx)
+	assert.Empty(t, searchParams.KeywordColumns)
+	require.Len(t, searchParams.SQLConditions, 1)
+	assert.Equal(t, []any{"v1"}, searchParams.SQLConditions[0].Args)
+	require.NotNil(t, searchParams.RankBy)
+
+	c.OrderBys = []ColOrderBy{{FieldName: "ID", OrderBy: OrderByASC}}
+	searchParams, _, _, _ = c.buildSearchParams(evCtx)
+	assert.Nil(t, searchParams.RankBy)
+
+	idx.documents["foos:1"] = "v1 " + SearchIndexHighlightStart + "<b>" + SearchIndexHighlightEnd
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	snippets, err := c.searchSnippets(evCtx, c.Keyword, op.records)
+	require.NoError(t, err)
+	html, err := snippets[ObjectID(op.records[0])].MarshalHTML(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "v1 <mark>&lt;b&gt;</mark>")
+
+	// the snippets aren't shown to the users who can't read the fields indexed
+	clerkCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	clerkCtx.R.Header.Set("role", "clerk")
+	snippets, err = c.searchSnippets(clerkCtx, c.Keyword, op.records)
+	require.NoError(t, err)
+	assert.Empty(t, snippets)
+
+	require.NoError(t, eb.Deleter(&foo{}, "1", evCtx))
+	assert.Empty(t, idx.documents)
+}
+
+func TestReindex(t *testing.T) {
+	op := &apiTestOperator{}
+	for i := 1; i <= PerPageMax+1; i++ {
+		r := &foo{Version: fmt.Sprintf("v%d", i)}
+		r.ID = uint(i)
+		op.records = append(op.records, r)
+	}
+	idx := &testSearchIndex{documents: map[string]string{}}
+	mb := New().DataOperator(op).Model(&foo{})
+	lb := mb.Listing()
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	require.Error(t, lb.Reindex(evCtx))
+
+	assert.Panics(t, func() { lb.SearchIndex(idx) })
+	lb.SearchIndex(idx, "Version")
+	require.NoError(t, lb.Reindex(evCtx))
+	assert.Len(t, idx.documents, PerPageMax+1)
+	assert.Equal(t, fmt.Sprintf("v%d", PerPageMax+1), idx.documents[fmt.Sprintf("foos:%d", PerPageMax+1)])
+}
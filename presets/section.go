@@ -91,10 +91,7 @@ func (d *SectionsBuilder) appendNewSection(name string) (r *SectionBuilder) {
 		return r.father.mb.editing.Saver(obj, id, ctx)
 	})
 	r.ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
-		if r.father.mb.editing.Validator != nil {
-			return r.father.mb.editing.Validator(obj, ctx)
-		}
-		return err
+		return r.father.mb.editing.Validate(obj, ctx)
 	})
 	r.UnmarshalFunc(r.DefaultUnmarshalFunc)
 	d.sections = append(d.sections, r)
@@ -515,6 +512,14 @@ func (b *SectionBuilder) editComponent(obj interface{}, field *FieldContext, ctx
 	}
 	onChangeEvent := fmt.Sprintf("if (vars.%s ){ vars.%s.section_%s=true };", VarsPresetsDataChanged, VarsPresetsDataChanged, b.name)
 	cancelChangeEvent := fmt.Sprintf("if (vars.%s ){vars.%s.section_%s=false};", VarsPresetsDataChanged, VarsPresetsDataChanged, b.name)
+	cancelChangeEvent += b.father.mb.draftClearTimerScript(ctx, id, b.name)
+	draftEvent := func() *web.VueEventTagBuilder {
+		return web.Plaid().
+			URL(ctx.R.URL.Path).
+			Query(SectionFieldName, b.name).
+			Query(ParamID, id)
+	}
+	withDependentFieldEvent(ctx, draftEvent)
 
 	cancelBtn := VBtn(i18n.T(ctx.R, CoreI18nModuleKey, "Cancel")).Size(SizeSmall).Variant(VariantFlat).Color(ColorGreyLighten3).
 		Attr("style", "text-transform: none;").
@@ -542,6 +547,7 @@ func (b *SectionBuilder) editComponent(obj interface{}, field *FieldContext, ctx
 			hiddenComp.AppendChildren(f(obj, ctx))
 		}
 	}
+	hiddenComp.AppendChildren(b.father.mb.versionHidden(obj))
 
 	content := h.Div().Class("section-wrap edit-view with-border-b")
 
@@ -553,6 +559,7 @@ func (b *SectionBuilder) editComponent(obj interface{}, field *FieldContext, ctx
 			).Class("section-title-wrap"),
 		)
 	}
+	content.AppendChildren(b.father.mb.draftOffer(ctx, id, b.name, draftEvent))
 
 	if b.componentEditFunc != nil {
 		content.AppendChildren(
@@ -577,7 +584,7 @@ func (b *SectionBuilder) editComponent(obj interface{}, field *FieldContext, ctx
 		web.Scope(
 			content,
 			hiddenComp,
-		).VSlot("{ form }").OnChange(onChangeEvent).UseDebounce(150),
+		).VSlot("{ form }").OnChange(onChangeEvent + b.father.mb.draftAutosaveScript(ctx, id, b.name, draftEvent)).UseDebounce(150),
 	)
 }
 
@@ -900,6 +907,9 @@ func (b *SectionBuilder) DefaultElementUnmarshal() func(toObj, formObj any, pref
 				if info.Verifier().Do(PermCreate).ObjectOn(formObj).SnakeOn("f_"+name).WithReq(ctx.R).IsAllowed() != nil && info.Verifier().Do(PermUpdate).ObjectOn(formObj).SnakeOn("f_"+name).WithReq(ctx.R).IsAllowed() != nil {
 					continue
 				}
+				if !info.FieldWritable(ctx.R, name) {
+					continue
+				}
 			}
 			if v, err := reflectutils.Get(formObj, f.name); err == nil {
 				reflectutils.Set(toObj, f.name, v)
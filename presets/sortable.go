@@ -0,0 +1,326 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+	"github.com/theplant/relay"
+)
+
+// SortablePositionStep is the gap between the positions of the records when they are renumbered,
+// and the gap to the first or the last record when a record is moved before or after it.
+const SortablePositionStep = 1024
+
+type SortableBuilder struct {
+	mb              *ModelBuilder
+	field           string
+	dbColumn        string
+	transactionFunc func(ctx *web.EventContext, f func(ctx *web.EventContext) error) error
+}
+
+// Sortable lists the records by the position field ascending when no column is sorted,
+// and dragging a row onto another one moves it before or after that row. The position field must be
+// a signed integer or a float, since the record moved before the first one can get a negative position.
+// The record moved gets a position between its new neighbours, a float field like Container.DisplayOrder
+// is rarely renumbered, while an integer field is renumbered when there is no gap between the neighbours.
+// The neighbours are searched among all the records, so the order is kept with the filters and the pagination.
+func (b *ListingBuilder) Sortable(field string) (r *SortableBuilder) {
+	if b.sortable != nil && b.sortable.field == field {
+		return b.sortable
+	}
+	b.sortable = &SortableBuilder{
+		mb:       b.mb,
+		field:    field,
+		dbColumn: strcase.ToSnake(field),
+	}
+	b.sortable.checkField(field)
+	return b.sortable
+}
+
+// DBColumn sets the column of the position field, default is the snake case of the field
+func (b *SortableBuilder) DBColumn(v string) (r *SortableBuilder) {
+	b.dbColumn = v
+	return b
+}
+
+// TransactionFunc is used to save the positions of the records atomically,
+// if not set, the data operator will be used if it implements Transactor.
+func (b *SortableBuilder) TransactionFunc(v func(ctx *web.EventContext, f func(ctx *web.EventContext) error) error) (r *SortableBuilder) {
+	b.transactionFunc = v
+	return b
+}
+
+func (b *SortableBuilder) checkField(name string) {
+	t := reflectutils.GetType(b.mb.model, name)
+	if t == nil {
+		panic(fmt.Sprintf("sortable field %s not found in %s", name, b.mb.modelType))
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64:
+	default:
+		panic(fmt.Sprintf("sortable field %s must be a signed integer or a float", name))
+	}
+}
+
+func (b *SortableBuilder) isFloat() bool {
+	kind := reflectutils.GetType(b.mb.model, b.field).Kind()
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func (b *SortableBuilder) position(obj any) float64 {
+	rv := reflectValueOf(obj, b.field)
+	if !rv.IsValid() {
+		return 0
+	}
+	return rv.Convert(reflect.TypeOf(float64(0))).Float()
+}
+
+func (b *SortableBuilder) setPosition(obj any, v float64) error {
+	t := reflectutils.GetType(obj, b.field)
+	return reflectutils.Set(obj, b.field, reflect.ValueOf(v).Convert(t).Interface())
+}
+
+// orderBys orders the records by the position, and by the primary field for the same positions
+func (b *SortableBuilder) orderBys(desc bool) []relay.OrderBy {
+	r := []relay.OrderBy{{Field: b.field, Desc: desc}}
+	if b.mb.primaryField != "" {
+		r = append(r, relay.OrderBy{Field: b.mb.primaryField, Desc: desc})
+	}
+	return r
+}
+
+func (b *SortableBuilder) transaction(ctx *web.EventContext, f func(ctx *web.EventContext) error) error {
+	if b.transactionFunc != nil {
+		return b.transactionFunc(ctx, f)
+	}
+	if t, ok := b.mb.p.dataOperator.(Transactor); ok {
+		return t.Transaction(ctx, f)
+	}
+	return f(ctx)
+}
+
+// search lists the records of the listing conditions ordered by the position, the filters aren't applied
+func (b *SortableBuilder) search(evCtx *web.EventContext, cond *SQLCondition, desc bool, perPage int64, page int64) (objs []any, err error) {
+	lb := b.mb.listing
+	params := &SearchParams{
+		Model:         b.mb.NewModel(),
+		PageURL:       evCtx.R.URL,
+		SQLConditions: slices.Clone(lb.conditions),
+		OrderBys:      b.orderBys(desc),
+		PerPage:       perPage,
+		Page:          page,
+	}
+	if cond != nil {
+		params.SQLConditions = append(params.SQLConditions, cond)
+	}
+	result, err := lb.Searcher(evCtx, params)
+	if err != nil {
+		return nil, err
+	}
+	reflectutils.ForEach(result.Nodes, func(obj interface{}) {
+		objs = append(objs, obj)
+	})
+	return
+}
+
+// sortableActive reports whether the rows can be dragged, the records must be listed in the order of the positions
+func (c *ListingCompo) sortableActive(evCtx *web.EventContext) bool {
+	return c.lb.sortable != nil && c.layout(evCtx) == ListingLayoutTable && !c.inTrash(evCtx) &&
+		len(c.OrderBys) == 0 && c.Keyword == ""
+}
+
+func (c *ListingCompo) sortableMoveAllowed(evCtx *web.EventContext, obj any) bool {
+	return c.lb.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn("f_"+c.lb.sortable.field).WithReq(evCtx.R).IsAllowed() == nil &&
+		c.lb.mb.Info().FieldWritable(evCtx.R, c.lb.sortable.field)
+}
+
+// sortableRow makes the row draggable and droppable, the dragged row is moved before the row dropped on
+// if it's dropped on the upper half, otherwise after it.
+func (c *ListingCompo) sortableRow(ctx context.Context, row h.MutableAttrHTMLComponent, id string, obj any) {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	if !c.sortableActive(evCtx) {
+		return
+	}
+	if c.sortableMoveAllowed(evCtx, obj) {
+		row.SetAttr("draggable", "true")
+		row.SetAttr("@dragstart", fmt.Sprintf(`$event.dataTransfer.setData("text/plain", %s)`, h.JSONString(id)))
+	}
+	row.SetAttr("@dragover.prevent", true)
+	row.SetAttr("@drop.prevent", fmt.Sprintf(`const id = $event.dataTransfer.getData("text/plain"); if (id && id !== %s) { %s }`,
+		h.JSONString(id),
+		stateful.PostAction(ctx, c, c.MoveSortableRow, SortableMoveRequest{TargetID: id},
+			stateful.WithAppendFix(`v.request.id = id;`),
+			stateful.WithAppendFix(`v.request.after = $event.clientY > $event.currentTarget.getBoundingClientRect().top + $event.currentTarget.offsetHeight / 2;`),
+		).Go(),
+	))
+}
+
+type SortableMoveRequest struct {
+	ID       string `json:"id"`
+	TargetID string `json:"target_id"`
+	After    bool   `json:"after"`
+}
+
+// MoveSortableRow moves the record before or after the target record, the positions changed are saved in a transaction
+func (c *ListingCompo) MoveSortableRow(ctx context.Context, req SortableMoveRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	sb := c.lb.sortable
+	if sb == nil {
+		return r, errors.New("sortable is not enabled")
+	}
+	if req.ID == req.TargetID {
+		return r, nil
+	}
+
+	eb := c.lb.mb.editing
+	obj, err := eb.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		return r, err
+	}
+	if !c.sortableMoveAllowed(evCtx, obj) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+	target, err := eb.Fetcher(c.lb.mb.NewModel(), req.TargetID, evCtx)
+	if err != nil {
+		return r, err
+	}
+
+	var changed []any
+	err = sb.transaction(evCtx, func(txCtx *web.EventContext) error {
+		position, ok, err := sb.positionBetween(txCtx, target, req.ID, req.After)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := sb.setPosition(obj, position); err != nil {
+				return err
+			}
+			changed = []any{obj}
+		} else if changed, err = sb.renumber(txCtx, obj, req.TargetID, req.After); err != nil {
+			return err
+		}
+		// the other records renumbered must be movable by the user too
+		for _, o := range changed {
+			if !c.sortableMoveAllowed(txCtx, o) {
+				return perm.PermissionDenied
+			}
+		}
+		for _, o := range changed {
+			if err := c.lb.mb.checkVersion(txCtx, o); err != nil {
+				return err
+			}
+			if err := eb.Saver(o, ObjectID(o), txCtx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, perm.PermissionDenied) {
+		ShowMessage(&r, err.Error(), ColorWarning)
+		return r, nil
+	}
+	if err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	payload := PayloadModelsUpdated{Models: map[string]any{}}
+	for _, o := range changed {
+		id := ObjectID(o)
+		payload.Ids = append(payload.Ids, id)
+		payload.Models[id] = o
+	}
+	ShowMessage(&r, msgr.SuccessfullyUpdated, "")
+	r.Emit(c.lb.mb.NotifModelsUpdated(), payload)
+	return r, nil
+}
+
+// positionBetween returns the position between the target and its neighbour on the side the record is moved to,
+// it isn't ok if there is no room between them or there are other records at the position of the target.
+func (b *SortableBuilder) positionBetween(evCtx *web.EventContext, target any, id string, after bool) (position float64, ok bool, err error) {
+	targetPosition := b.position(target)
+	same, err := b.search(evCtx, &SQLCondition{Query: fmt.Sprintf("%s = ?", b.dbColumn), Args: []interface{}{targetPosition}}, false, 2, 1)
+	if err != nil {
+		return
+	}
+	if slices.ContainsFunc(same, func(o any) bool { id0 := ObjectID(o); return id0 != id && id0 != ObjectID(target) }) {
+		return 0, false, nil
+	}
+
+	op, step := ">", float64(SortablePositionStep)
+	if !after {
+		op, step = "<", -step
+	}
+	neighbours, err := b.search(evCtx, &SQLCondition{Query: fmt.Sprintf("%s %s ?", b.dbColumn, op), Args: []interface{}{targetPosition}}, !after, 2, 1)
+	if err != nil {
+		return
+	}
+	neighbours = slices.DeleteFunc(neighbours, func(o any) bool { return ObjectID(o) == id })
+	if len(neighbours) == 0 {
+		return targetPosition + step, true, nil
+	}
+
+	position = (targetPosition + b.position(neighbours[0])) / 2
+	if !b.isFloat() {
+		position = math.Floor(position)
+	}
+	if position == targetPosition || position == b.position(neighbours[0]) {
+		return 0, false, nil
+	}
+	return position, true, nil
+}
+
+// renumber places the record before or after the target and gives all the records the positions apart by SortablePositionStep,
+// it returns the records whose positions are changed.
+func (b *SortableBuilder) renumber(evCtx *web.EventContext, obj any, targetID string, after bool) (changed []any, err error) {
+	id := ObjectID(obj)
+	var all []any
+	for page := int64(1); ; page++ {
+		objs, err := b.search(evCtx, nil, false, PerPageMax, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			if ObjectID(o) != id {
+				all = append(all, o)
+			}
+		}
+		if len(objs) < PerPageMax {
+			break
+		}
+	}
+
+	i := slices.IndexFunc(all, func(o any) bool { return ObjectID(o) == targetID })
+	if i < 0 {
+		return nil, errors.Errorf("record %s not found", targetID)
+	}
+	if after {
+		i++
+	}
+	all = slices.Insert(all, i, obj)
+
+	for i, o := range all {
+		position := float64((i + 1) * SortablePositionStep)
+		if ObjectID(o) != id && b.position(o) == position {
+			continue
+		}
+		if err := b.setPosition(o, position); err != nil {
+			return nil, err
+		}
+		changed = append(changed, o)
+	}
+	return
+}
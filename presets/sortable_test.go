@@ -0,0 +1,137 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sortableBanner struct {
+	ID       uint
+	Position int
+}
+
+func TestMoveSortableRow(t *testing.T) {
+	banners := []*sortableBanner{{ID: 1, Position: 1024}, {ID: 2, Position: 2048}, {ID: 3, Position: 2049}, {ID: 4, Position: 4096}}
+	transactions := 0
+
+	mb := New().Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("editor").WhoAre(perm.Denied).ToDo(PermUpdate).On("*:presets:sortable_banners:sortable_banners:2:*"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	})).Model(&sortableBanner{})
+	mb.Listing().SearchFunc(func(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+		var nodes []*sortableBanner
+		for _, b := range banners {
+			matched := true
+			for _, cond := range params.SQLConditions {
+				pos := cond.Args[0].(float64)
+				switch {
+				case strings.HasSuffix(cond.Query, "= ?"):
+					matched = matched && float64(b.Position) == pos
+				case strings.HasSuffix(cond.Query, "> ?"):
+					matched = matched && float64(b.Position) > pos
+				case strings.HasSuffix(cond.Query, "< ?"):
+					matched = matched && float64(b.Position) < pos
+				}
+			}
+			if matched {
+				c := *b
+				nodes = append(nodes, &c)
+			}
+		}
+		slices.SortFunc(nodes, func(a, b *sortableBanner) int {
+			if params.OrderBys[0].Desc {
+				return b.Position - a.Position
+			}
+			return a.Position - b.Position
+		})
+		start := min(int((params.Page-1)*params.PerPage), len(nodes))
+		end := min(start+int(params.PerPage), len(nodes))
+		return &SearchResult{Nodes: nodes[start:end]}, nil
+	})
+	mb.Editing().
+		FetchFunc(func(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+			for _, b := range banners {
+				if ObjectID(b) == id {
+					c := *b
+					return &c, nil
+				}
+			}
+			return nil, errors.New("not found")
+		}).
+		SaveFunc(func(obj interface{}, id string, ctx *web.EventContext) error {
+			for i, b := range banners {
+				if ObjectID(b) == id {
+					banners[i] = obj.(*sortableBanner)
+				}
+			}
+			return nil
+		})
+	mb.Listing().Sortable("Position").TransactionFunc(func(ctx *web.EventContext, f func(ctx *web.EventContext) error) error {
+		transactions++
+		return f(ctx)
+	})
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing()}
+	positions := func() (r []int) {
+		for _, b := range banners {
+			r = append(r, b.Position)
+		}
+		return
+	}
+
+	// moved between the neighbours
+	_, err := c.MoveSortableRow(ctx, SortableMoveRequest{ID: "4", TargetID: "1", After: true})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1024, 2048, 2049, 1536}, positions())
+
+	// moved to the end
+	_, err = c.MoveSortableRow(ctx, SortableMoveRequest{ID: "1", TargetID: "3", After: true})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3073, 2048, 2049, 1536}, positions())
+
+	// the records renumbered must be movable by the user
+	editorCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	editorCtx.R.Header.Set("role", "editor")
+	r, err := c.MoveSortableRow(web.WrapEventContext(context.Background(), editorCtx), SortableMoveRequest{ID: "4", TargetID: "3"})
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	assert.Equal(t, []int{3073, 2048, 2049, 1536}, positions())
+
+	// no room between 2048 and 2049, all the records are renumbered
+	_, err = c.MoveSortableRow(ctx, SortableMoveRequest{ID: "4", TargetID: "3"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4096, 1024, 3072, 2048}, positions())
+	assert.Equal(t, 4, transactions)
+}
+
+func TestSortableField(t *testing.T) {
+	type unsignedBanner struct {
+		ID       uint
+		Position uint
+	}
+	type floatBanner struct {
+		ID       uint
+		Position float64
+	}
+
+	assert.PanicsWithValue(t, "sortable field Position must be a signed integer or a float", func() {
+		New().Model(&unsignedBanner{}).Listing().Sortable("Position")
+	})
+	assert.NotPanics(t, func() {
+		New().Model(&floatBanner{}).Listing().Sortable("Position")
+	})
+}
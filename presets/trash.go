@@ -0,0 +1,208 @@
+package presets
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	vx "github.com/qor5/x/v3/ui/vuetifyx"
+	h "github.com/theplant/htmlgo"
+)
+
+// Trash adds a trash tab to the listing which lists the soft deleted records,
+// they can be restored or deleted permanently, the data operator must implement Trasher.
+func (b *ListingBuilder) Trash(v bool) (r *ListingBuilder) {
+	b.trash = v
+	return b
+}
+
+func (c *ListingCompo) trashAllowed(evCtx *web.EventContext) bool {
+	if !c.lb.trash {
+		return false
+	}
+	verifier := c.lb.mb.Info().Verifier()
+	return verifier.Do(PermRestore).WithReq(evCtx.R).IsAllowed() == nil ||
+		verifier.Do(PermPurge).WithReq(evCtx.R).IsAllowed() == nil
+}
+
+func (c *ListingCompo) inTrash(evCtx *web.EventContext) bool {
+	return c.Trash && c.trashAllowed(evCtx)
+}
+
+func (c *ListingCompo) trasher() (Trasher, error) {
+	t, ok := c.lb.mb.p.dataOperator.(Trasher)
+	if !ok {
+		return nil, errors.New("trash requires a data operator implements Trasher")
+	}
+	return t, nil
+}
+
+// trashedAllowed fetches the soft deleted record and checks the permission of the action on it
+func (c *ListingCompo) trashedAllowed(evCtx *web.EventContext, t Trasher, permAction string, id string) (bool, error) {
+	if !c.lb.trash {
+		return false, nil
+	}
+	obj, err := t.FetchTrashed(c.lb.mb.NewModel(), id, evCtx)
+	if err != nil {
+		return false, err
+	}
+	return c.lb.mb.Info().Verifier().Do(permAction).ObjectOn(obj).WithReq(evCtx.R).IsAllowed() == nil, nil
+}
+
+func (c *ListingCompo) trashTab(ctx context.Context) h.HTMLComponent {
+	_, msgr := c.MustGetEventContext(ctx)
+	return VTab(
+		VIcon("mdi-delete-outline").Size(SizeSmall).Class("mr-1"),
+		h.Text(msgr.ListingTrash),
+	).Attr("@click", stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+		target.Page = 0
+		target.After, target.Before = nil, nil
+		target.ActiveFilterTab = ""
+		target.ActiveView = ""
+		target.FilterQuery = ""
+		target.SelectedIds = nil
+		target.Trash = true
+	}).ThenScript(ListingCompo_JsScrollToTop).Go())
+}
+
+func (c *ListingCompo) rowMenuItemFuncs(ctx context.Context) []vx.RowMenuItemFunc {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	if !c.inTrash(evCtx) {
+		return c.lb.RowMenu().listingItemFuncs(evCtx)
+	}
+
+	item := func(permAction, icon, label string, onClick func(id string) string) vx.RowMenuItemFunc {
+		return func(obj interface{}, id string, ctx *web.EventContext) h.HTMLComponent {
+			if c.lb.mb.Info().Verifier().Do(permAction).ObjectOn(obj).WithReq(ctx.R).IsAllowed() != nil {
+				return nil
+			}
+			return VListItem(
+				web.Slot(VIcon(icon)).Name("prepend"),
+				VListItemTitle(h.Text(label)),
+			).Attr("@click", onClick(id))
+		}
+	}
+	return []vx.RowMenuItemFunc{
+		item(PermRestore, "mdi-restore", msgr.ListingTrashRestore, func(id string) string {
+			return stateful.PostAction(ctx, c, c.RestoreTrashed, TrashRequest{ID: id}).Go()
+		}),
+		item(PermPurge, "mdi-delete-forever", msgr.ListingTrashPurge, func(id string) string {
+			return stateful.PostAction(ctx, c, c.OpenPurgeDialog, TrashRequest{ID: id}).Go()
+		}),
+	}
+}
+
+type TrashRequest struct {
+	ID string `json:"id"`
+}
+
+func (c *ListingCompo) RestoreTrashed(ctx context.Context, req TrashRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	t, err := c.trasher()
+	if err != nil {
+		return r, err
+	}
+	if ok, err := c.trashedAllowed(evCtx, t, PermRestore, req.ID); err != nil || !ok {
+		if err == nil {
+			err = perm.PermissionDenied
+		}
+		ShowMessage(&r, err.Error(), ColorWarning)
+		return r, nil
+	}
+
+	if err := c.lb.restore(t, evCtx, &r, req.ID); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	ShowMessage(&r, msgr.ListingTrashRestored, "")
+	return r, nil
+}
+
+// restore restores the soft deleted record and notifies the listings
+func (b *ListingBuilder) restore(t Trasher, evCtx *web.EventContext, r *web.EventResponse, id string) error {
+	obj := b.mb.NewModel()
+	if err := t.Restore(obj, id, evCtx); err != nil {
+		return err
+	}
+	if err := b.indexObject(evCtx, obj); err != nil {
+		return err
+	}
+	r.Emit(b.mb.NotifModelsCreated(), PayloadModelsCreated{Models: []any{obj}})
+	return nil
+}
+
+// deleteUndo restores the records deleted, which are soft deleted by the data operator,
+// all of them are restored in a transaction if the data operator implements Transactor.
+func (b *ListingBuilder) deleteUndo(ids []string) UndoFunc {
+	t, ok := b.mb.p.dataOperator.(Trasher)
+	if !ok || !t.Trashable(b.mb.NewModel()) {
+		return nil
+	}
+	return func(ctx *web.EventContext, r *web.EventResponse) error {
+		// the listings are only notified once all the records are restored
+		var restored web.EventResponse
+		restoreAll := func(ctx *web.EventContext) error {
+			for _, id := range ids {
+				if err := b.restore(t, ctx, &restored, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var err error
+		if tx, ok := b.mb.p.dataOperator.(Transactor); ok {
+			err = tx.Transaction(ctx, restoreAll)
+		} else {
+			err = restoreAll(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		web.AppendRunScripts(r, restored.RunScript)
+		return nil
+	}
+}
+
+func (c *ListingCompo) OpenPurgeDialog(ctx context.Context, req TrashRequest) (r web.EventResponse, err error) {
+	_, msgr := c.MustGetEventContext(ctx)
+	c.dialog(&r, VCard(
+		VCardTitle(h.Text(msgr.ListingTrashPurge)),
+		VCardText(h.Text(msgr.ListingTrashPurgeConfirmation)),
+		VCardActions(
+			VSpacer(),
+			VBtn(msgr.Cancel).Variant(VariantFlat).Class("ml-2").Attr("@click", c.closeActionDialog()),
+			VBtn(msgr.ListingTrashPurge).Color(ColorError).Variant(VariantFlat).Theme(ThemeDark).
+				Attr("@click", stateful.PostAction(ctx, c, c.PurgeTrashed, req).Go()),
+		),
+	), "500")
+	return r, nil
+}
+
+func (c *ListingCompo) PurgeTrashed(ctx context.Context, req TrashRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	t, err := c.trasher()
+	if err != nil {
+		return r, err
+	}
+	if ok, err := c.trashedAllowed(evCtx, t, PermPurge, req.ID); err != nil || !ok {
+		if err == nil {
+			err = perm.PermissionDenied
+		}
+		ShowMessage(&r, err.Error(), ColorWarning)
+		return r, nil
+	}
+
+	if err := t.Purge(c.lb.mb.NewModel(), req.ID, evCtx); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+
+	ShowMessage(&r, msgr.ListingTrashPurged, "")
+	web.AppendRunScripts(&r, c.closeActionDialog())
+	r.Emit(c.lb.mb.NotifModelsDeleted(), PayloadModelsDeleted{Ids: []string{req.ID}})
+	return r, nil
+}
@@ -0,0 +1,157 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type trashTestOperator struct {
+	apiTestOperator
+	trashed      []*foo
+	hardDeletion bool
+}
+
+func (op *trashTestOperator) Trashable(model interface{}) bool {
+	return !op.hardDeletion
+}
+
+func (op *trashTestOperator) Search(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+	if params.Trashed {
+		return (&apiTestOperator{records: op.trashed}).Search(ctx, params)
+	}
+	return op.apiTestOperator.Search(ctx, params)
+}
+
+func (op *trashTestOperator) Delete(obj interface{}, id string, ctx *web.EventContext) error {
+	for _, r := range op.records {
+		if fmt.Sprint(r.ID) == id {
+			op.trashed = append(op.trashed, r)
+		}
+	}
+	return op.apiTestOperator.Delete(obj, id, ctx)
+}
+
+func (op *trashTestOperator) FetchTrashed(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+	return (&apiTestOperator{records: op.trashed}).Fetch(obj, id, ctx)
+}
+
+func (op *trashTestOperator) Restore(obj interface{}, id string, ctx *web.EventContext) error {
+	for i, r := range op.trashed {
+		if fmt.Sprint(r.ID) == id {
+			op.records = append(op.records, r)
+			op.trashed = append(op.trashed[:i], op.trashed[i+1:]...)
+			*obj.(*foo) = *r
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func (op *trashTestOperator) Purge(obj interface{}, id string, ctx *web.EventContext) error {
+	for i, r := range op.trashed {
+		if fmt.Sprint(r.ID) == id {
+			op.trashed = append(op.trashed[:i], op.trashed[i+1:]...)
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func TestTrash(t *testing.T) {
+	op := &trashTestOperator{}
+	for i, v := range []string{"live", "trashed 2", "trashed 3"} {
+		r := &foo{Version: v}
+		r.ID = uint(i + 1)
+		op.records = append(op.records, r)
+	}
+	require.NoError(t, op.Delete(nil, "2", nil))
+	require.NoError(t, op.Delete(nil, "3", nil))
+
+	pb := New().DataOperator(op).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor("editor").WhoAre(perm.Denied).ToDo(PermRestore, PermPurge).On(":presets:foos:foos:3:"),
+	).SubjectsFunc(func(r *http.Request) []string {
+		return []string{r.Header.Get("role")}
+	}))
+	mb := pb.Model(&foo{})
+	mb.Listing("Version").Trash(true)
+
+	request := func(role string) (*web.EventContext, context.Context) {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("role", role)
+		evCtx := &web.EventContext{R: r, W: httptest.NewRecorder()}
+		return evCtx, web.WrapEventContext(context.Background(), evCtx)
+	}
+
+	evCtx, ctx := request("editor")
+	c := &ListingCompo{lb: mb.Listing(), Trash: true}
+	body := h.MustString(c.dataTable(ctx), ctx)
+	assert.Contains(t, body, "trashed 2")
+	assert.NotContains(t, body, "live", "only the soft deleted records are listed in the trash")
+
+	var items []string
+	for _, f := range c.rowMenuItemFuncs(ctx) {
+		for _, obj := range []*foo{op.trashed[0], op.trashed[1]} {
+			if comp := f(obj, fmt.Sprint(obj.ID), evCtx); comp != nil {
+				items = append(items, fmt.Sprint(obj.ID))
+			}
+		}
+	}
+	assert.Equal(t, []string{"2", "2"}, items, "the actions denied on the record aren't shown")
+
+	// the permissions are checked on the soft deleted record
+	r, err := c.RestoreTrashed(ctx, TrashRequest{ID: "3"})
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	r, err = c.PurgeTrashed(ctx, TrashRequest{ID: "3"})
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, perm.PermissionDenied.Error())
+	assert.Len(t, op.trashed, 2)
+
+	r, err = c.RestoreTrashed(ctx, TrashRequest{ID: "2"})
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, Messages_en_US.ListingTrashRestored)
+	assert.Len(t, op.records, 2)
+
+	_, ctx = request("admin")
+	r, err = c.PurgeTrashed(ctx, TrashRequest{ID: "3"})
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, Messages_en_US.ListingTrashPurged)
+	assert.Empty(t, op.trashed)
+
+	// the trash isn't listed if it isn't enabled
+	mb.Listing().Trash(false)
+	_, ctx = request("admin")
+	body = h.MustString(c.dataTable(ctx), ctx)
+	assert.Contains(t, body, "live")
+}
+
+func TestExportTrash(t *testing.T) {
+	op := &trashTestOperator{}
+	for i, v := range []string{"live", "trashed"} {
+		r := &foo{Version: v}
+		r.ID = uint(i + 1)
+		op.records = append(op.records, r)
+	}
+	require.NoError(t, op.Delete(nil, "2", nil))
+
+	mb := New().DataOperator(op).Model(&foo{})
+	mb.Listing("Version").Trash(true).Export(ExportFormatCSV)
+
+	href, err := url.Parse(mb.Listing().exporting.href(&ListingCompo{lb: mb.Listing(), Trash: true}, ExportFormatCSV))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	mb.Listing().exporting.ServeHTTP(w, httptest.NewRequest("GET", "/?"+href.RawQuery, nil))
+	assert.Contains(t, w.Body.String(), "trashed")
+	assert.NotContains(t, w.Body.String(), "live", "the trash is exported as it's listed")
+}
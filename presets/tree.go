@@ -0,0 +1,402 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/web/v3/stateful"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// TreeIndent is the indent of a level of the tree nodes in pixels
+const TreeIndent = 24
+
+type TreeBuilder struct {
+	mb          *ModelBuilder
+	parentField string
+	dbColumn    string
+	nodeFunc    ObjectComponentFunc
+}
+
+// Tree adds a tree layout to the listing of the self-referencing model, the records are listed under
+// the records referenced by the parent field like ParentID, the children are loaded when a node is expanded,
+// and dragging a node onto another one re-parents it with the editing validator and saver.
+// The records matching the keyword or the filters are listed with the breadcrumbs of their ancestors.
+func (b *ListingBuilder) Tree(parentField string) (r *TreeBuilder) {
+	if b.tree != nil && b.tree.parentField == parentField {
+		return b.tree
+	}
+	b.tree = &TreeBuilder{
+		mb:          b.mb,
+		parentField: parentField,
+		dbColumn:    strcase.ToSnake(parentField),
+	}
+	b.tree.checkField(parentField)
+	return b.tree
+}
+
+// DBColumn sets the column which is used to search the children of a node, default is the snake case of the parent field
+func (b *TreeBuilder) DBColumn(v string) (r *TreeBuilder) {
+	b.dbColumn = v
+	return b
+}
+
+// NodeFunc renders the body of a node, the first listing field is rendered by default
+func (b *TreeBuilder) NodeFunc(v ObjectComponentFunc) (r *TreeBuilder) {
+	b.nodeFunc = v
+	return b
+}
+
+func (b *TreeBuilder) checkField(name string) {
+	t := reflectutils.GetType(b.mb.model, name)
+	if t == nil {
+		panic(fmt.Sprintf("tree parent field %s not found in %s", name, b.mb.modelType))
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.String:
+	default:
+		panic(fmt.Sprintf("tree parent field %s must be an integer or a string, or a pointer to them", name))
+	}
+}
+
+// parentID returns the id of the parent of the record, it's empty for the root nodes
+func (b *TreeBuilder) parentID(obj any) string {
+	rv := reflectValueOf(obj, b.parentField)
+	if !rv.IsValid() || rv.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// setParentID sets the parent field of the record to the id, or to the zero value for the root nodes
+func (b *TreeBuilder) setParentID(obj any, id string) error {
+	t := reflectutils.GetType(obj, b.parentField)
+	if id == "" {
+		return reflectutils.Set(obj, b.parentField, reflect.Zero(t).Interface())
+	}
+	et := t
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	v := reflect.New(et).Elem()
+	switch et.Kind() {
+	case reflect.String:
+		v.SetString(id)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(id, 10, et.Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		v.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(id, 10, et.Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		v.SetUint(n)
+	}
+	if t.Kind() == reflect.Ptr {
+		v = v.Addr()
+	}
+	return reflectutils.Set(obj, b.parentField, v.Interface())
+}
+
+// rootCondition matches the records without parent, and the records whose parent is deleted or trashed
+// if the data operator is able to find them, so that their subtrees are still listed.
+func (b *TreeBuilder) rootCondition(evCtx *web.EventContext) (*SQLCondition, error) {
+	t := reflectutils.GetType(b.mb.model, b.parentField)
+	cond := &SQLCondition{Query: fmt.Sprintf("%s IS NULL", b.dbColumn)}
+	if t.Kind() != reflect.Ptr {
+		cond.Query = fmt.Sprintf("%s IS NULL OR %s = ?", b.dbColumn, b.dbColumn)
+		cond.Args = []interface{}{reflect.Zero(t).Interface()}
+	}
+	if finder, ok := b.mb.p.dataOperator.(OrphanFinder); ok {
+		orphan, err := finder.OrphanCondition(b.mb.NewModel(), b.dbColumn, evCtx)
+		if err != nil {
+			return nil, err
+		}
+		cond.Query = fmt.Sprintf("%s OR %s", cond.Query, orphan.Query)
+		cond.Args = append(cond.Args, orphan.Args...)
+	}
+	cond.Query = fmt.Sprintf("(%s)", cond.Query)
+	return cond, nil
+}
+
+func (b *TreeBuilder) childrenCondition(parentIDs []string) *SQLCondition {
+	return &SQLCondition{
+		Query: fmt.Sprintf("%s IN ?", b.dbColumn),
+		Args:  []interface{}{parentIDs},
+	}
+}
+
+// ancestors returns the ancestors of the record from the root, the records are fetched by the editing fetcher,
+// the chain ends at a parent which is not found, like a deleted or trashed one.
+func (b *TreeBuilder) ancestors(evCtx *web.EventContext, obj any, cache map[string]any) (r []any, err error) {
+	seen := map[string]bool{ObjectID(obj): true}
+	for id := b.parentID(obj); id != "" && !seen[id]; id = b.parentID(obj) {
+		seen[id] = true
+		parent, ok := cache[id]
+		if !ok {
+			parent, err = b.mb.editing.Fetcher(b.mb.NewModel(), id, evCtx)
+			if errors.Is(err, ErrRecordNotFound) {
+				return r, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			cache[id] = parent
+		}
+		r = append([]any{parent}, r...)
+		obj = parent
+	}
+	return
+}
+
+func (c *ListingCompo) treeMoveAllowed(evCtx *web.EventContext, obj any) bool {
+	return c.lb.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn("f_"+c.lb.tree.parentField).WithReq(evCtx.R).IsAllowed() == nil &&
+		c.lb.mb.Info().FieldWritable(evCtx.R, c.lb.tree.parentField)
+}
+
+// searchPage lists the records of the page of the listing matching the search params and the conditions
+func (c *ListingCompo) searchPage(evCtx *web.EventContext, searchParams *SearchParams, conds ...*SQLCondition) (objs []any, result *SearchResult) {
+	params := *searchParams
+	params.Model = c.lb.mb.NewModel()
+	if len(conds) > 0 {
+		params.SQLConditions = append(slices.Clone(searchParams.SQLConditions), conds...)
+	}
+	result, err := c.lb.Searcher(evCtx, &params)
+	if err != nil {
+		panic(errors.Wrap(err, "searcher error"))
+	}
+	reflectutils.ForEach(result.Nodes, func(obj interface{}) {
+		objs = append(objs, obj)
+	})
+	return
+}
+
+// searchAll lists all the records matching the search params and the conditions, page by page
+func (c *ListingCompo) searchAll(evCtx *web.EventContext, searchParams *SearchParams, conds ...*SQLCondition) (objs []any) {
+	params := *searchParams
+	params.PerPage = PerPageMax
+	params.RelayPagination = nil
+	params.RelayPaginateRequest = nil
+	for params.Page = 1; ; params.Page++ {
+		page, _ := c.searchPage(evCtx, &params, conds...)
+		objs = append(objs, page...)
+		if len(page) < PerPageMax {
+			return
+		}
+	}
+}
+
+func (c *ListingCompo) treeView(ctx context.Context, searchParams *SearchParams) h.HTMLComponent {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	tb := c.lb.tree
+
+	if c.Keyword != "" || c.FilterQuery != "" {
+		return c.treeSearchResults(ctx, searchParams)
+	}
+
+	// the roots are paginated like the rows of the table, while all the children of the expanded nodes are listed
+	rootCond, err := tb.rootCondition(evCtx)
+	if err != nil {
+		panic(errors.Wrap(err, "tree root condition error"))
+	}
+	roots, result := c.searchPage(evCtx, searchParams, rootCond)
+	if len(roots) == 0 {
+		return c.buildDataTableAdditions(ctx, searchParams, result)
+	}
+
+	return h.Components(
+		h.Div(
+			h.Div(h.Text(msgr.ListingTreeMoveToRoot)).
+				Class("presets-tree-root text-caption text-grey text-center pa-2 mb-2 rounded border-dashed").
+				Attr("@dragover.prevent", true).
+				Attr("@drop.prevent", c.treeDropScript(ctx, "")),
+			c.treeLevel(ctx, searchParams, roots, 0),
+		).Class("presets-tree"),
+		c.buildDataTableAdditions(ctx, searchParams, result),
+	)
+}
+
+// treeLevel renders the sibling nodes, the children of all of them are searched at once,
+// to know which nodes have children and to render the children of the expanded nodes.
+func (c *ListingCompo) treeLevel(ctx context.Context, searchParams *SearchParams, nodes []any, depth int) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	tb := c.lb.tree
+
+	ids := make([]string, 0, len(nodes))
+	for _, obj := range nodes {
+		ids = append(ids, ObjectID(obj))
+	}
+	children := map[string][]any{}
+	for _, child := range c.searchAll(evCtx, searchParams, tb.childrenCondition(ids)) {
+		parentID := tb.parentID(child)
+		children[parentID] = append(children[parentID], child)
+	}
+
+	var comps []h.HTMLComponent
+	for _, obj := range nodes {
+		id := ObjectID(obj)
+		expanded := slices.Contains(c.TreeExpanded, id)
+		comps = append(comps, c.treeNode(ctx, obj, depth, len(children[id]) > 0, expanded))
+		if expanded && len(children[id]) > 0 {
+			comps = append(comps, c.treeLevel(ctx, searchParams, children[id], depth+1))
+		}
+	}
+	return h.Components(comps...)
+}
+
+func (c *ListingCompo) treeNode(ctx context.Context, obj any, depth int, hasChildren bool, expanded bool) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	id := ObjectID(obj)
+
+	toggle := h.HTMLComponent(h.Span("").Style("display: inline-block; width: 28px;"))
+	if hasChildren {
+		icon := "mdi-chevron-right"
+		if expanded {
+			icon = "mdi-chevron-down"
+		}
+		toggle = VBtn("").Icon(icon).Size(SizeXSmall).Variant(VariantText).Class("mr-1").
+			Attr("@click.stop", stateful.ReloadAction(ctx, c, func(target *ListingCompo) {
+				if expanded {
+					target.TreeExpanded = slices.DeleteFunc(slices.Clone(target.TreeExpanded), func(v string) bool { return v == id })
+				} else {
+					target.TreeExpanded = append(slices.Clone(target.TreeExpanded), id)
+				}
+			}).Go())
+	}
+
+	node := h.Div(
+		toggle,
+		h.Div(c.treeNodeBody(evCtx, obj)).Class("flex-grow-1"),
+	).Class("presets-tree-node d-flex align-center py-1 pr-2 rounded cursor-pointer").
+		Style(fmt.Sprintf("padding-left: %dpx;", depth*TreeIndent)).
+		Attr("@click", c.openRecordEvent(id)).
+		Attr("@dragover.prevent", true).
+		Attr("@drop.prevent", c.treeDropScript(ctx, id))
+	if c.treeMoveAllowed(evCtx, obj) {
+		node.Attr("draggable", "true").
+			Attr("@dragstart", fmt.Sprintf(`$event.dataTransfer.setData("text/plain", %s)`, h.JSONString(id)))
+	}
+	return node
+}
+
+func (c *ListingCompo) treeNodeBody(evCtx *web.EventContext, obj any) h.HTMLComponent {
+	if c.lb.tree.nodeFunc != nil {
+		return c.lb.tree.nodeFunc(obj, evCtx)
+	}
+	return c.recordSummary(evCtx, obj, c.lb.tree.parentField)
+}
+
+func (c *ListingCompo) treeDropScript(ctx context.Context, parentID string) string {
+	return fmt.Sprintf(`const id = $event.dataTransfer.getData("text/plain"); if (id && id !== %s) { %s }`,
+		h.JSONString(parentID),
+		stateful.PostAction(ctx, c, c.MoveTreeNode, TreeMoveRequest{ParentID: parentID},
+			stateful.WithAppendFix(`v.request.id = id;`),
+		).Go(),
+	)
+}
+
+// treeSearchResults lists the records matching the keyword or the filters, with the breadcrumbs of their ancestors
+func (c *ListingCompo) treeSearchResults(ctx context.Context, searchParams *SearchParams) h.HTMLComponent {
+	evCtx, _ := c.MustGetEventContext(ctx)
+	tb := c.lb.tree
+
+	objs, result := c.searchPage(evCtx, searchParams)
+	if len(objs) == 0 {
+		return c.buildDataTableAdditions(ctx, searchParams, result)
+	}
+
+	cache := map[string]any{}
+	for _, obj := range objs {
+		cache[ObjectID(obj)] = obj
+	}
+	var comps []h.HTMLComponent
+	for _, obj := range objs {
+		ancestors, err := tb.ancestors(evCtx, obj, cache)
+		if err != nil {
+			panic(errors.Wrap(err, "fetch ancestors error"))
+		}
+		var crumbs []h.HTMLComponent
+		for _, a := range ancestors {
+			crumbs = append(crumbs, h.Span("").Children(c.treeNodeBody(evCtx, a)), VIcon("mdi-chevron-right").Size(SizeXSmall).Class("mx-1"))
+		}
+		comps = append(comps, h.Div(
+			h.Div(crumbs...).Class("presets-tree-breadcrumbs d-flex align-center text-caption text-grey"),
+			h.Div(c.treeNodeBody(evCtx, obj)),
+		).Class("presets-tree-node py-2 px-2 rounded cursor-pointer").Attr("@click", c.openRecordEvent(ObjectID(obj))))
+	}
+	return h.Components(
+		h.Div(comps...).Class("presets-tree"),
+		c.buildDataTableAdditions(ctx, searchParams, result),
+	)
+}
+
+type TreeMoveRequest struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id"`
+}
+
+// MoveTreeNode moves the record under the parent, or to the root if the parent is empty,
+// the record can't be moved under itself or its descendants.
+func (c *ListingCompo) MoveTreeNode(ctx context.Context, req TreeMoveRequest) (r web.EventResponse, err error) {
+	evCtx, msgr := c.MustGetEventContext(ctx)
+	tb := c.lb.tree
+	if tb == nil {
+		return r, errors.New("tree is not enabled")
+	}
+
+	eb := c.lb.mb.editing
+	obj, err := eb.Fetcher(c.lb.mb.NewModel(), req.ID, evCtx)
+	if err != nil {
+		return r, err
+	}
+	if !c.treeMoveAllowed(evCtx, obj) {
+		ShowMessage(&r, perm.PermissionDenied.Error(), ColorWarning)
+		return r, nil
+	}
+	if tb.parentID(obj) == req.ParentID {
+		return r, nil
+	}
+
+	if req.ParentID != "" {
+		if req.ParentID == req.ID {
+			ShowMessage(&r, msgr.ListingTreeMoveIntoItself, ColorWarning)
+			return r, nil
+		}
+		parent, err := eb.Fetcher(c.lb.mb.NewModel(), req.ParentID, evCtx)
+		if err != nil {
+			return r, err
+		}
+		ancestors, err := tb.ancestors(evCtx, parent, map[string]any{})
+		if err != nil {
+			return r, err
+		}
+		for _, a := range ancestors {
+			if ObjectID(a) == req.ID {
+				ShowMessage(&r, msgr.ListingTreeMoveIntoItself, ColorWarning)
+				return r, nil
+			}
+		}
+	}
+
+	if err := tb.setParentID(obj, req.ParentID); err != nil {
+		return r, err
+	}
+	c.saveMovedRecord(&r, evCtx, obj, req.ID)
+	return r, nil
+}
@@ -0,0 +1,153 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+	"github.com/theplant/relay"
+)
+
+type treeCategory struct {
+	ID       uint
+	ParentID uint
+	Name     string
+}
+
+func TestMoveTreeNode(t *testing.T) {
+	records := map[string]*treeCategory{
+		"1": {ID: 1, Name: "Clothing"},
+		"2": {ID: 2, ParentID: 1, Name: "Shirts"},
+		"3": {ID: 3, ParentID: 2, Name: "T-Shirts"},
+		"4": {ID: 4, Name: "Shoes"},
+	}
+	mb := New().Model(&treeCategory{})
+	mb.Editing("Name").
+		FetchFunc(func(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+			r, ok := records[id]
+			if !ok {
+				return nil, ErrRecordNotFound
+			}
+			c := *r
+			return &c, nil
+		}).
+		SaveFunc(func(obj interface{}, id string, ctx *web.EventContext) error {
+			records[id] = obj.(*treeCategory)
+			return nil
+		})
+	mb.Listing().Tree("ParentID")
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing()}
+
+	ancestors, err := c.lb.tree.ancestors(evCtx, records["3"], map[string]any{})
+	require.NoError(t, err)
+	require.Len(t, ancestors, 2)
+	assert.Equal(t, "Clothing", ancestors[0].(*treeCategory).Name)
+	assert.Equal(t, "Shirts", ancestors[1].(*treeCategory).Name)
+
+	// the chain ends at a deleted parent
+	ancestors, err = c.lb.tree.ancestors(evCtx, &treeCategory{ID: 5, ParentID: 2}, map[string]any{"2": &treeCategory{ID: 2, ParentID: 42}})
+	require.NoError(t, err)
+	require.Len(t, ancestors, 1)
+	assert.Equal(t, uint(2), ancestors[0].(*treeCategory).ID)
+
+	_, err = c.MoveTreeNode(ctx, TreeMoveRequest{ID: "2", ParentID: "4"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), records["2"].ParentID)
+
+	// a record can't be moved under its descendants
+	for _, parentID := range []int{2, 3} {
+		_, err = c.MoveTreeNode(ctx, TreeMoveRequest{ID: "2", ParentID: strconv.Itoa(parentID)})
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), records["2"].ParentID)
+	}
+
+	_, err = c.MoveTreeNode(ctx, TreeMoveRequest{ID: "2", ParentID: ""})
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), records["2"].ParentID)
+}
+
+func TestTreeView(t *testing.T) {
+	var records []*treeCategory
+	for i := 1; i <= 3; i++ {
+		records = append(records, &treeCategory{ID: uint(i), Name: fmt.Sprintf("Root %d", i)})
+	}
+	// more children than a page of the searcher
+	for i := 1; i <= PerPageMax+5; i++ {
+		records = append(records, &treeCategory{ID: uint(100 + i), ParentID: 1, Name: fmt.Sprintf("Child %d", i)})
+	}
+
+	mb := New().Model(&treeCategory{})
+	mb.Listing("Name").PerPage(2).Tree("ParentID")
+	mb.Listing().SearchFunc(func(ctx *web.EventContext, params *SearchParams) (result *SearchResult, err error) {
+		var objs []*treeCategory
+		for _, r := range records {
+			match := true
+			for _, cond := range params.SQLConditions {
+				switch {
+				case strings.Contains(cond.Query, "IS NULL"):
+					match = match && r.ParentID == 0
+				case strings.Contains(cond.Query, "IN ?"):
+					match = match && slices.Contains(cond.Args[0].([]string), fmt.Sprint(r.ParentID))
+				}
+			}
+			if match {
+				objs = append(objs, r)
+			}
+		}
+		total := len(objs)
+		start := min(int((params.Page-1)*params.PerPage), total)
+		end := min(start+int(params.PerPage), total)
+		return &SearchResult{Nodes: objs[start:end], PageInfo: relay.PageInfo{TotalCount: total}}, nil
+	})
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	ctx := web.WrapEventContext(context.Background(), evCtx)
+	c := &ListingCompo{lb: mb.Listing(), Layout: ListingLayoutTree, TreeExpanded: []string{"1"}}
+
+	body := h.MustString(c.dataTable(ctx), ctx)
+	assert.Contains(t, body, "Root 2")
+	assert.NotContains(t, body, "Root 3", "the roots are paginated")
+	assert.Contains(t, body, "<v-pagination", "the other pages of the roots can be reached")
+	assert.Contains(t, body, fmt.Sprintf("Child %d", PerPageMax+5), "all the children are listed")
+
+	c.Page = 2
+	body = h.MustString(c.dataTable(ctx), ctx)
+	assert.Contains(t, body, "Root 3")
+	assert.NotContains(t, body, "Root 2")
+}
+
+type orphanTestOperator struct {
+	apiTestOperator
+}
+
+func (op *orphanTestOperator) OrphanCondition(model interface{}, column string, ctx *web.EventContext) (*SQLCondition, error) {
+	return &SQLCondition{Query: fmt.Sprintf("%s NOT IN (?)", column), Args: []interface{}{"live ids"}}, nil
+}
+
+func TestTreeRootCondition(t *testing.T) {
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+
+	mb := New().Model(&treeCategory{})
+	cond, err := mb.Listing().Tree("ParentID").rootCondition(evCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "(parent_id IS NULL OR parent_id = ?)", cond.Query)
+	assert.Equal(t, []interface{}{uint(0)}, cond.Args)
+
+	// the records whose parent is deleted are listed as roots
+	mb = New().DataOperator(&orphanTestOperator{}).Model(&treeCategory{})
+	cond, err = mb.Listing().Tree("ParentID").rootCondition(evCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "(parent_id IS NULL OR parent_id = ? OR parent_id NOT IN (?))", cond.Query)
+	assert.Equal(t, []interface{}{uint(0), "live ids"}, cond.Args)
+}
@@ -0,0 +1,159 @@
+package presets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+const (
+	// UndoWindowDefault is the suggested undo window, the undo is disabled by default
+	UndoWindowDefault = 10 * time.Second
+
+	ParamUndoToken = "undo_token"
+)
+
+// UndoFunc reverts a destructive operation, it is called when the user clicks Undo in the snackbar.
+type UndoFunc func(ctx *web.EventContext, r *web.EventResponse) (err error)
+
+// BulkActionUndoFunc is called before the update of the bulk action to capture the records,
+// the returned UndoFunc reverts the update.
+type BulkActionUndoFunc func(selectedIds []string, ctx *web.EventContext) (undo UndoFunc, err error)
+
+// UndoUserFunc returns the user of the request, the undo can only be done by the user who did the operation.
+type UndoUserFunc func(r *http.Request) string
+
+// UndoWindow is how long the deletes and the bulk actions can be undone, 0 disables the undo, which is the default.
+// The undo funcs are kept in the memory of the instance which did the operation, so the undo requests
+// must be routed to it, like by the sticky sessions, when the app runs on several instances.
+func (b *Builder) UndoWindow(v time.Duration) (r *Builder) {
+	b.undoWindow = v
+	return b
+}
+
+// UndoUserFunc binds the undo tokens to the users, if not set, the undo can be done by whoever has the token.
+func (b *Builder) UndoUserFunc(v UndoUserFunc) (r *Builder) {
+	b.undoUserFunc = v
+	return b
+}
+
+func (b *Builder) undoUser(r *http.Request) string {
+	if b.undoUserFunc == nil {
+		return ""
+	}
+	return b.undoUserFunc(r)
+}
+
+// UndoFunc makes the bulk action undoable
+func (b *BulkActionBuilder) UndoFunc(v BulkActionUndoFunc) (r *BulkActionBuilder) {
+	b.undoFunc = v
+	return b
+}
+
+type undoEntry struct {
+	undo      UndoFunc
+	user      string
+	expiresAt time.Time
+}
+
+// undoStore keeps the undo funcs in memory until they expire
+type undoStore struct {
+	mu      sync.Mutex
+	entries map[string]*undoEntry
+}
+
+func newUndoStore() *undoStore {
+	return &undoStore{entries: make(map[string]*undoEntry)}
+}
+
+// randomToken is the unguessable key of the undo funcs and the background bulk actions
+func randomToken() (string, error) {
+	bs := make([]byte, 16)
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bs), nil
+}
+
+func (s *undoStore) add(undo UndoFunc, user string, window time.Duration) (token string, err error) {
+	if token, err = randomToken(); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	// a little more time than the snackbar shows for the latency of the request
+	s.entries[token] = &undoEntry{undo: undo, user: user, expiresAt: now.Add(window + 2*time.Second)}
+	return token, nil
+}
+
+// take removes the undo func of the token if it belongs to the user
+func (s *undoStore) take(token string, user string) (UndoFunc, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[token]
+	if !ok || e.user != user {
+		return nil, false
+	}
+	delete(s.entries, token)
+	if time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.undo, true
+}
+
+// ShowUndoMessage shows the message with an Undo button for the undo window, it shows the plain message
+// if the undo is disabled.
+func (b *Builder) ShowUndoMessage(r *web.EventResponse, ctx *web.EventContext, msg string, undo UndoFunc) {
+	if undo == nil || b.undoWindow <= 0 {
+		ShowMessage(r, msg, "")
+		return
+	}
+	token, err := b.undos.add(undo, b.undoUser(ctx.R), b.undoWindow)
+	if err != nil {
+		ShowMessage(r, msg, "")
+		return
+	}
+	msgr := MustGetMessages(ctx.R)
+	web.AppendRunScripts(r, fmt.Sprintf(`vars.presetsMessage = { show: true, message: %s, color: %s, timeout: %d, undo: { label: %s, token: %s } }`,
+		h.JSONString(msg), h.JSONString(ColorSuccess), b.undoWindow.Milliseconds(), h.JSONString(msgr.Undo), h.JSONString(token)))
+}
+
+// undoButton is the action of the snackbar which calls the undo event with the token
+func undoButton() h.HTMLComponent {
+	return web.Slot(
+		VBtn("").Attr("v-if", "vars.presetsMessage.undo").Variant(VariantText).
+			Attr("@click", fmt.Sprintf(`const token = vars.presetsMessage.undo.token; vars.presetsMessage = { ...vars.presetsMessage, show: false, undo: null }; %s`,
+				web.Plaid().EventFunc(actions.Undo).Query(ParamUndoToken, web.Var("token")).Go())).
+			Children(h.Text("{{vars.presetsMessage.undo.label}}")),
+	).Name("actions")
+}
+
+func (mb *ModelBuilder) undo(ctx *web.EventContext) (r web.EventResponse, err error) {
+	msgr := MustGetMessages(ctx.R)
+	undo, ok := mb.p.undos.take(ctx.R.FormValue(ParamUndoToken), mb.p.undoUser(ctx.R))
+	if !ok {
+		ShowMessage(&r, msgr.UndoExpired, ColorWarning)
+		return
+	}
+	if err := undo(ctx, &r); err != nil {
+		ShowMessage(&r, err.Error(), ColorError)
+		return r, nil
+	}
+	ShowMessage(&r, msgr.Undone, "")
+	return
+}
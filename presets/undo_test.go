@@ -0,0 +1,111 @@
+package presets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var undoTokenReg = regexp.MustCompile(`token: "([0-9a-f]+)"`)
+
+func TestUndoDelete(t *testing.T) {
+	op := &trashTestOperator{}
+	op.records = []*foo{{Version: "v1"}}
+	op.records[0].ID = 1
+	pb := New().DataOperator(op).UndoUserFunc(func(r *http.Request) string {
+		return r.Header.Get("User")
+	})
+	mb := pb.Model(&foo{})
+	request := func(url string, user string) *web.EventContext {
+		r := httptest.NewRequest("POST", url, nil)
+		r.Header.Set("User", user)
+		return &web.EventContext{R: r, W: httptest.NewRecorder()}
+	}
+
+	// the undo is disabled by default
+	r, err := mb.editing.doDelete(request("/?id=1", "alice"))
+	require.NoError(t, err)
+	assert.Nil(t, undoTokenReg.FindStringSubmatch(r.RunScript))
+	require.NoError(t, op.Restore(&foo{}, "1", nil))
+	pb.UndoWindow(UndoWindowDefault)
+
+	// the trash of the listing isn't required to undo the soft delete
+	r, err = mb.editing.doDelete(request("/?id=1", "alice"))
+	require.NoError(t, err)
+	require.Empty(t, op.records)
+	m := undoTokenReg.FindStringSubmatch(r.RunScript)
+	require.Len(t, m, 2, r.RunScript)
+
+	// the undo can only be done by the user who deleted the record
+	r, err = mb.undo(request("/?undo_token="+m[1], "bob"))
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, Messages_en_US.UndoExpired)
+	require.Empty(t, op.records)
+
+	r, err = mb.undo(request("/?undo_token="+m[1], "alice"))
+	require.NoError(t, err)
+	require.Len(t, op.records, 1)
+	assert.Contains(t, r.RunScript, Messages_en_US.Undone)
+
+	// the undo can only be done once
+	r, err = mb.undo(request("/?undo_token="+m[1], "alice"))
+	require.NoError(t, err)
+	assert.Contains(t, r.RunScript, Messages_en_US.UndoExpired)
+
+	// the records deleted permanently can't be restored
+	op.hardDeletion = true
+	r, err = mb.editing.doDelete(request("/?id=1", "alice"))
+	require.NoError(t, err)
+	require.Empty(t, op.records)
+	assert.Nil(t, undoTokenReg.FindStringSubmatch(r.RunScript))
+}
+
+func TestUndoStoreExpires(t *testing.T) {
+	s := newUndoStore()
+	token, err := s.add(func(ctx *web.EventContext, r *web.EventResponse) error { return nil }, "", -time.Hour)
+	require.NoError(t, err)
+	_, ok := s.take(token, "")
+	assert.False(t, ok)
+}
+
+type txTrashTestOperator struct {
+	trashTestOperator
+	transactions int
+}
+
+func (op *txTrashTestOperator) Transaction(ctx *web.EventContext, f func(ctx *web.EventContext) error) error {
+	op.transactions++
+	return f(ctx)
+}
+
+func TestUndoDeleteTransaction(t *testing.T) {
+	op := &txTrashTestOperator{}
+	for i := 1; i <= 2; i++ {
+		r := &foo{}
+		r.ID = uint(i)
+		op.records = append(op.records, r)
+	}
+	mb := New().DataOperator(op).Model(&foo{})
+	require.NoError(t, op.Delete(nil, "1", nil))
+	require.NoError(t, op.Delete(nil, "2", nil))
+
+	evCtx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	var r web.EventResponse
+	require.NoError(t, mb.listing.deleteUndo([]string{"1", "2"})(evCtx, &r))
+	assert.Equal(t, 1, op.transactions, "the records are restored in a transaction")
+	assert.Len(t, op.records, 2)
+	assert.Equal(t, 2, strings.Count(r.RunScript, "PresetsNotifModelsCreated"))
+
+	// the listings aren't notified if the undo fails
+	r = web.EventResponse{}
+	require.NoError(t, op.Delete(nil, "1", nil))
+	require.Error(t, mb.listing.deleteUndo([]string{"1", "2"})(evCtx, &r))
+	assert.Empty(t, r.RunScript)
+}
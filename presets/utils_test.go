@@ -2,8 +2,14 @@ package presets
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/qor5/web/v3"
+	"github.com/sunfmin/reflectutils"
+	"github.com/theplant/relay"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +22,66 @@ func (v *foo) PrimarySlug() string {
 	return fmt.Sprintf("%d_%s", v.ID, v.Version)
 }
 
+// memoryTestOperator keeps the records of the model in memory, the models must have an ID field of uint,
+// and the keyword matches the records whose string fields contain it.
+type memoryTestOperator[T any] struct {
+	records []*T
+}
+
+func (op *memoryTestOperator[T]) Search(ctx *web.EventContext, params *SearchParams) (*SearchResult, error) {
+	var nodes []*T
+	for _, r := range op.records {
+		if params.Keyword == "" || recordContains(r, params.Keyword) {
+			nodes = append(nodes, r)
+		}
+	}
+	start := min(int((params.Page-1)*params.PerPage), len(nodes))
+	end := min(start+int(params.PerPage), len(nodes))
+	return &SearchResult{Nodes: nodes[start:end], PageInfo: relay.PageInfo{TotalCount: len(nodes)}}, nil
+}
+
+func (op *memoryTestOperator[T]) Fetch(obj interface{}, id string, ctx *web.EventContext) (interface{}, error) {
+	for _, r := range op.records {
+		if ObjectID(r) == id {
+			cp := *r
+			return &cp, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+func (op *memoryTestOperator[T]) Save(obj interface{}, id string, ctx *web.EventContext) error {
+	r := obj.(*T)
+	if id == "" {
+		if err := reflectutils.Set(r, "ID", uint(len(op.records)+1)); err != nil {
+			return err
+		}
+		op.records = append(op.records, r)
+		return nil
+	}
+	for i := range op.records {
+		if ObjectID(op.records[i]) == id {
+			op.records[i] = r
+		}
+	}
+	return nil
+}
+
+func (op *memoryTestOperator[T]) Delete(obj interface{}, id string, ctx *web.EventContext) error {
+	op.records = slices.DeleteFunc(op.records, func(r *T) bool { return ObjectID(r) == id })
+	return nil
+}
+
+func recordContains(r any, keyword string) bool {
+	rv := reflect.Indirect(reflect.ValueOf(r))
+	for i := 0; i < rv.NumField(); i++ {
+		if f := rv.Field(i); f.Kind() == reflect.String && strings.Contains(f.String(), keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 func TestObjectID(t *testing.T) {
 	tests := []struct {
 		name     string
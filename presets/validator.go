@@ -0,0 +1,382 @@
+package presets
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qor5/web/v3"
+	"github.com/sunfmin/reflectutils"
+)
+
+// ValidateTagName is the struct tag the field validators are read from, like
+//
+//	Name  string `presets_validate:"required,min=3,max=50"`
+//	Email string `presets_validate:"required,email,unique"`
+//	Code  string `presets_validate:"regex=^[A-Z]{3}-[0-9]+$"`
+//
+// the regex rule takes the rest of the tag, so it must be the last one.
+// It isn't the validate tag of the other validation libraries, whose rules are different.
+const ValidateTagName = "presets_validate"
+
+const (
+	ValidatorRuleRequired = "required"
+	ValidatorRuleMin      = "min"
+	ValidatorRuleMax      = "max"
+	ValidatorRuleRegex    = "regex"
+	ValidatorRuleEmail    = "email"
+	ValidatorRuleUnique   = "unique"
+)
+
+// FieldValidateFunc returns the error shown under the field if the value of the field in obj is invalid
+type FieldValidateFunc func(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error)
+
+// FieldValidator validates a field when the editing form is saved, the errors are keyed by the form key
+// of the field. The rule and the param of the built-in validators are also rendered as hints by the default
+// components, like the required marker and the counter of the max length.
+type FieldValidator struct {
+	rule     string
+	param    string
+	validate FieldValidateFunc
+	// the validators other than required and the ones of the number ranges skip the empty values
+	skipEmpty bool
+}
+
+func (v *FieldValidator) Rule() string {
+	return v.rule
+}
+
+func (v *FieldValidator) Param() string {
+	return v.param
+}
+
+// ValidatorFunc creates a custom field validator
+func ValidatorFunc(rule string, f FieldValidateFunc) *FieldValidator {
+	return &FieldValidator{rule: rule, validate: f}
+}
+
+func Required() *FieldValidator {
+	return &FieldValidator{
+		rule: ValidatorRuleRequired,
+		validate: func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+			if isEmptyValue(fieldReflectValue(obj, field)) {
+				return errors.New(MustGetMessages(ctx.R).ValidationRequired)
+			}
+			return nil
+		},
+	}
+}
+
+// Min is the min length of the strings and the slices, or the min value of the numbers.
+// The empty strings and slices are skipped, while the numbers are always checked, zero included.
+func Min(n float64) *FieldValidator {
+	param := strconv.FormatFloat(n, 'f', -1, 64)
+	return &FieldValidator{
+		rule:  ValidatorRuleMin,
+		param: param,
+		validate: func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+			v, isLen, ok := measureValue(fieldReflectValue(obj, field))
+			if !ok || v >= n || (isLen && v == 0) {
+				return nil
+			}
+			msgr := MustGetMessages(ctx.R)
+			if isLen {
+				return errors.New(msgr.ValidationMinLength(param))
+			}
+			return errors.New(msgr.ValidationMin(param))
+		},
+	}
+}
+
+// Max is the max length of the strings and the slices, or the max value of the numbers.
+// The empty strings and slices are skipped, while the numbers are always checked, zero included.
+func Max(n float64) *FieldValidator {
+	param := strconv.FormatFloat(n, 'f', -1, 64)
+	return &FieldValidator{
+		rule:  ValidatorRuleMax,
+		param: param,
+		validate: func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+			v, isLen, ok := measureValue(fieldReflectValue(obj, field))
+			if !ok || v <= n || (isLen && v == 0) {
+				return nil
+			}
+			msgr := MustGetMessages(ctx.R)
+			if isLen {
+				return errors.New(msgr.ValidationMaxLength(param))
+			}
+			return errors.New(msgr.ValidationMax(param))
+		},
+	}
+}
+
+func Regex(pattern string) *FieldValidator {
+	reg := regexp.MustCompile(pattern)
+	return &FieldValidator{
+		rule:      ValidatorRuleRegex,
+		param:     pattern,
+		skipEmpty: true,
+		validate: func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+			if !reg.MatchString(fmt.Sprint(fieldReflectValue(obj, field).Interface())) {
+				return errors.New(MustGetMessages(ctx.R).ValidationInvalidFormat)
+			}
+			return nil
+		},
+	}
+}
+
+func Email() *FieldValidator {
+	return &FieldValidator{
+		rule:      ValidatorRuleEmail,
+		skipEmpty: true,
+		validate: func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+			s := fmt.Sprint(fieldReflectValue(obj, field).Interface())
+			if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+				return errors.New(MustGetMessages(ctx.R).ValidationInvalidEmail)
+			}
+			return nil
+		},
+	}
+}
+
+// Unique checks no other record has the same value of the field,
+// the data operator of the presets must implement UniqueChecker, which is checked when the presets is built.
+func Unique() *FieldValidator {
+	return &FieldValidator{
+		rule:      ValidatorRuleUnique,
+		skipEmpty: true,
+		validate: func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+			if field.ModelInfo == nil {
+				return nil
+			}
+			checker, ok := field.ModelInfo.mb.p.dataOperator.(UniqueChecker)
+			if !ok {
+				return fmt.Errorf("the data operator must implement UniqueChecker for the unique validator of %s", field.Name)
+			}
+			unique, err := checker.IsUnique(obj, field.Name, fieldReflectValue(obj, field).Interface(), ctx)
+			if err != nil {
+				return err
+			}
+			if !unique {
+				return errors.New(MustGetMessages(ctx.R).ValidationNotUnique)
+			}
+			return nil
+		},
+	}
+}
+
+// Validators appends the validators of the field to the ones read from the struct tag
+func (b *FieldBuilder) Validators(vs ...*FieldValidator) (r *FieldBuilder) {
+	b.validators = append(b.validators, vs...)
+	return b
+}
+
+// ParseValidateTag parses the validators from the value of the validate struct tag
+func ParseValidateTag(tag string) (vs []*FieldValidator, err error) {
+	for tag != "" {
+		var rule string
+		rule, tag, _ = strings.Cut(tag, ",")
+		rule = strings.TrimSpace(rule)
+		name, param, _ := strings.Cut(rule, "=")
+		if name == ValidatorRuleRegex {
+			// the pattern may have commas
+			if tag != "" {
+				param += "," + tag
+				tag = ""
+			}
+			if _, err := regexp.Compile(param); err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q: %w", ValidateTagName, rule, err)
+			}
+			vs = append(vs, Regex(param))
+			continue
+		}
+		switch name {
+		case "":
+			continue
+		case ValidatorRuleRequired:
+			vs = append(vs, Required())
+		case ValidatorRuleEmail:
+			vs = append(vs, Email())
+		case ValidatorRuleUnique:
+			vs = append(vs, Unique())
+		case ValidatorRuleMin, ValidatorRuleMax:
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q: %w", ValidateTagName, rule, err)
+			}
+			if name == ValidatorRuleMin {
+				vs = append(vs, Min(n))
+			} else {
+				vs = append(vs, Max(n))
+			}
+		default:
+			return nil, fmt.Errorf("unknown %s rule %q", ValidateTagName, rule)
+		}
+	}
+	return
+}
+
+// checkUniqueValidators panics if the fields of the model have the unique validator
+// while the data operator can't check it
+func (mb *ModelBuilder) checkUniqueValidators() {
+	if _, ok := mb.p.dataOperator.(UniqueChecker); ok {
+		return
+	}
+	var check func(fb *FieldsBuilder)
+	check = func(fb *FieldsBuilder) {
+		for _, f := range fb.fields {
+			for _, v := range f.validators {
+				if v.rule == ValidatorRuleUnique {
+					panic(fmt.Sprintf("the data operator must implement UniqueChecker for the unique validator of %s.%s", mb.uriName, f.name))
+				}
+			}
+			if f.nestedFieldsBuilder != nil {
+				check(f.nestedFieldsBuilder)
+			}
+		}
+	}
+	check(&mb.editing.FieldsBuilder)
+	if mb.creating != nil {
+		check(&mb.creating.FieldsBuilder)
+	}
+}
+
+// validatorsFromTag reads the validators of the field from the struct tag of the model
+func validatorsFromTag(model interface{}, name string) []*FieldValidator {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	sf, ok := t.FieldByName(name)
+	if !ok {
+		return nil
+	}
+	tag, ok := sf.Tag.Lookup(ValidateTagName)
+	if !ok {
+		return nil
+	}
+	vs, err := ParseValidateTag(tag)
+	if err != nil {
+		panic(fmt.Sprintf("%s.%s: %v", t.Name(), name, err))
+	}
+	return vs
+}
+
+// Required returns if the field has the required validator
+func (fc *FieldContext) Required() bool {
+	_, ok := fc.ValidatorParam(ValidatorRuleRequired)
+	return ok
+}
+
+// ValidatorParam returns the param of the first validator of the field having the rule
+func (fc *FieldContext) ValidatorParam(rule string) (param string, ok bool) {
+	for _, v := range fc.Validators {
+		if v.rule == rule {
+			return v.param, true
+		}
+	}
+	return "", false
+}
+
+// validate runs the validators of the fields of obj, the nested fields and the list fields included,
+// the errors are keyed by the form keys of the fields.
+func (b *FieldsBuilder) validate(obj interface{}, info *ModelInfo, parentFormKey string, ctx *web.EventContext, vErr *web.ValidationErrors) {
+	for _, f := range b.fields {
+		// the fields which can't be changed by the request are not validated
+		if info != nil && !info.FieldWritable(ctx.R, f.name) {
+			continue
+		}
+		// the hidden fields are not set, so they are not validated either
+		if f.visibleWhen != nil && !f.visibleWhen.match(obj) {
+			continue
+		}
+
+		formKey := f.name
+		if parentFormKey != "" {
+			formKey = fmt.Sprintf("%s.%s", parentFormKey, f.name)
+		}
+		field := &FieldContext{
+			ModelInfo:  info,
+			Name:       f.name,
+			FormKey:    formKey,
+			Label:      b.getLabel(f.NameLabel),
+			Context:    f.context,
+			Validators: f.activeValidators(obj),
+		}
+		for _, v := range field.Validators {
+			if v.skipEmpty && isEmptyValue(fieldReflectValue(obj, field)) {
+				continue
+			}
+			if err := v.validate(obj, field, ctx); err != nil {
+				vErr.FieldError(formKey, err.Error())
+				break
+			}
+		}
+
+		if f.nestedFieldsBuilder == nil {
+			continue
+		}
+		val, err := reflectutils.Get(obj, f.name)
+		if err != nil || val == nil {
+			continue
+		}
+		rv := reflect.ValueOf(val)
+		switch rv.Kind() {
+		case reflect.Slice:
+			for i := 0; i < rv.Len(); i++ {
+				child := rv.Index(i)
+				if child.Kind() == reflect.Ptr && child.IsNil() {
+					continue
+				}
+				f.nestedFieldsBuilder.validate(child.Interface(), info, fmt.Sprintf("%s[%d]", formKey, i), ctx, vErr)
+			}
+		case reflect.Ptr:
+			if !rv.IsNil() {
+				f.nestedFieldsBuilder.validate(val, info, formKey, ctx, vErr)
+			}
+		default:
+			f.nestedFieldsBuilder.validate(val, info, formKey, ctx, vErr)
+		}
+	}
+}
+
+func fieldReflectValue(obj interface{}, field *FieldContext) reflect.Value {
+	return reflectValueOf(obj, field.Name)
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	if !rv.IsValid() {
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(rv.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// measureValue returns the length of the strings and the slices, or the value of the numbers
+func measureValue(rv reflect.Value) (n float64, isLen bool, ok bool) {
+	switch rv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(rv.String())), true, true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(rv.Len()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), false, true
+	}
+	return 0, false, false
+}
@@ -0,0 +1,120 @@
+package presets
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type validatedAddress struct {
+	City string `presets_validate:"required"`
+	Zip  string `presets_validate:"regex=^[0-9]{3,5}$"`
+}
+
+type validatedItem struct {
+	Name string `presets_validate:"required,max=5"`
+}
+
+type validatedFoo struct {
+	ID      uint
+	Name    string `presets_validate:"required,min=3,max=10"`
+	Email   string `presets_validate:"email"`
+	Age     int    `presets_validate:"min=18"`
+	Address *validatedAddress
+	Items   []*validatedItem
+}
+
+func TestParseValidateTag(t *testing.T) {
+	vs, err := ParseValidateTag("required,min=3,max=50,email,regex=^[a-z]{1,3}$")
+	require.NoError(t, err)
+	var rules []string
+	for _, v := range vs {
+		rules = append(rules, v.Rule()+"="+v.Param())
+	}
+	assert.Equal(t, []string{"required=", "min=3", "max=50", "email=", "regex=^[a-z]{1,3}$"}, rules, "the regex takes the rest of the tag")
+
+	_, err = ParseValidateTag("required,size=3")
+	assert.Error(t, err)
+	_, err = ParseValidateTag("max=abc")
+	assert.Error(t, err)
+}
+
+type uniqueFoo struct {
+	ID    uint
+	Email string `presets_validate:"unique"`
+	// the rules of the other validation libraries are ignored
+	Age int `validate:"gte=0"`
+}
+
+func TestUniqueValidatorNeedsUniqueChecker(t *testing.T) {
+	pb := New().URIPrefix("/admin")
+	pb.Model(&uniqueFoo{}).Editing("Email", "Age")
+	assert.PanicsWithValue(t, "the data operator must implement UniqueChecker for the unique validator of unique-foos.Email", pb.Build)
+}
+
+func TestFieldValidators(t *testing.T) {
+	pb := New()
+	mb := pb.Model(&validatedFoo{})
+	addressFB := pb.NewFieldsBuilder(WRITE).Model(&validatedAddress{}).Only("City", "Zip")
+	itemFB := pb.NewFieldsBuilder(WRITE).Model(&validatedItem{}).Only("Name")
+	eb := mb.Editing("Name", "Email", "Age", "Address", "Items")
+	eb.Field("Address").Nested(addressFB)
+	eb.Field("Items").Nested(itemFB)
+	eb.Field("Email").Validators(ValidatorFunc("example", func(obj interface{}, field *FieldContext, ctx *web.EventContext) error {
+		if obj.(*validatedFoo).Email == "root@example.com" {
+			return assert.AnError
+		}
+		return nil
+	}))
+	eb.ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+		err.GlobalError("global")
+		return
+	})
+
+	ctx := &web.EventContext{R: httptest.NewRequest("POST", "/", nil), W: httptest.NewRecorder()}
+	obj := &validatedFoo{
+		Name:    "ab",
+		Email:   "not an email",
+		Age:     10,
+		Address: &validatedAddress{Zip: "12a"},
+		Items:   []*validatedItem{{Name: "ok"}, {Name: "too long"}, nil},
+	}
+	vErr := eb.Validate(obj, ctx)
+	assert.Equal(t, "global", vErr.GetGlobalError(), "the ValidateFunc still runs")
+	assert.Equal(t, []string{Messages_en_US.ValidationMinLength("3")}, vErr.GetFieldErrors("Name"))
+	assert.Equal(t, []string{Messages_en_US.ValidationInvalidEmail}, vErr.GetFieldErrors("Email"))
+	assert.Equal(t, []string{Messages_en_US.ValidationMin("18")}, vErr.GetFieldErrors("Age"))
+	assert.Equal(t, []string{Messages_en_US.ValidationRequired}, vErr.GetFieldErrors("Address.City"))
+	assert.Equal(t, []string{Messages_en_US.ValidationInvalidFormat}, vErr.GetFieldErrors("Address.Zip"))
+	assert.Empty(t, vErr.GetFieldErrors("Items[0].Name"))
+	assert.Equal(t, []string{Messages_en_US.ValidationMaxLength("5")}, vErr.GetFieldErrors("Items[1].Name"))
+
+	obj = &validatedFoo{Name: "abc", Email: "root@example.com", Age: 18, Items: []*validatedItem{{Name: ""}}}
+	vErr = eb.Validate(obj, ctx)
+	assert.Empty(t, vErr.GetFieldErrors("Name"))
+	assert.Empty(t, vErr.GetFieldErrors("Age"))
+	assert.Equal(t, []string{assert.AnError.Error()}, vErr.GetFieldErrors("Email"), "the validators of the field API run after the tag ones")
+	assert.Equal(t, []string{Messages_en_US.ValidationRequired}, vErr.GetFieldErrors("Items[0].Name"))
+
+	obj = &validatedFoo{Name: "abc", Age: 18}
+	vErr = mb.Editing().Creating().Validate(obj, ctx)
+	assert.Equal(t, []string{"global"}, vErr.GetGlobalErrors(), "the empty strings are only checked by required")
+	assert.Empty(t, vErr.GetFieldErrors("Email"))
+
+	obj = &validatedFoo{Name: "abc"}
+	vErr = eb.Validate(obj, ctx)
+	assert.Equal(t, []string{Messages_en_US.ValidationMin("18")}, vErr.GetFieldErrors("Age"), "zero is checked by the number range")
+
+	vErr = web.ValidationErrors{}
+	vErr.FieldError("Address.City", "city error")
+	ctx.Flash = &vErr
+	body := h.MustString(eb.ToComponent(mb.Info(), &validatedFoo{ID: 1, Address: &validatedAddress{}}, ctx), web.WrapEventContext(context.Background(), ctx))
+	assert.Contains(t, body, "city error", "the errors of the nested fields are shown")
+	assert.Contains(t, body, `counter='10'`)
+	assert.Contains(t, body, "Name *")
+}
@@ -0,0 +1,221 @@
+package presets
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/qor5/web/v3"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/samber/lo"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// ParamVersion is the form key of the version token which is embedded in the edit form and section edits
+const ParamVersion = "presets_Version"
+
+// ExpectedVersion is the version of the record when the user opened it, the saver should
+// only update the record if its version is still the same, and return ErrVersionConflict otherwise.
+type ExpectedVersion struct {
+	Field string
+	Value any
+}
+
+type ctxKeyExpectedVersion struct{}
+
+// expectedVersions are the expected versions of the records checked in the request, keyed by the record pointers,
+// so that the other records saved with the same context aren't affected.
+type expectedVersions map[any]*ExpectedVersion
+
+// ExpectedVersionFromContext returns the expected version of the record obj, which is the pointer passed to the saver
+func ExpectedVersionFromContext(ctx context.Context, obj any) (*ExpectedVersion, bool) {
+	evs, _ := ctx.Value(ctxKeyExpectedVersion{}).(expectedVersions)
+	if evs == nil || !isPointer(obj) {
+		return nil, false
+	}
+	v, ok := evs[obj]
+	return v, ok
+}
+
+// WithExpectedVersion returns the context with the expected version of the record obj, for the saver to check it
+func WithExpectedVersion(ctx context.Context, obj any, ev *ExpectedVersion) context.Context {
+	evs, _ := ctx.Value(ctxKeyExpectedVersion{}).(expectedVersions)
+	if evs == nil {
+		evs = expectedVersions{}
+		ctx = context.WithValue(ctx, ctxKeyExpectedVersion{}, evs)
+	}
+	evs[obj] = ev
+	return ctx
+}
+
+func isPointer(obj any) bool {
+	return obj != nil && reflect.TypeOf(obj).Kind() == reflect.Ptr
+}
+
+type VersionConflictDiff struct {
+	Field  string
+	Latest string
+	Yours  string
+}
+
+type VersionConflictDiffFunc func(latest, yours any) ([]*VersionConflictDiff, error)
+
+// VersionField enables optimistic concurrency control with the field, which is a time.Time like UpdatedAt
+// or an integer version column, saving a record which has been changed by others since it was opened
+// is rejected and a conflict dialog is shown.
+func (mb *ModelBuilder) VersionField(name string) (r *ModelBuilder) {
+	t := reflectutils.GetType(mb.model, name)
+	if t == nil {
+		panic(fmt.Sprintf("version field %s not found in %s", name, mb.modelType))
+	}
+	if t != reflect.TypeOf(time.Time{}) && !t.ConvertibleTo(reflect.TypeOf(int64(0))) {
+		panic(fmt.Sprintf("version field %s must be time.Time or an integer", name))
+	}
+	mb.versionField = name
+	return mb
+}
+
+// VersionConflictDiffFunc is used to list the fields which differ from the latest version in the conflict dialog
+func (mb *ModelBuilder) VersionConflictDiffFunc(v VersionConflictDiffFunc) (r *ModelBuilder) {
+	mb.versionConflictDiffFunc = v
+	return mb
+}
+
+func (mb *ModelBuilder) versionToken(obj any) string {
+	v, err := reflectutils.Get(obj, mb.versionField)
+	if err != nil {
+		panic(err)
+	}
+	switch vv := v.(type) {
+	case time.Time:
+		if vv.IsZero() {
+			return ""
+		}
+		return vv.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(vv)
+	}
+}
+
+func (mb *ModelBuilder) versionHidden(obj any) h.HTMLComponent {
+	if mb.versionField == "" || obj == nil {
+		return nil
+	}
+	return h.Input("").Type("hidden").Attr(web.VField(ParamVersion, mb.versionToken(obj))...)
+}
+
+// checkVersion compares the submitted version token with the fetched record, if they are the same,
+// it puts the expected version of the record into the context for the saver and bumps the version of the record.
+// Without a token, like the saves of the REST API, the inline editing and the moves on the listing,
+// the version of the fetched record is expected, so that the changes saved after it's fetched aren't overwritten.
+func (mb *ModelBuilder) checkVersion(ctx *web.EventContext, obj any) error {
+	if mb.versionField == "" || !isPointer(obj) {
+		return nil
+	}
+	token := ctx.R.FormValue(ParamVersion)
+	if token != "" && token != mb.versionToken(obj) {
+		return ErrVersionConflict
+	}
+
+	current, err := reflectutils.Get(obj, mb.versionField)
+	if err != nil {
+		return err
+	}
+	ctx.R = ctx.R.WithContext(WithExpectedVersion(ctx.R.Context(), obj, &ExpectedVersion{Field: mb.versionField, Value: current}))
+
+	var next any
+	switch vv := current.(type) {
+	case time.Time:
+		next = time.Now()
+	default:
+		n, _ := strconv.ParseInt(fmt.Sprint(vv), 10, 64)
+		next = reflect.ValueOf(n + 1).Convert(reflect.TypeOf(vv)).Interface()
+	}
+	return reflectutils.Set(obj, mb.versionField, next)
+}
+
+func (mb *ModelBuilder) versionConflictDiffs(latest, yours any) ([]*VersionConflictDiff, error) {
+	if mb.versionConflictDiffFunc != nil {
+		return mb.versionConflictDiffFunc(latest, yours)
+	}
+	var diffs []*VersionConflictDiff
+	for _, f := range mb.editing.fields {
+		lv, err := reflectutils.Get(latest, f.name)
+		if err != nil {
+			continue
+		}
+		yv, err := reflectutils.Get(yours, f.name)
+		if err != nil {
+			continue
+		}
+		if reflect.DeepEqual(lv, yv) {
+			continue
+		}
+		diffs = append(diffs, &VersionConflictDiff{
+			Field:  f.name,
+			Latest: fmt.Sprint(lv),
+			Yours:  fmt.Sprint(yv),
+		})
+	}
+	return diffs, nil
+}
+
+// versionConflictDialog tells the user that the record has been changed by others,
+// the form is re-rendered with the latest version token, so saving again overwrites the latest version.
+func (mb *ModelBuilder) versionConflictDialog(ctx *web.EventContext, r *web.EventResponse, latest, yours any, discardEvent string) {
+	msgr := MustGetMessages(ctx.R)
+
+	diffs, err := mb.versionConflictDiffs(latest, yours)
+	if err != nil {
+		ShowMessage(r, err.Error(), ColorError)
+		return
+	}
+
+	var diffTable h.HTMLComponent
+	if len(diffs) > 0 {
+		diffTable = VTable(
+			h.Thead(h.Tr(
+				h.Th(msgr.VersionConflictField),
+				h.Th(msgr.VersionConflictLatest),
+				h.Th(msgr.VersionConflictYours),
+			)),
+			h.Tbody(lo.Map(diffs, func(d *VersionConflictDiff, _ int) h.HTMLComponent {
+				label := d.Field
+				if f := mb.editing.GetField(d.Field); f != nil {
+					label = mb.getLabel(f.NameLabel)
+				}
+				return h.Tr(
+					h.Td(h.Text(label)),
+					h.Td(h.Text(d.Latest)),
+					h.Td(h.Text(d.Yours)),
+				)
+			})...),
+		).Density(DensityCompact)
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: DefaultConfirmDialogPortalName,
+		Body: web.Scope(
+			VDialog(
+				VCard(
+					VCardTitle(h.Text(msgr.VersionConflictTitle)),
+					VCardText(
+						VAlert(h.Text(msgr.VersionConflictNotice)).Type(ColorWarning).Density(DensityCompact).Class("mb-2"),
+						diffTable,
+					),
+					VCardActions(
+						VSpacer(),
+						VBtn(msgr.VersionConflictDiscardYours).Variant(VariantText).
+							Attr("@click", fmt.Sprintf("locals.show = false; %s", discardEvent)),
+						VBtn(msgr.VersionConflictKeepYours).Variant(VariantFlat).Color(ColorPrimary).
+							Attr("@click", "locals.show = false"),
+					),
+				),
+			).Width(cmp.Or(mb.rightDrawerWidth, "600")).Attr("v-model", "locals.show"),
+		).VSlot("{ locals }").Init("{show: true}"),
+	})
+}
@@ -0,0 +1,64 @@
+package presets
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCheckVersion(t *testing.T) {
+	mb := New().Model(&foo{}).VersionField("UpdatedAt")
+	assert.Panics(t, func() { mb.VersionField("Version") })
+
+	updatedAt := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	newCtx := func(token string) *web.EventContext {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{ParamVersion: {token}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return &web.EventContext{R: r, W: httptest.NewRecorder()}
+	}
+
+	obj := &foo{Model: gorm.Model{ID: 1, UpdatedAt: updatedAt}}
+	token := mb.versionToken(obj)
+	assert.Equal(t, "2024-05-01T10:00:00.123456Z", token)
+
+	ctx := newCtx(token)
+	require.NoError(t, mb.checkVersion(ctx, obj))
+	ev, ok := ExpectedVersionFromContext(ctx.R.Context(), obj)
+	require.True(t, ok)
+	assert.Equal(t, "UpdatedAt", ev.Field)
+	assert.Equal(t, updatedAt, ev.Value)
+	assert.True(t, obj.UpdatedAt.After(updatedAt))
+
+	// the record has been saved by someone else since the token was rendered
+	ctx = newCtx(token)
+	assert.ErrorIs(t, mb.checkVersion(ctx, obj), ErrVersionConflict)
+	_, ok = ExpectedVersionFromContext(ctx.R.Context(), obj)
+	assert.False(t, ok)
+
+	// without a token the version of the fetched record is expected
+	bumped := obj.UpdatedAt
+	ctx = newCtx("")
+	require.NoError(t, mb.checkVersion(ctx, obj))
+	ev, ok = ExpectedVersionFromContext(ctx.R.Context(), obj)
+	require.True(t, ok)
+	assert.Equal(t, bumped, ev.Value)
+
+	// the expected version is only for the record checked, not for the other records saved with the context
+	_, ok = ExpectedVersionFromContext(ctx.R.Context(), &foo{Model: gorm.Model{ID: 1}})
+	assert.False(t, ok)
+	other := &foo{Model: gorm.Model{ID: 2, UpdatedAt: updatedAt}}
+	require.NoError(t, mb.checkVersion(ctx, other))
+	ev, ok = ExpectedVersionFromContext(ctx.R.Context(), obj)
+	require.True(t, ok)
+	assert.Equal(t, bumped, ev.Value)
+	ev, ok = ExpectedVersionFromContext(ctx.R.Context(), other)
+	require.True(t, ok)
+	assert.Equal(t, updatedAt, ev.Value)
+}
@@ -0,0 +1,213 @@
+package presets
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/i18n"
+	"github.com/qor5/x/v3/perm"
+	. "github.com/qor5/x/v3/ui/vuetify"
+	"github.com/samber/lo"
+	h "github.com/theplant/htmlgo"
+)
+
+// WizardStepBuilder is a step of the creating wizard, a group of the fields of the form
+// which are validated before going to the next step.
+type WizardStepBuilder struct {
+	title     string
+	fields    []interface{}
+	validator ValidateFunc
+	saveDraft bool
+}
+
+// WizardStep creates a step with the title and the fields, the fields are string / []string / *FieldsSection like Only
+func WizardStep(title string, fields ...interface{}) *WizardStepBuilder {
+	return &WizardStepBuilder{title: title, fields: fields}
+}
+
+// ValidateFunc validates the step besides the validators of its fields
+func (b *WizardStepBuilder) ValidateFunc(v ValidateFunc) (r *WizardStepBuilder) {
+	b.validator = v
+	return b
+}
+
+// SaveDraft saves the values of the form as the draft of the creating form when going to the next step,
+// reopening the form offers to restore it at the step after. The drafts of the model must be enabled by Drafts.
+func (b *WizardStepBuilder) SaveDraft(v bool) (r *WizardStepBuilder) {
+	b.saveDraft = v
+	return b
+}
+
+// Wizard splits the creating form into the ordered steps, the record is only saved by the last step.
+// The fields of all the steps are kept in the form, only the ones of the current step are shown,
+// so the steps should cover the fields of the form.
+func (b *EditingBuilder) Wizard(steps ...*WizardStepBuilder) (r *EditingBuilder) {
+	b.wizardSteps = steps
+	return b
+}
+
+func (b *EditingBuilder) isWizard(id string) bool {
+	return len(b.wizardSteps) > 0 && id == ""
+}
+
+type ctxKeyWizardStep struct{}
+
+// withWizardStep sets the step the wizard rendered for the request is at, instead of the one posted by the form
+func withWizardStep(ctx *web.EventContext, step int) {
+	ctx.WithContextValue(ctxKeyWizardStep{}, step)
+}
+
+// currentWizardStep is the step the form is at, or the first step having the errors of the fields before it
+func (b *EditingBuilder) currentWizardStep(ctx *web.EventContext) int {
+	step, ok := ctx.ContextValue(ctxKeyWizardStep{}).(int)
+	if !ok {
+		step, _ = strconv.Atoi(ctx.R.FormValue(ParamWizardStep))
+	}
+	step = max(0, min(step, len(b.wizardSteps)-1))
+
+	if vErr, ok := ctx.Flash.(*web.ValidationErrors); ok {
+		for i := 0; i < step; i++ {
+			names := b.FieldsBuilder.Only(b.wizardSteps[i].fields...).getFieldNamesFromLayout()
+			if lo.ContainsBy(names, func(name string) bool { return len(vErr.GetFieldErrors(name)) > 0 }) {
+				return i
+			}
+		}
+	}
+	return step
+}
+
+// wizardComponent renders the stepper and the fields of the steps, the steps other than the current one are hidden,
+// they are still in the form so that their values are posted with the events of any step.
+func (b *EditingBuilder) wizardComponent(obj interface{}, ctx *web.EventContext) h.HTMLComponent {
+	step := b.currentWizardStep(ctx)
+	titles := lo.Map(b.wizardSteps, func(s *WizardStepBuilder, _ int) string {
+		return i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, s.title)
+	})
+
+	comps := []h.HTMLComponent{
+		VStepper().Items(titles).ModelValue(step + 1).
+			HideActions(true).Flat(true).AltLabels(true).Class("mb-4"),
+		h.Input("").Type("hidden").Attr(web.VField(ParamWizardStep, step)...),
+	}
+	for i, s := range b.wizardSteps {
+		stepComp := h.Div(b.FieldsBuilder.Only(s.fields...).ToComponent(b.mb.Info(), obj, ctx))
+		if i != step {
+			stepComp.Style("display: none;")
+		}
+		comps = append(comps, stepComp)
+	}
+	return h.Components(comps...)
+}
+
+// wizardButtons goes back to the previous step and to the next step, the last step has updateBtn to save the record
+func (b *EditingBuilder) wizardButtons(queries url.Values, updateBtn h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+	step := b.currentWizardStep(ctx)
+	goStep := func(to int) string {
+		return web.Plaid().
+			EventFunc(actions.WizardStep).
+			Queries(queries).
+			Query(ParamWizardTo, to).
+			URL(b.mb.Info().ListingHref()).
+			Go()
+	}
+
+	var nextBtn h.HTMLComponent = updateBtn
+	if step < len(b.wizardSteps)-1 {
+		nextBtn = VBtn(msgr.WizardNext).
+			Color("primary").
+			Variant(VariantFlat).
+			Attr("@click", goStep(step+1)).
+			Attr(":disabled", "isFetching").
+			Attr(":loading", "isFetching")
+	}
+	return h.Components(
+		h.If(step > 0,
+			VBtn(msgr.WizardBack).
+				Variant(VariantText).
+				Attr("@click", goStep(step-1)).
+				Attr(":disabled", "isFetching"),
+		),
+		VSpacer(),
+		nextBtn,
+	)
+}
+
+// validateWizardStep runs the validators of the fields of the step and the ValidateFunc of the step
+func (b *EditingBuilder) validateWizardStep(s *WizardStepBuilder, obj interface{}, ctx *web.EventContext) (vErr web.ValidationErrors) {
+	if s.validator != nil {
+		vErr = s.validator(obj, ctx)
+	}
+	b.FieldsBuilder.Only(s.fields...).validate(obj, b.mb.Info(), "", ctx, &vErr)
+	return
+}
+
+// validateWizardSteps runs the ValidateFuncs of all the steps before the record is saved,
+// the wizard goes back to the first step failing.
+func (b *EditingBuilder) validateWizardSteps(obj interface{}, ctx *web.EventContext) (vErr web.ValidationErrors) {
+	for i, s := range b.wizardSteps {
+		if s.validator == nil {
+			continue
+		}
+		if vErr = s.validator(obj, ctx); vErr.HaveErrors() {
+			withWizardStep(ctx, i)
+			return
+		}
+	}
+	return
+}
+
+// wizardStep goes to the step of the creating wizard, the current step must be valid to go forward
+func (b *EditingBuilder) wizardStep(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if b.mb.Info().Verifier().Do(PermCreate).WithReq(ctx.R).IsAllowed() != nil {
+		ShowMessage(&r, perm.PermissionDenied.Error(), "warning")
+		return
+	}
+
+	usingB := b
+	if b.mb.creating != nil {
+		usingB = b.mb.creating
+	}
+	if len(usingB.wizardSteps) == 0 {
+		return
+	}
+
+	from := usingB.currentWizardStep(ctx)
+	to, err := strconv.Atoi(ctx.R.FormValue(ParamWizardTo))
+	if err != nil {
+		return
+	}
+	to = max(0, min(to, len(usingB.wizardSteps)-1))
+
+	obj, vErr := usingB.FetchAndUnmarshal("", true, ctx)
+	if !vErr.HaveErrors() && to > from {
+		for i := from; i < to && !vErr.HaveErrors(); i++ {
+			vErr = usingB.validateWizardStep(usingB.wizardSteps[i], obj, ctx)
+		}
+	}
+	if vErr.HaveErrors() {
+		withWizardStep(ctx, from)
+		usingB.UpdateOverlayContent(ctx, &r, obj, "", &vErr)
+		return r, nil
+	}
+
+	if to > from && usingB.wizardSteps[to-1].saveDraft {
+		if key, ok := b.mb.draftKey(ctx, "", ""); ok {
+			values := url.Values{}
+			for k, vs := range b.mb.draftValues(ctx, "") {
+				values[k] = vs
+			}
+			values.Set(ParamWizardStep, strconv.Itoa(to))
+			if err = b.mb.editing.draftStore.Save(ctx, key, values); err != nil {
+				return
+			}
+			ShowMessage(&r, MustGetMessages(ctx.R).WizardDraftSaved, "")
+		}
+	}
+
+	withWizardStep(ctx, to)
+	usingB.UpdateOverlayContent(ctx, &r, obj, "", nil)
+	return
+}
@@ -0,0 +1,119 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets/actions"
+	"github.com/qor5/web/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	h "github.com/theplant/htmlgo"
+)
+
+type wizardProduct struct {
+	ID       uint
+	Name     string `presets_validate:"required"`
+	Price    int    `presets_validate:"min=1"`
+	SEOTitle string
+}
+
+func TestWizard(t *testing.T) {
+	pb := New()
+	mb := pb.Model(&wizardProduct{})
+	store := memoryDraftStore{}
+	var saved []*wizardProduct
+	mb.Editing("Name", "Price", "SEOTitle").
+		SaveFunc(func(obj interface{}, id string, ctx *web.EventContext) error {
+			saved = append(saved, obj.(*wizardProduct))
+			return nil
+		}).
+		Drafts(store, func(r *http.Request) string { return "alice" }).
+		Creating().
+		Wizard(
+			WizardStep("Basic Info", "Name"),
+			WizardStep("Pricing", "Price").SaveDraft(true).
+				ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+					if obj.(*wizardProduct).Price > 1000 {
+						err.FieldError("Price", "too expensive")
+					}
+					return
+				}),
+			WizardStep("SEO", "SEOTitle"),
+		)
+
+	wizardContext := func(overlay string, values url.Values) *web.EventContext {
+		values.Set(ParamOverlay, overlay)
+		return rowContext(draftTestContext("/?overlay="+overlay, nil), values)
+	}
+	render := func(r web.EventResponse, ctx *web.EventContext) string {
+		require.Len(t, r.UpdatePortals, 1)
+		return h.MustString(r.UpdatePortals[0].Body, web.WrapEventContext(context.Background(), ctx))
+	}
+
+	// the current step must be valid to go to the next one
+	ctx := wizardContext("dialog", url.Values{ParamWizardStep: {"0"}, ParamWizardTo: {"1"}})
+	r, err := mb.editing.wizardStep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dialogContentPortalName, r.UpdatePortals[0].Name)
+	body := render(r, ctx)
+	assert.Contains(t, body, Messages_en_US.ValidationRequired)
+	assert.Contains(t, body, `v-assign='[form, {"presets_wizard_step":0}]'`)
+	assert.NotContains(t, body, Messages_en_US.WizardBack)
+
+	// the fields of the steps after aren't validated yet
+	ctx = wizardContext("dialog", url.Values{ParamWizardStep: {"0"}, ParamWizardTo: {"1"}, "Name": {"Chair"}})
+	r, err = mb.editing.wizardStep(ctx)
+	require.NoError(t, err)
+	body = render(r, ctx)
+	assert.NotContains(t, body, Messages_en_US.ValidationRequired)
+	assert.Contains(t, body, `v-assign='[form, {"presets_wizard_step":1}]'`)
+	assert.Contains(t, body, `v-assign='[form, {"Name":"Chair"}]'`, "the values of the other steps are kept in the form")
+	assert.Contains(t, body, Messages_en_US.WizardBack)
+	assert.Contains(t, body, Messages_en_US.WizardNext)
+	assert.Empty(t, store)
+
+	// going back doesn't validate, the step saving the draft saves it when going forward
+	ctx = wizardContext("drawer", url.Values{ParamWizardStep: {"1"}, ParamWizardTo: {"0"}, "Name": {"Chair"}})
+	r, err = mb.editing.wizardStep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, RightDrawerContentPortalName, r.UpdatePortals[0].Name)
+	assert.Contains(t, render(r, ctx), `v-assign='[form, {"presets_wizard_step":0}]'`)
+
+	ctx = wizardContext("drawer", url.Values{ParamWizardStep: {"1"}, ParamWizardTo: {"2"}, "Name": {"Chair"}, "Price": {"10"}})
+	r, err = mb.editing.wizardStep(ctx)
+	require.NoError(t, err)
+	body = render(r, ctx)
+	assert.Contains(t, body, `v-assign='[form, {"presets_wizard_step":2}]'`)
+	assert.Contains(t, body, actions.Update, "the last step saves the record")
+	draft := store[DraftKey{User: "alice", Model: "wizard-products"}]
+	require.NotNil(t, draft)
+	assert.Equal(t, "2", draft.Values.Get(ParamWizardStep))
+	assert.Equal(t, "Chair", draft.Values.Get("Name"))
+
+	// the errors of the fields of the steps before go back to the step
+	ctx = wizardContext("drawer", url.Values{ParamWizardStep: {"2"}, "Price": {"10"}})
+	var r2 web.EventResponse
+	_, err = mb.editing.doUpdate(ctx, &r2, false)
+	require.Error(t, err)
+	assert.Contains(t, render(r2, ctx), `v-assign='[form, {"presets_wizard_step":0}]'`)
+	assert.Empty(t, saved)
+
+	// the ValidateFuncs of the steps run again when saving
+	ctx = wizardContext("drawer", url.Values{ParamWizardStep: {"2"}, "Name": {"Chair"}, "Price": {"5000"}})
+	r2 = web.EventResponse{}
+	_, err = mb.editing.doUpdate(ctx, &r2, false)
+	require.Error(t, err)
+	body = render(r2, ctx)
+	assert.Contains(t, body, "too expensive")
+	assert.Contains(t, body, `v-assign='[form, {"presets_wizard_step":1}]'`)
+	assert.Empty(t, saved)
+
+	ctx = wizardContext("drawer", url.Values{ParamWizardStep: {"2"}, "Name": {"Chair"}, "Price": {"10"}})
+	_, err = mb.editing.doUpdate(ctx, &r2, false)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "Chair", saved[0].Name)
+}
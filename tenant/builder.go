@@ -0,0 +1,141 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/ory/ladon"
+	"github.com/pkg/errors"
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/x/v3/perm"
+	"gorm.io/gorm"
+)
+
+// FieldDefault is the tenant field of the models, the activity logs, media and worker jobs
+// have it to carry the tenant of the request which creates them.
+const FieldDefault = "TenantID"
+
+const (
+	permContextKeyMismatched = "tenant_mismatched"
+	permPolicyID             = "tenant_isolation"
+)
+
+// Resolver returns the tenant of the request, the request is not scoped if the tenant is empty,
+// e.g. for the administrators of the platform.
+type Resolver func(r *http.Request) (tenantID string, err error)
+
+type ctxKeyTenant struct{}
+
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, ctxKeyTenant{}, tenantID)
+}
+
+func FromContext(ctx context.Context) (tenantID string, ok bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenantID, ok = ctx.Value(ctxKeyTenant{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// Builder scopes the records of the models which have the tenant field to the tenant of the request,
+// the queries made with the request context only see the records of the tenant and the records
+// created with it are assigned to the tenant.
+type Builder struct {
+	db       *gorm.DB
+	resolver Resolver
+	field    string
+}
+
+var _ presets.Plugin = (*Builder)(nil)
+
+// New registers the tenant callbacks to db, which should be the db of the presets data operator.
+func New(db *gorm.DB, resolver Resolver) *Builder {
+	b := &Builder{
+		db:       db,
+		resolver: resolver,
+		field:    FieldDefault,
+	}
+	if err := b.registerCallbacks(); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Field sets the tenant field of the models, the models without it fall back to FieldDefault.
+func (b *Builder) Field(v string) (r *Builder) {
+	b.field = v
+	return b
+}
+
+// Install resolves the tenant of the requests to presets, and denies the access to the records of the other tenants
+// by the permission, it should be installed after the permission of presets is set.
+func (b *Builder) Install(pb *presets.Builder) error {
+	pb.AddWrapHandler("tenant", b.Middleware)
+
+	pm := pb.GetPermission()
+	if pm == nil {
+		return nil
+	}
+	contextFunc := pm.GetContextFunc()
+	pm.ContextFunc(func(r *http.Request, objs []interface{}) perm.Context {
+		var c perm.Context
+		if contextFunc != nil {
+			c = contextFunc(r, objs)
+		}
+		if c == nil {
+			c = make(perm.Context)
+		}
+		c[permContextKeyMismatched] = b.mismatched(r, objs)
+		return c
+	})
+	pm.CreatePolicies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Denied).ToDo(perm.Anything).On(perm.Anything).
+			Given(perm.Conditions{
+				permContextKeyMismatched: &ladon.BooleanCondition{BooleanValue: true},
+			}).ID(permPolicyID),
+	)
+	return nil
+}
+
+// Middleware puts the tenant resolved from the request into its context
+func (b *Builder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, err := b.resolver(r)
+		if err != nil {
+			http.Error(w, errors.Wrap(err, "resolve tenant").Error(), http.StatusForbidden)
+			return
+		}
+		if tenantID != "" {
+			r = r.WithContext(NewContext(r.Context(), tenantID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mismatched reports whether any of the objs belongs to another tenant
+func (b *Builder) mismatched(r *http.Request, objs []interface{}) bool {
+	tenantID, ok := FromContext(r.Context())
+	if !ok {
+		return false
+	}
+	for _, obj := range objs {
+		rv := reflect.Indirect(reflect.ValueOf(obj))
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		for _, name := range []string{b.field, FieldDefault} {
+			fv := rv.FieldByName(name)
+			if !fv.IsValid() {
+				continue
+			}
+			if !fv.IsZero() && fmt.Sprint(reflect.Indirect(fv).Interface()) != tenantID {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package tenant_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor5/admin/v3/presets"
+	"github.com/qor5/admin/v3/presets/gorm2op"
+	"github.com/qor5/admin/v3/tenant"
+	"github.com/qor5/web/v3"
+	"github.com/qor5/x/v3/perm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type Product struct {
+	ID       uint
+	Name     string
+	TenantID string
+}
+
+func setup(t *testing.T) (*gorm.DB, *presets.Builder) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Product{}))
+	require.NoError(t, db.Create([]*Product{
+		{ID: 1, Name: "a1", TenantID: "a"},
+		{ID: 2, Name: "b1", TenantID: "b"},
+	}).Error)
+
+	pb := presets.New().DataOperator(gorm2op.DataOperator(db)).Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+	))
+	pb.Use(tenant.New(db, func(r *http.Request) (string, error) {
+		return r.Header.Get("tenant"), nil
+	}))
+	return db, pb
+}
+
+func eventContext(tenantID string) *web.EventContext {
+	r := httptest.NewRequest("GET", "/", nil)
+	if tenantID != "" {
+		r = r.WithContext(tenant.NewContext(r.Context(), tenantID))
+	}
+	return &web.EventContext{R: r, W: httptest.NewRecorder()}
+}
+
+func TestScoping(t *testing.T) {
+	db, _ := setup(t)
+	op := gorm2op.DataOperator(db)
+	ctx := eventContext("a")
+
+	result, err := op.Search(ctx, &presets.SearchParams{Model: &Product{}})
+	require.NoError(t, err)
+	assert.Len(t, result.Nodes, 1)
+
+	_, err = op.Fetch(&Product{}, "2", ctx)
+	assert.ErrorIs(t, err, presets.ErrRecordNotFound)
+
+	p := &Product{Name: "a2", TenantID: "b"}
+	require.NoError(t, op.Save(p, "", ctx))
+	assert.Equal(t, "a", p.TenantID)
+
+	// the record of another tenant can't be overwritten
+	err = op.Save(&Product{ID: 2, Name: "hacked"}, "2", ctx)
+	assert.ErrorIs(t, err, presets.ErrRecordNotFound)
+	other := &Product{}
+	require.NoError(t, db.First(other, 2).Error)
+	assert.Equal(t, "b1", other.Name)
+	assert.Equal(t, "b", other.TenantID)
+
+	// the tenant of the record can't be changed
+	require.NoError(t, op.Save(&Product{ID: 1, Name: "a1", TenantID: "b"}, "1", ctx))
+	other = &Product{}
+	require.NoError(t, db.First(other, 1).Error)
+	assert.Equal(t, "a", other.TenantID)
+
+	require.NoError(t, op.Delete(&Product{}, "2", ctx))
+	var count int64
+	require.NoError(t, db.Model(&Product{}).Count(&count).Error)
+	assert.EqualValues(t, 3, count)
+
+	// the requests without tenant are not scoped
+	result, err = op.Search(eventContext(""), &presets.SearchParams{Model: &Product{}})
+	require.NoError(t, err)
+	assert.Len(t, result.Nodes, 3)
+}
+
+func TestPermission(t *testing.T) {
+	_, pb := setup(t)
+	mb := pb.Model(&Product{})
+	ctx := eventContext("a")
+
+	verifier := mb.Info().Verifier().Do(presets.PermGet).WithReq(ctx.R)
+	assert.NoError(t, verifier.ObjectOn(&Product{ID: 1, TenantID: "a"}).IsAllowed())
+	verifier = mb.Info().Verifier().Do(presets.PermGet).WithReq(ctx.R)
+	assert.Error(t, verifier.ObjectOn(&Product{ID: 2, TenantID: "b"}).IsAllowed())
+}
+
+func TestMiddleware(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	b := tenant.New(db, func(r *http.Request) (string, error) {
+		return r.Header.Get("tenant"), nil
+	})
+	var got string
+	h := b.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = tenant.FromContext(r.Context())
+	}))
+	r := httptest.NewRequest("GET", "/", nil).WithContext(context.Background())
+	r.Header.Set("tenant", "a")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "a", got)
+}
@@ -0,0 +1,109 @@
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+func (b *Builder) registerCallbacks() error {
+	cb := b.db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", b.scope); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:row", b.scope); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", b.scopeAndAssign); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", b.scope); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("tenant:create", b.assign)
+}
+
+// tenantField returns the tenant field of the statement's model and the tenant of the statement's context
+func (b *Builder) tenantField(db *gorm.DB) (f *schema.Field, value any, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, nil, false
+	}
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		return nil, nil, false
+	}
+	for _, name := range []string{b.field, FieldDefault} {
+		if f = db.Statement.Schema.LookUpField(name); f != nil {
+			break
+		}
+	}
+	if f == nil || f.DBName == "" {
+		return nil, nil, false
+	}
+
+	// converts the tenant to the type of the field
+	rv := reflect.New(db.Statement.Schema.ModelType).Elem()
+	if err := f.Set(db.Statement.Context, rv, tenantID); err != nil {
+		_ = db.AddError(err)
+		return nil, nil, false
+	}
+	value, _ = f.ValueOf(db.Statement.Context, rv)
+	return f, value, true
+}
+
+func (b *Builder) scope(db *gorm.DB) {
+	f, value, ok := b.tenantField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Value: value},
+	}})
+}
+
+func (b *Builder) assign(db *gorm.DB) {
+	f, value, ok := b.tenantField(db)
+	if !ok {
+		return
+	}
+	ctx := db.Statement.Context
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := f.Set(ctx, reflect.Indirect(rv.Index(i)), value); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := f.Set(ctx, rv, value); err != nil {
+			_ = db.AddError(err)
+			return
+		}
+	}
+
+	// the upsert of Save must not overwrite the records of the other tenants
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && (onConflict.UpdateAll || len(onConflict.DoUpdates) > 0) {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs,
+				clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Value: value})
+			db.Statement.AddClause(onConflict)
+		}
+	}
+}
+
+// scopeAndAssign scopes the updates to the tenant and keeps the records in it
+func (b *Builder) scopeAndAssign(db *gorm.DB) {
+	b.scope(db)
+	if rv := db.Statement.ReflectValue; rv.Kind() == reflect.Struct && rv.CanAddr() {
+		f, value, ok := b.tenantField(db)
+		if !ok {
+			return
+		}
+		if err := f.Set(db.Statement.Context, rv, value); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
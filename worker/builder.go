@@ -132,6 +132,11 @@ func (b *Builder) setStatus(id uint, status string) error {
 
 var permVerifier *perm.Verifier
 
+// GetModelBuilder returns the model builder of the jobs, it's nil before the worker is installed.
+func (b *Builder) GetModelBuilder() *presets.ModelBuilder {
+	return b.mb
+}
+
 func (b *Builder) Install(pb *presets.Builder) error {
 	b.pb = pb
 	permVerifier = perm.NewVerifier("workers", pb.GetPermission())
@@ -435,7 +440,7 @@ func (b *Builder) createJob(ctx *web.EventContext, qorJob *QorJob) (j *QorJob, e
 			Job:    qorJob.Job,
 			Status: JobStatusNew,
 		}
-		err = b.db.Create(j).Error
+		err = b.db.WithContext(ctx.R.Context()).Create(j).Error
 		if err != nil {
 			return err
 		}
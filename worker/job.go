@@ -203,7 +203,7 @@ func (jb *JobBuilder) newJobInstance(
 	if jb.b.getCurrentUserIDFunc != nil {
 		inst.Operator = jb.b.getCurrentUserIDFunc(r)
 	}
-	err := jb.b.db.Create(&inst).Error
+	err := jb.b.db.WithContext(r.Context()).Create(&inst).Error
 	if err != nil {
 		return nil, err
 	}
@@ -13,6 +13,8 @@ type QorJob struct {
 	Job    string
 	Status string      `sql:"default:'new'"`
 	Args   interface{} `sql:"-" gorm:"-"`
+	// TenantID is the tenant of the request which creates the job
+	TenantID string `gorm:"index"`
 }
 
 type QorJobInstance struct {
@@ -29,6 +31,7 @@ type QorJobInstance struct {
 
 	Progress     uint
 	ProgressText string
+	TenantID     string `gorm:"index"`
 
 	jb          *JobBuilder `sql:"-"`
 	mutex       sync.Mutex  `sql:"-"`